@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	errAliasNotFound = errors.New("field from alias_of option not found")
+	errAliasValue    = errors.New("presence of non-nullable message can not be checked")
+)
+
+// aliasOf returns value of alias_of option of field fdp or an empty string.
+func aliasOf(fdp *descriptor.FieldDescriptorProto) string {
+	if fdp.Options == nil {
+		return ""
+	}
+
+	name, _ := getStringOption(fdp.Options, options.E_AliasOf)
+
+	return name
+}
+
+// presenceCond returns format of condition which is true if proto field fdp
+// has a value, e.g. `%s != ""` for strings. Verb is replaced by expression
+// which reads the field.
+func presenceCond(fdp *descriptor.FieldDescriptorProto, fi fileInfo) (string, error) {
+	switch {
+	case fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED:
+		return "len(%s) > 0", nil
+	case fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		if !fi.nullable(fdp) {
+			return "", errAliasValue
+		}
+		return "%s != nil", nil
+	case fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_BYTES || isPointerScalar(fdp, fi):
+		return "%s != nil", nil
+	case fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return "%s", nil
+	case fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_STRING:
+		return `%s != ""`, nil
+	default:
+		return "%s != 0", nil
+	}
+}
+
+// processAliasField adds alias field fdp to aliases of field from its
+// alias_of option. Alias is processed as a field which is mapped into the
+// same model field.
+func processAliasField(w io.Writer, fdp *descriptor.FieldDescriptorProto, msg *descriptor.DescriptorProto, fields []Field, subMessages MessageOptionList, goStructFields source.Structure, fi fileInfo) error {
+	name := aliasOf(fdp)
+
+	var target *Field
+	for i := range fields {
+		if fields[i].ProtoPath == name {
+			target = &fields[i]
+		}
+	}
+
+	var targetFdp *descriptor.FieldDescriptorProto
+	for _, f := range msg.Field {
+		if f.GetName() == name {
+			targetFdp = f
+		}
+	}
+
+	if target == nil || targetFdp == nil {
+		return pkgerrors.Wrap(errAliasNotFound, fdp.GetName())
+	}
+
+	cond, err := presenceCond(targetFdp, fi)
+	if err != nil {
+		return pkgerrors.Wrap(err, name)
+	}
+	target.Presence = cond
+
+	alias := proto.Clone(fdp).(*descriptor.FieldDescriptorProto)
+	if alias.Options == nil {
+		alias.Options = &descriptor.FieldOptions{}
+	}
+	if err := proto.SetExtension(alias.Options, options.E_MapTo, &target.Name); err != nil {
+		return pkgerrors.Wrap(err, fdp.GetName())
+	}
+
+	f, err := processField(w, alias, subMessages, goStructFields, fi)
+	if err != nil {
+		return err
+	}
+
+	if fi.golang {
+		golangField(f, alias, fi)
+	}
+
+	if f.Presence, err = presenceCond(fdp, fi); err != nil {
+		return pkgerrors.Wrap(err, fdp.GetName())
+	}
+	f.WriteAlias = getBoolOption(fdp.Options, options.E_WriteAlias)
+
+	target.Aliases = append(target.Aliases, *f)
+	// Imports of aliases are collected with imports of their field, see
+	// fileImports.
+	target.Imports = append(target.Imports, f.Imports...)
+
+	return nil
+}
+
+// formatAliasFields returns statements which read model field from the first
+// alias which has a value if field f has no value, or write model field into
+// aliases with write_alias option. Default value of field is set if aliases
+// have no values too.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatAliasFields(f Field, swapped bool) string {
+	if len(f.Aliases) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	if swapped {
+		for _, a := range f.Aliases {
+			if a.WriteAlias {
+				fmt.Fprintf(&b, "\ts.%s = %s\n", a.ProtoName, formatComplexField(a, true))
+			}
+		}
+
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\tswitch {\n\tcase %s:\n", fmt.Sprintf(f.Presence, f.srcField(false)))
+	for _, a := range f.Aliases {
+		fmt.Fprintf(&b, "\tcase %s:\n\t\ts.%s = %s\n", fmt.Sprintf(a.Presence, a.srcField(false)), f.Name, formatComplexField(a, false))
+	}
+	// Default value is set if neither field nor its aliases have values.
+	if f.Default != "" {
+		fmt.Fprintf(&b, "\tdefault:\n\t\ts.%s = %s\n", f.Name, f.Default)
+	}
+	b.WriteString("\t}\n")
+
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Aliases", func() {
+	var (
+		str      = descriptor.FieldDescriptorProto_TYPE_STRING
+		boolean  = descriptor.FieldDescriptorProto_TYPE_BOOL
+		int64t   = descriptor.FieldDescriptorProto_TYPE_INT64
+		bytest   = descriptor.FieldDescriptorProto_TYPE_BYTES
+		msg      = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+		optional = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	// alias returns string proto field with alias_of option set to target.
+	alias := func(name, target string, write bool) *descriptor.FieldDescriptorProto {
+		fdp := newField(name, str, map[*proto.ExtensionDesc]interface{}{
+			options.E_AliasOf:    target,
+			options.E_WriteAlias: write,
+		})
+		fdp.Label = &optional
+
+		return fdp
+	}
+
+	DescribeTable("presenceCond",
+		func(fdp *descriptor.FieldDescriptorProto, fi fileInfo, expected string) {
+			cond, err := presenceCond(fdp, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cond).To(Equal(expected))
+		},
+
+		Entry("String", &descriptor.FieldDescriptorProto{Type: &str, Label: &optional}, fileInfo{gogofaster: true}, `%s != ""`),
+		Entry("Bool", &descriptor.FieldDescriptorProto{Type: &boolean, Label: &optional}, fileInfo{gogofaster: true}, "%s"),
+		Entry("Number", &descriptor.FieldDescriptorProto{Type: &int64t, Label: &optional}, fileInfo{gogofaster: true}, "%s != 0"),
+		Entry("Bytes", &descriptor.FieldDescriptorProto{Type: &bytest, Label: &optional}, fileInfo{gogofaster: true}, "%s != nil"),
+		Entry("Repeated", &descriptor.FieldDescriptorProto{Type: &str, Label: &repeated}, fileInfo{gogofaster: true}, "len(%s) > 0"),
+		Entry("Message", &descriptor.FieldDescriptorProto{Type: &msg, Label: &optional}, fileInfo{gogofaster: true}, "%s != nil"),
+		Entry("Proto2 string of gogo/protobuf", &descriptor.FieldDescriptorProto{Type: &str, Label: &optional}, fileInfo{proto2: true}, "%s != nil"),
+	)
+
+	It("presenceCond returns an error for non-nullable message", func() {
+		fdp := &descriptor.FieldDescriptorProto{Type: &msg, Label: &optional, Options: &descriptor.FieldOptions{}}
+		Expect(proto.SetExtension(fdp.Options, gogoproto.E_Nullable, bp(false))).To(Succeed())
+
+		_, err := presenceCond(fdp, fileInfo{gogofaster: true})
+		Expect(err).To(MatchError(errAliasValue))
+	})
+
+	Describe("processAliasField", func() {
+		var (
+			m      *descriptor.DescriptorProto
+			fields []Field
+			gf     source.Structure
+			fi     fileInfo
+		)
+
+		BeforeEach(func() {
+			m = &descriptor.DescriptorProto{
+				Name:  sp("Product"),
+				Field: []*descriptor.FieldDescriptorProto{{Name: sp("title"), Type: &str, Label: &optional}},
+			}
+			fields = []Field{{Name: "Title", ProtoName: "Title", ProtoPath: "title", ProtoType: "string"}}
+			gf = source.Structure{"Title": {Type: "string"}}
+			fi = fileInfo{gogofaster: true}
+		})
+
+		It("adds alias to target field", func() {
+			Expect(processAliasField(&bytes.Buffer{}, alias("name", "title", true), m, fields, nil, gf, fi)).To(Succeed())
+
+			Expect(fields[0].Presence).To(Equal(`%s != ""`))
+			Expect(fields[0].Aliases).To(HaveLen(1))
+			Expect(fields[0].Aliases[0].Name).To(Equal("Title"))
+			Expect(fields[0].Aliases[0].ProtoName).To(Equal("Name"))
+			Expect(fields[0].Aliases[0].Presence).To(Equal(`%s != ""`))
+			Expect(fields[0].Aliases[0].WriteAlias).To(BeTrue())
+		})
+
+		It("adds imports of alias to target field", func() {
+			ts := descriptor.FieldDescriptorProto_TYPE_MESSAGE
+			m.Field = append(m.Field, &descriptor.FieldDescriptorProto{Name: sp("created"), Type: &ts, Label: &optional, TypeName: sp(".google.protobuf.Timestamp")})
+			fields = append(fields, Field{Name: "Created", ProtoName: "Created", ProtoPath: "created"})
+			gf["Created"] = source.FieldInfo{Type: "int64"}
+
+			a := alias("created_at", "created", false)
+			a.Type, a.TypeName = &ts, sp(".google.protobuf.Timestamp")
+			Expect(proto.SetExtension(a.Options, options.E_TimeUnix, sp("s"))).To(Succeed())
+
+			Expect(processAliasField(&bytes.Buffer{}, a, m, fields, nil, gf, fi)).To(Succeed())
+			Expect(fields[1].Imports).To(Equal(fields[1].Aliases[0].Imports))
+			Expect(fields[1].Imports).NotTo(BeEmpty())
+		})
+
+		It("returns an error if target field not found", func() {
+			err := processAliasField(&bytes.Buffer{}, alias("name", "label", false), m, fields, nil, gf, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errAliasNotFound))
+		})
+	})
+
+	DescribeTable("formatAliasFields",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatAliasFields(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Field without aliases", Field{Name: "Title", ProtoName: "Title"}, false, ""),
+		Entry("Proto to Go", Field{
+			Name:      "Title",
+			ProtoName: "Title",
+			Presence:  `%s != ""`,
+			Aliases: []Field{
+				{Name: "Title", ProtoName: "Name", Presence: `%s != ""`},
+				{Name: "Title", ProtoName: "Label", Presence: `%s != ""`},
+			},
+		}, false, "\tswitch {\n\tcase src.Title != \"\":\n\tcase src.Name != \"\":\n\t\ts.Title = src.Name\n\tcase src.Label != \"\":\n\t\ts.Title = src.Label\n\t}\n"),
+		Entry("Go to proto", Field{
+			Name:      "Title",
+			ProtoName: "Title",
+			Aliases: []Field{
+				{Name: "Title", ProtoName: "Name", WriteAlias: true},
+				{Name: "Title", ProtoName: "Label"},
+			},
+		}, true, "\ts.Name = src.Title\n"),
+		Entry("Proto to Go with default value", Field{
+			Name:      "Title",
+			ProtoName: "Title",
+			Presence:  `%s != ""`,
+			Default:   `"untitled"`,
+			Aliases:   []Field{{Name: "Title", ProtoName: "Name", Presence: `%s != ""`}},
+		}, false, "\tswitch {\n\tcase src.Title != \"\":\n\tcase src.Name != \"\":\n\t\ts.Title = src.Name\n\tdefault:\n\t\ts.Title = \"untitled\"\n\t}\n"),
+	)
+})
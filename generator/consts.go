@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	errConstEntry     = errors.New("constant entries should be in form name=expression")
+	errConstNotFound  = errors.New("field from pb_const option not found")
+	errConstOneof     = errors.New("oneof fields can not be set to constant values")
+	errConstDuplicate = errors.New("field is already transformed, it should be skipped or transformed in the other direction only")
+)
+
+// absence contains conditions which are true if field has no value for
+// conditions returned by presenceCond.
+var absence = map[string]string{
+	"len(%s) > 0": "len(%s) == 0",
+	"%s != nil":   "%s == nil",
+	"%s":          "!%s",
+	`%s != ""`:    `%s == ""`,
+	"%s != 0":     "%s == 0",
+}
+
+// getStringListOption returns values of repeated string option opt of
+// message options m.
+func getStringListOption(m proto.Message, opt *proto.ExtensionDesc) []string {
+	if m == nil || !proto.HasExtension(m, opt) {
+		return nil
+	}
+
+	ext, err := proto.GetExtension(m, opt)
+	if err != nil {
+		return nil
+	}
+
+	values, _ := ext.([]string)
+
+	return values
+}
+
+// constEntries splits entries of transformer.model_const or
+// transformer.pb_const option into field names and Go expressions.
+func constEntries(entries []string) ([][2]string, error) {
+	out := make([][2]string, 0, len(entries))
+
+	for _, e := range entries {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, pkgerrors.Wrap(errConstEntry, e)
+		}
+
+		name, expr := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if err := checkExpr(expr); err != nil {
+			return nil, pkgerrors.Wrap(err, name)
+		}
+
+		out = append(out, [2]string{name, expr})
+	}
+
+	return out, nil
+}
+
+// constFields returns fields which are set to constant values: model fields
+// from transformer.model_const option of message msg are set in proto to
+// model functions, proto fields from transformer.pb_const option are set in
+// model to proto functions. Constant fields must not be transformed by other
+// fields in the same direction.
+func constFields(msg *descriptor.DescriptorProto, fields []Field, goStructFields source.Structure) ([]Field, error) {
+	if msg.Options == nil {
+		return nil, nil
+	}
+
+	modelConsts, err := constEntries(getStringListOption(msg.Options, options.E_ModelConst))
+	if err != nil {
+		return nil, err
+	}
+
+	pbConsts, err := constEntries(getStringListOption(msg.Options, options.E_PbConst))
+	if err != nil {
+		return nil, err
+	}
+
+	value, _ := getStringOption(msg.Options, options.E_ConstImports)
+	imports, err := exprImports(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Field
+
+	for _, c := range modelConsts {
+		if _, ok := goStructFields[c[0]]; !ok {
+			return nil, pkgerrors.Wrap(errors.New("field not found in destination structure"), c[0])
+		}
+
+		for _, f := range fields {
+			if f.Name == c[0] && f.transformed(false) {
+				return nil, pkgerrors.Wrap(errConstDuplicate, c[0])
+			}
+		}
+
+		out = append(out, Field{
+			Name:          c[0],
+			ProtoToGoExpr: c[1],
+			Direction:     options.Direction_PB_TO_MODEL,
+		})
+	}
+
+	for _, c := range pbConsts {
+		var fdp *descriptor.FieldDescriptorProto
+		for _, f := range msg.Field {
+			if f.GetName() == c[0] {
+				fdp = f
+			}
+		}
+
+		if fdp == nil {
+			return nil, pkgerrors.Wrap(errConstNotFound, c[0])
+		}
+		if fdp.OneofIndex != nil {
+			return nil, pkgerrors.Wrap(errConstOneof, c[0])
+		}
+
+		for _, f := range fields {
+			if f.ProtoPath == c[0] && f.transformed(true) {
+				return nil, pkgerrors.Wrap(errConstDuplicate, c[0])
+			}
+		}
+
+		pname, _ := prepareFieldNames(fdp.GetName(), protoGoName(fdp), "")
+		out = append(out, Field{
+			ProtoName:     pname,
+			GoToProtoExpr: c[1],
+			Direction:     options.Direction_MODEL_TO_PB,
+		})
+	}
+
+	// Imports are collected from all fields, see fileImports.
+	if len(out) > 0 {
+		out[0].Imports = imports
+	}
+
+	return out, nil
+}
+
+// formatDefaultField returns statement which sets default value of model
+// field if proto field has no value. Default values of fields with aliases
+// are set by formatAliasFields.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatDefaultField(f Field, swapped bool) string {
+	if swapped || f.Default == "" || len(f.Aliases) > 0 {
+		return ""
+	}
+
+	cond, ok := absence[f.Presence]
+	if !ok {
+		cond = "!(" + f.Presence + ")"
+	}
+
+	return fmt.Sprintf("\tif %s {\n\t\ts.%s = %s\n\t}\n", fmt.Sprintf(cond, f.srcField(false)), f.Name, f.Default)
+}
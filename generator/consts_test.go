@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Constants", func() {
+	var (
+		zero int32
+		str  = descriptor.FieldDescriptorProto_TYPE_STRING
+	)
+
+	goStructFields := source.Structure{
+		"Name":    {Type: "string"},
+		"Source":  {Type: "string"},
+		"Version": {Type: "int"},
+	}
+
+	// message returns proto message with constant options.
+	message := func(modelConsts, pbConsts []string, imports string) *descriptor.DescriptorProto {
+		m := &descriptor.DescriptorProto{
+			Name: sp("Product"),
+			Field: []*descriptor.FieldDescriptorProto{
+				{Name: sp("name"), Type: &str},
+				{Name: sp("origin"), Type: &str},
+				{Name: sp("sku"), Type: &str, OneofIndex: &zero},
+			},
+			Options: &descriptor.MessageOptions{},
+		}
+		for ext, v := range map[*proto.ExtensionDesc][]string{options.E_ModelConst: modelConsts, options.E_PbConst: pbConsts} {
+			if v == nil {
+				continue
+			}
+			if err := proto.SetExtension(m.Options, ext, v); err != nil {
+				panic(err)
+			}
+		}
+		if err := proto.SetExtension(m.Options, options.E_ConstImports, sp(imports)); err != nil {
+			panic(err)
+		}
+
+		return m
+	}
+
+	fields := []Field{{Name: "Name", ProtoName: "Name", ProtoPath: "name"}}
+
+	DescribeTable("constFields",
+		func(msg *descriptor.DescriptorProto, expected []Field) {
+			cf, err := constFields(msg, fields, goStructFields)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cf).To(Equal(expected))
+		},
+
+		Entry("Message without options", &descriptor.DescriptorProto{Name: sp("Product")}, nil),
+		Entry("Model constants", message([]string{`Source="api"`, "Version = 1"}, nil, ""), []Field{
+			{Name: "Source", ProtoToGoExpr: `"api"`, Direction: options.Direction_PB_TO_MODEL},
+			{Name: "Version", ProtoToGoExpr: "1", Direction: options.Direction_PB_TO_MODEL},
+		}),
+		Entry("Proto constants with imports", message(nil, []string{"origin=strings.ToUpper(\"db\")"}, "strings"), []Field{
+			{ProtoName: "Origin", GoToProtoExpr: `strings.ToUpper("db")`, Direction: options.Direction_MODEL_TO_PB, Imports: []string{`"strings"`}},
+		}),
+	)
+
+	DescribeTable("constFields returns an error",
+		func(msg *descriptor.DescriptorProto, expected interface{}) {
+			_, err := constFields(msg, fields, goStructFields)
+			Expect(pkgerrors.Cause(err)).To(MatchError(expected))
+		},
+
+		Entry("Entry without expression", message([]string{"Source"}, nil, ""), errConstEntry),
+		Entry("Entry without name", message(nil, []string{`="db"`}, ""), errConstEntry),
+		Entry("Model field not found", message([]string{"Kind=1"}, nil, ""), "field not found in destination structure"),
+		Entry("Model field is transformed", message([]string{`Name="n"`}, nil, ""), errConstDuplicate),
+		Entry("Proto field not found", message(nil, []string{"kind=1"}, ""), errConstNotFound),
+		Entry("Proto field is transformed", message(nil, []string{`name="n"`}, ""), errConstDuplicate),
+		Entry("Oneof field", message(nil, []string{`sku="n"`}, ""), errConstOneof),
+		Entry("Invalid import", message([]string{"Version=1"}, nil, "a b c"), errExprImport),
+	)
+
+	It("constFields returns an error for invalid expressions", func() {
+		_, err := constFields(message([]string{"Version=1 +"}, nil, ""), fields, goStructFields)
+		Expect(err).To(MatchError(ContainSubstring(`Version: invalid Go expression "1 +"`)))
+	})
+
+	It("constFields allows constants of fields transformed in the other direction", func() {
+		fields := []Field{{Name: "Name", ProtoName: "Name", ProtoPath: "name", Direction: options.Direction_PB_TO_MODEL}}
+
+		cf, err := constFields(message(nil, []string{`name="n"`}, ""), fields, goStructFields)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cf).To(HaveLen(1))
+	})
+
+	It("constants are transformed in one direction", func() {
+		d := &Data{Fields: []Field{
+			{Name: "Source", ProtoToGoExpr: `"api"`, Direction: options.Direction_PB_TO_MODEL},
+			{ProtoName: "Origin", GoToProtoExpr: `"db"`, Direction: options.Direction_MODEL_TO_PB},
+		}}
+
+		Expect(formatField(d.directed().Fields[0], false, "model")).To(Equal(`Source: "api",`))
+		d.swap()
+		Expect(formatField(d.directed().Fields[0], true, "pb")).To(Equal(`Origin: "db",`))
+	})
+
+	It("newFieldMaskData skips constants", func() {
+		d := &Data{Fields: []Field{
+			{Name: "ID", ProtoPath: "id"},
+			{Name: "Source", ProtoToGoExpr: `"api"`},
+			{ProtoName: "Origin", GoToProtoExpr: `"db"`},
+		}}
+		Expect(newFieldMaskData(d).Fields).To(Equal([]Field{{Name: "ID", ProtoPath: "id"}}))
+	})
+
+	DescribeTable("formatDefaultField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatDefaultField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Without default value", Field{Name: "Currency", ProtoName: "Currency", Presence: `%s != ""`}, false, ""),
+		Entry("Go to proto", Field{Name: "Currency", ProtoName: "Currency", Presence: `%s != ""`, Default: `"USD"`}, true, ""),
+		Entry("String", Field{Name: "Currency", ProtoName: "Currency", Presence: `%s != ""`, Default: `"USD"`}, false,
+			"\tif src.Currency == \"\" {\n\t\ts.Currency = \"USD\"\n\t}\n"),
+		Entry("Pointer", Field{Name: "Limit", ProtoName: "Limit", Presence: "%s != nil", Default: "10"}, false,
+			"\tif src.Limit == nil {\n\t\ts.Limit = 10\n\t}\n"),
+		Entry("Bool", Field{Name: "Active", ProtoName: "Active", Presence: "%s", Default: "true"}, false,
+			"\tif !src.Active {\n\t\ts.Active = true\n\t}\n"),
+		Entry("Getter", Field{Name: "Tags", ProtoName: "Tags", Getter: true, Presence: "len(%s) > 0", Default: "[]string{}"}, false,
+			"\tif len(src.GetTags()) == 0 {\n\t\ts.Tags = []string{}\n\t}\n"),
+		Entry("Other conditions", Field{Name: "At", ProtoName: "At", Presence: "!%s.IsZero()", Default: "time.Now()"}, false,
+			"\tif !(!src.At.IsZero()) {\n\t\ts.At = time.Now()\n\t}\n"),
+		Entry("Field with aliases", Field{Name: "Currency", ProtoName: "Currency", Presence: `%s != ""`, Default: `"USD"`, Aliases: []Field{{}}}, false, ""),
+	)
+})
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	gogen "github.com/gogo/protobuf/protoc-gen-gogo/generator"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+// errNoTransformer is returned for sub message field if transformer functions
+// of its message are neither generated nor declared by go_converter option.
+type errNoTransformer struct {
+	// Field path, e.g. "shop.Order.total".
+	path string
+	// Full name of sub message, e.g. "shop.Money".
+	message string
+}
+
+func (e errNoTransformer) Error() string {
+	return fmt.Sprintf("%s: message %s has no transformer, set option %q or %q, or add model structure %s",
+		e.path, e.message, options.E_GoStruct.Name, options.E_GoConverter.Name, gogen.CamelCase(lastName(e.message)))
+}
+
+// messageNode is a node of dependency graph of messages.
+type messageNode struct {
+	// Full name of file which declares message.
+	file string
+	// Full names of messages referenced by sub message fields, which are
+	// transformed by functions of these messages.
+	refs []string
+}
+
+// messageGraph returns dependency graph of top-level messages of all files
+// passed within plugin request, map key is a full message name without
+// leading dot, e.g. "shop.Order".
+func messageGraph(req plugin.CodeGeneratorRequest) map[string]messageNode {
+	graph := map[string]messageNode{}
+
+	for _, f := range req.ProtoFile {
+		for _, m := range f.MessageType {
+			graph[fmt.Sprintf("%s.%s", f.GetPackage(), m.GetName())] = messageNode{
+				file: f.GetName(),
+				refs: messageRefs(m),
+			}
+		}
+	}
+
+	return graph
+}
+
+// messageRefs returns full names of messages of sub message fields of message
+// m. Fields with skip, custom and force_assignable options are not
+// transformed by sub message functions and are ignored.
+func messageRefs(m *descriptor.DescriptorProto) []string {
+	var refs []string
+
+	for _, fdp := range m.Field {
+		if fdp.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE || fdp.TypeName == nil {
+			continue
+		}
+
+		if getBoolOption(fdp.Options, options.E_Skip) ||
+			getBoolOption(fdp.Options, options.E_Custom) ||
+			getBoolOption(fdp.Options, options.E_ForceAssignable) {
+			continue
+		}
+
+		refs = append(refs, fdp.GetTypeName()[1:])
+	}
+
+	return refs
+}
+
+// resolveSubMessages links messages without go_struct option, which are
+// referenced by transformed messages, with model structures of the same names.
+// Structures are looked up in models file of the file which declares
+// referenced message, so its transformer functions are generated with other
+// functions of that file. Referenced messages are resolved recursively,
+// because their functions transform their sub messages too.
+func resolveSubMessages(req plugin.CodeGeneratorRequest, mol MessageOptionList) {
+	graph := messageGraph(req)
+
+	files := map[string]*descriptor.FileDescriptorProto{}
+	for _, f := range req.ProtoFile {
+		files[f.GetName()] = f
+	}
+
+	// Models are parsed once per file, nil is stored for files without
+	// models.
+	models := map[string]source.StructureList{}
+	structs := func(name string) source.StructureList {
+		if sl, ok := models[name]; ok {
+			return sl
+		}
+
+		var sl source.StructureList
+		if f := files[name]; f.Options != nil {
+			if path, err := modelsPath(f.Options); err == nil {
+				sl, _ = source.Parse(path, nil)
+			}
+		}
+		models[name] = sl
+
+		return sl
+	}
+
+	var queue []string
+	for name, mo := range mol {
+		if !mo.Omitted() && !mo.Declared() {
+			queue = append(queue, name)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, ref := range graph[name].refs {
+			mo, ok := mol[ref]
+			if !ok || !mo.Omitted() || mo.OneofDecl() != "" {
+				continue
+			}
+
+			target := gogen.CamelCase(lastName(ref))
+			if _, err := source.Lookup(structs(graph[ref].file), target); err != nil {
+				continue
+			}
+
+			mol[ref] = messageOption{
+				targetName: target,
+				fieldMask:  mo.FieldMask(),
+			}
+			queue = append(queue, ref)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dependency", func() {
+	var (
+		str = descriptor.FieldDescriptorProto_TYPE_STRING
+		msg = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	// field returns proto field of type typeName, options are set if ext isn't
+	// nil.
+	field := func(name, typeName string, ext *proto.ExtensionDesc) *descriptor.FieldDescriptorProto {
+		opts := map[*proto.ExtensionDesc]interface{}{}
+		if ext != nil {
+			opts[ext] = true
+		}
+		fdp := newField(name, msg, opts)
+		fdp.TypeName = sp(typeName)
+
+		return fdp
+	}
+
+	// message returns proto message with string option ext if it isn't nil.
+	message := func(name string, ext *proto.ExtensionDesc, value string, fields ...*descriptor.FieldDescriptorProto) *descriptor.DescriptorProto {
+		m := &descriptor.DescriptorProto{Name: sp(name), Field: fields}
+		if ext != nil {
+			m.Options = &descriptor.MessageOptions{}
+			if err := proto.SetExtension(m.Options, ext, sp(value)); err != nil {
+				panic(err)
+			}
+		}
+
+		return m
+	}
+
+	It("messageRefs returns types of transformed sub message fields", func() {
+		m := message("Order", nil, "",
+			&descriptor.FieldDescriptorProto{Name: sp("id"), Type: &str},
+			field("total", ".shop.Money", nil),
+			field("tax", ".shop.Money", options.E_Skip),
+			field("discount", ".shop.Discount", options.E_Custom),
+			field("raw", ".shop.Raw", options.E_ForceAssignable),
+			field("created", ".google.protobuf.Timestamp", nil),
+		)
+
+		Expect(messageRefs(m)).To(Equal([]string{"shop.Money", "google.protobuf.Timestamp"}))
+	})
+
+	Describe("CollectAllMessages", func() {
+		var mol MessageOptionList
+
+		BeforeEach(func() {
+			f := &descriptor.FileDescriptorProto{
+				Name:    sp("shop.proto"),
+				Package: sp("shop"),
+				Options: &descriptor.FileOptions{},
+				MessageType: []*descriptor.DescriptorProto{
+					message("Order", options.E_GoStruct, "Order",
+						field("total", ".shop.Money", nil),
+						field("rate", ".shop.Rate", nil),
+						field("tax", ".shop.Tax", nil),
+					),
+					message("Money", nil, "", field("currency", ".shop.Currency", nil)),
+					message("Currency", nil, ""),
+					message("Rate", options.E_GoConverter, "Rate"),
+					message("Tax", nil, ""),
+					message("Unused", nil, "", field("currency", ".shop.Currency", nil)),
+					message("Category", options.E_GoStruct, "Category",
+						field("children", ".shop.Category", nil),
+						field("root", ".shop.Node", nil),
+					),
+					message("Node", nil, "", field("edges", ".shop.Edge", nil)),
+					message("Edge", nil, "", field("to", ".shop.Node", nil)),
+				},
+			}
+			Expect(proto.SetExtension(f.Options, options.E_GoModelsFilePath, sp("testdata/graph.go"))).To(Succeed())
+
+			var err error
+			mol, err = CollectAllMessages(plugin.CodeGeneratorRequest{ProtoFile: []*descriptor.FileDescriptorProto{f}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("links referenced messages with model structures", func() {
+			Expect(mol["shop.Money"].Target()).To(Equal("Money"))
+			Expect(mol["shop.Currency"].Target()).To(Equal("Currency"))
+			Expect(mol["shop.Money"].Declared()).To(BeFalse())
+		})
+
+		It("links recursive messages", func() {
+			Expect(mol["shop.Category"].Target()).To(Equal("Category"))
+			Expect(mol["shop.Node"].Target()).To(Equal("Node"))
+			Expect(mol["shop.Edge"].Target()).To(Equal("Edge"))
+		})
+
+		It("keeps declared converters", func() {
+			Expect(mol["shop.Rate"].Target()).To(Equal("Rate"))
+			Expect(mol["shop.Rate"].Declared()).To(BeTrue())
+		})
+
+		It("skips messages without model structures", func() {
+			Expect(mol["shop.Tax"].Omitted()).To(BeTrue())
+		})
+	})
+
+	It("processField returns field path of sub message without transformer", func() {
+		fi := fileInfo{message: "shop.Order"}
+		gf := source.Structure{"Tax": {Type: "Tax", IsPointer: true}}
+		mol := MessageOptionList{"shop.Tax": messageOption{}}
+
+		_, err := processField(nil, field("tax", ".shop.Tax", nil), mol, gf, fi)
+		Expect(err).To(MatchError(`shop.Order.tax: message shop.Tax has no transformer, set option "transformer.go_struct" or "transformer.go_converter", or add model structure Tax`))
+	})
+})
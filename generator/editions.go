@@ -0,0 +1,292 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	gogen "github.com/gogo/protobuf/protoc-gen-gogo/generator"
+)
+
+// Descriptors of gogo/protobuf have no fields for protobuf editions, such
+// fields are kept as unrecognized bytes and are decoded here.
+
+const (
+	// Values of google.protobuf.Edition enum.
+	editionProto2 = 998
+	editionProto3 = 999
+	edition2023   = 1000
+
+	// Field numbers of edition and features fields in descriptors.
+	fileEditionField     = 14
+	fileFeaturesField    = 50
+	messageFeaturesField = 12
+	fieldFeaturesField   = 21
+	enumFeaturesField    = 7
+
+	// Field numbers of google.protobuf.FeatureSet.
+	fieldPresenceFeature = 1
+	enumTypeFeature      = 2
+
+	// Field numbers of google.protobuf.compiler.CodeGeneratorResponse.
+	supportedFeaturesField = 2
+	minimumEditionField    = 3
+	maximumEditionField    = 4
+	// CodeGeneratorResponse.Feature FEATURE_PROTO3_OPTIONAL and
+	// FEATURE_SUPPORTS_EDITIONS.
+	featureProto3Optional   = 1
+	featureSupportsEditions = 2
+)
+
+// Values of FeatureSet.FieldPresence enum.
+const (
+	fieldPresenceExplicit       = 1
+	fieldPresenceImplicit       = 2
+	fieldPresenceLegacyRequired = 3
+)
+
+// Values of FeatureSet.EnumType enum.
+const (
+	enumTypeOpen   = 1
+	enumTypeClosed = 2
+)
+
+// featureSet contains resolved features of protobuf editions which are used
+// by generator.
+type featureSet struct {
+	fieldPresence int32
+	enumType      int32
+}
+
+// editionDefaults returns default features of edition. Editions newer than
+// 2023 have the same defaults for supported features.
+func editionDefaults(edition int32) featureSet {
+	switch edition {
+	case editionProto2:
+		return featureSet{fieldPresence: fieldPresenceExplicit, enumType: enumTypeClosed}
+	case editionProto3:
+		return featureSet{fieldPresence: fieldPresenceImplicit, enumType: enumTypeOpen}
+	}
+
+	return featureSet{fieldPresence: fieldPresenceExplicit, enumType: enumTypeOpen}
+}
+
+// merge returns features fs overridden by features encoded in raw
+// google.protobuf.FeatureSet message.
+func (fs featureSet) merge(raw []byte) featureSet {
+	rangeFields(raw, func(num int32, v uint64, _ []byte) {
+		switch {
+		case v == 0:
+		case num == fieldPresenceFeature:
+			fs.fieldPresence = int32(v)
+		case num == enumTypeFeature:
+			fs.enumType = int32(v)
+		}
+	})
+
+	return fs
+}
+
+// rangeFields calls fn for each varint and length-delimited field encoded in
+// b. Decoding stops at malformed data.
+func rangeFields(b []byte, fn func(num int32, v uint64, data []byte)) {
+	for len(b) > 0 {
+		key, n := proto.DecodeVarint(b)
+		if n == 0 {
+			return
+		}
+		b = b[n:]
+		num := int32(key >> 3)
+
+		switch key & 7 {
+		case proto.WireVarint:
+			v, n := proto.DecodeVarint(b)
+			if n == 0 {
+				return
+			}
+			fn(num, v, nil)
+			b = b[n:]
+		case proto.WireFixed64:
+			if len(b) < 8 {
+				return
+			}
+			b = b[8:]
+		case proto.WireBytes:
+			l, n := proto.DecodeVarint(b)
+			if n == 0 || l > uint64(len(b)-n) {
+				return
+			}
+			fn(num, 0, b[n:n+int(l)])
+			b = b[n+int(l):]
+		case proto.WireFixed32:
+			if len(b) < 4 {
+				return
+			}
+			b = b[4:]
+		default:
+			return
+		}
+	}
+}
+
+// rawFeatures returns concatenated google.protobuf.FeatureSet messages
+// encoded in field num of unrecognized bytes b.
+func rawFeatures(b []byte, num int32) []byte {
+	var fs []byte
+	rangeFields(b, func(n int32, _ uint64, data []byte) {
+		if n == num {
+			fs = append(fs, data...)
+		}
+	})
+
+	return fs
+}
+
+// fileFeatures returns features of file f which are resolved from edition
+// defaults and file options. The second return value is false if file
+// doesn't use editions syntax.
+func fileFeatures(f *descriptor.FileDescriptorProto) (featureSet, bool) {
+	if f.GetSyntax() != "editions" {
+		return featureSet{}, false
+	}
+
+	edition := int32(edition2023)
+	rangeFields(f.XXX_unrecognized, func(num int32, v uint64, _ []byte) {
+		if num == fileEditionField {
+			edition = int32(v)
+		}
+	})
+
+	fs := editionDefaults(edition)
+	if o := f.Options; o != nil {
+		fs = fs.merge(rawFeatures(o.XXX_unrecognized, fileFeaturesField))
+	}
+
+	return fs, true
+}
+
+// messageFeatures returns features fs overridden by message options.
+func messageFeatures(fs featureSet, msg *descriptor.DescriptorProto) featureSet {
+	if o := msg.GetOptions(); o != nil {
+		return fs.merge(rawFeatures(o.XXX_unrecognized, messageFeaturesField))
+	}
+
+	return fs
+}
+
+// fieldFeatures returns features fs overridden by field options.
+func fieldFeatures(fs featureSet, fdp *descriptor.FieldDescriptorProto) featureSet {
+	if o := fdp.GetOptions(); o != nil {
+		return fs.merge(rawFeatures(o.XXX_unrecognized, fieldFeaturesField))
+	}
+
+	return fs
+}
+
+// closedEnums returns closed enums declared in editions file f. Map key is a
+// full name of enum type, value is a name of Go map with names of enum values,
+// e.g. "Product_Color_name", built like protoc-gen-gogo does.
+func closedEnums(f *descriptor.FileDescriptorProto, fs featureSet) map[string]string {
+	enums := map[string]string{}
+
+	add := func(scope []string, prefix string, fs featureSet, eds []*descriptor.EnumDescriptorProto) {
+		for _, ed := range eds {
+			efs := fs
+			if o := ed.GetOptions(); o != nil {
+				efs = fs.merge(rawFeatures(o.XXX_unrecognized, enumFeaturesField))
+			}
+			if efs.enumType == enumTypeClosed {
+				enums[prefix+ed.GetName()] = gogen.CamelCaseSlice(append(scope[:len(scope):len(scope)], ed.GetName())) + "_name"
+			}
+		}
+	}
+
+	prefix := "."
+	if pkg := f.GetPackage(); pkg != "" {
+		prefix += pkg + "."
+	}
+
+	add(nil, prefix, fs, f.EnumType)
+
+	var walk func(scope []string, prefix string, fs featureSet, msgs []*descriptor.DescriptorProto)
+	walk = func(scope []string, prefix string, fs featureSet, msgs []*descriptor.DescriptorProto) {
+		for _, m := range msgs {
+			mfs := messageFeatures(fs, m)
+			s := append(scope[:len(scope):len(scope)], m.GetName())
+			p := prefix + m.GetName() + "."
+			add(s, p, mfs, m.EnumType)
+			walk(s, p, mfs, m.NestedType)
+		}
+	}
+
+	walk(nil, prefix, fs, f.MessageType)
+
+	return enums
+}
+
+// hasPresence returns true if scalar field fdp tracks presence, i.e. it's a
+// field of proto2 file, optional field of proto3 file or field with explicit
+// presence in editions file.
+func (fi fileInfo) hasPresence(fdp *descriptor.FieldDescriptorProto) bool {
+	if isProto3Optional(fdp) {
+		return true
+	}
+
+	if !fi.editions {
+		return fi.proto2
+	}
+
+	return fieldFeatures(fi.features, fdp).fieldPresence != fieldPresenceImplicit
+}
+
+// isRequired returns true if field fdp is a required field of proto2 file or
+// field with LEGACY_REQUIRED presence in editions file.
+func (fi fileInfo) isRequired(fdp *descriptor.FieldDescriptorProto) bool {
+	if !fi.editions {
+		return fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REQUIRED
+	}
+
+	return fieldFeatures(fi.features, fdp).fieldPresence == fieldPresenceLegacyRequired
+}
+
+// formatClosedEnumField returns check that value of closed enum field which is
+// converted from model is declared in enum. Runtimes keep undeclared values of
+// closed enums in unknown fields, so proto to Go conversion isn't checked.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatClosedEnumField(f Field, swapped bool) string {
+	if f.ClosedEnum == "" || !swapped {
+		return ""
+	}
+
+	report := fmt.Sprintf("reportError(opts, fmt.Errorf(\"%s: value %%d is not declared in closed enum\", v))", f.ProtoName)
+
+	if f.ProtoIsPointer {
+		return fmt.Sprintf("\tif p := s.%s; p != nil {\n\t\tif v := *p; %s[int32(v)] == \"\" {\n\t\t\t%s\n\t\t}\n\t}\n",
+			f.ProtoName, f.ClosedEnum, report)
+	}
+
+	return fmt.Sprintf("\tif v := s.%s; %s[int32(v)] == \"\" {\n\t\t%s\n\t}\n",
+		f.ProtoName, f.ClosedEnum, report)
+}
+
+// EditionsSupport returns encoded fields of CodeGeneratorResponse which
+// declare support of protobuf editions and proto3 optional fields. Response of
+// gogo/protobuf has no such fields, so bytes should be added to its
+// unrecognized fields.
+func EditionsSupport() []byte {
+	var b []byte
+	for _, f := range []struct {
+		num int32
+		v   uint64
+	}{
+		{supportedFeaturesField, featureProto3Optional | featureSupportsEditions},
+		{minimumEditionField, edition2023},
+		{maximumEditionField, edition2023},
+	} {
+		b = append(b, proto.EncodeVarint(uint64(f.num)<<3|proto.WireVarint)...)
+		b = append(b, proto.EncodeVarint(f.v)...)
+	}
+
+	return b
+}
@@ -0,0 +1,197 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// varintField returns encoded varint field.
+func varintField(num int32, v uint64) []byte {
+	b := proto.EncodeVarint(uint64(num)<<3 | proto.WireVarint)
+	return append(b, proto.EncodeVarint(v)...)
+}
+
+// bytesField returns encoded length-delimited field.
+func bytesField(num int32, data []byte) []byte {
+	b := proto.EncodeVarint(uint64(num)<<3 | proto.WireBytes)
+	b = append(b, proto.EncodeVarint(uint64(len(data)))...)
+	return append(b, data...)
+}
+
+var _ = Describe("Editions", func() {
+	var (
+		i32 = descriptor.FieldDescriptorProto_TYPE_INT32
+		enm = descriptor.FieldDescriptorProto_TYPE_ENUM
+		opt = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+
+		implicit = varintField(fieldPresenceFeature, fieldPresenceImplicit)
+		required = varintField(fieldPresenceFeature, fieldPresenceLegacyRequired)
+		closed   = varintField(enumTypeFeature, enumTypeClosed)
+	)
+
+	// file returns editions file with features set in file options.
+	file := func(edition uint64, features []byte) *descriptor.FileDescriptorProto {
+		f := &descriptor.FileDescriptorProto{
+			Package:          sp("pb"),
+			Syntax:           sp("editions"),
+			XXX_unrecognized: varintField(fileEditionField, edition),
+		}
+		if features != nil {
+			f.Options = &descriptor.FileOptions{XXX_unrecognized: bytesField(fileFeaturesField, features)}
+		}
+
+		return f
+	}
+
+	// field returns int32 field with features set in field options.
+	field := func(features []byte) *descriptor.FieldDescriptorProto {
+		fdp := newField("int32_field", i32, nil)
+		fdp.Label = &opt
+		if features != nil {
+			fdp.Options = &descriptor.FieldOptions{XXX_unrecognized: bytesField(fieldFeaturesField, features)}
+		}
+
+		return fdp
+	}
+
+	DescribeTable("fileFeatures",
+		func(f *descriptor.FileDescriptorProto, expected featureSet, editions bool) {
+			fs, ok := fileFeatures(f)
+			Expect(ok).To(Equal(editions))
+			Expect(fs).To(Equal(expected))
+		},
+
+		Entry("proto3 file", &descriptor.FileDescriptorProto{Syntax: sp("proto3")}, featureSet{}, false),
+		Entry("Edition 2023", file(edition2023, nil), featureSet{fieldPresence: fieldPresenceExplicit, enumType: enumTypeOpen}, true),
+		Entry("Edition proto2", file(editionProto2, nil), featureSet{fieldPresence: fieldPresenceExplicit, enumType: enumTypeClosed}, true),
+		Entry("Edition proto3", file(editionProto3, nil), featureSet{fieldPresence: fieldPresenceImplicit, enumType: enumTypeOpen}, true),
+		Entry("File features", file(edition2023, append(implicit, closed...)), featureSet{fieldPresence: fieldPresenceImplicit, enumType: enumTypeClosed}, true),
+	)
+
+	It("messageFeatures", func() {
+		fs := featureSet{fieldPresence: fieldPresenceExplicit, enumType: enumTypeOpen}
+		msg := &descriptor.DescriptorProto{
+			Options: &descriptor.MessageOptions{XXX_unrecognized: bytesField(messageFeaturesField, implicit)},
+		}
+
+		Expect(messageFeatures(fs, msg)).To(Equal(featureSet{fieldPresence: fieldPresenceImplicit, enumType: enumTypeOpen}))
+		Expect(messageFeatures(fs, &descriptor.DescriptorProto{})).To(Equal(fs))
+	})
+
+	DescribeTable("isPointerScalar",
+		func(fdp *descriptor.FieldDescriptorProto, fs featureSet, expected bool) {
+			fi := fileInfo{editions: true, features: fs, gogofaster: true}
+			Expect(isPointerScalar(fdp, fi)).To(Equal(expected))
+		},
+
+		Entry("Explicit presence", field(nil), featureSet{fieldPresence: fieldPresenceExplicit}, true),
+		Entry("Implicit presence", field(nil), featureSet{fieldPresence: fieldPresenceImplicit}, false),
+		Entry("Implicit presence of field", field(implicit), featureSet{fieldPresence: fieldPresenceExplicit}, false),
+		Entry("Legacy required field", field(required), featureSet{fieldPresence: fieldPresenceImplicit}, true),
+	)
+
+	DescribeTable("isRequired",
+		func(fdp *descriptor.FieldDescriptorProto, fi fileInfo, expected bool) {
+			Expect(fi.isRequired(fdp)).To(Equal(expected))
+		},
+
+		Entry("Legacy required field", field(required), fileInfo{editions: true}, true),
+		Entry("Explicit presence", field(nil), fileInfo{editions: true, features: featureSet{fieldPresence: fieldPresenceExplicit}}, false),
+		Entry("Required field of proto2 file", &descriptor.FieldDescriptorProto{Label: descriptor.FieldDescriptorProto_LABEL_REQUIRED.Enum()}, fileInfo{proto2: true}, true),
+	)
+
+	It("closedEnums", func() {
+		f := file(edition2023, nil)
+		f.EnumType = []*descriptor.EnumDescriptorProto{
+			{Name: sp("Open")},
+			{Name: sp("Color"), Options: &descriptor.EnumOptions{XXX_unrecognized: bytesField(enumFeaturesField, closed)}},
+		}
+		f.MessageType = []*descriptor.DescriptorProto{{
+			Name:     sp("Product"),
+			Options:  &descriptor.MessageOptions{XXX_unrecognized: bytesField(messageFeaturesField, closed)},
+			EnumType: []*descriptor.EnumDescriptorProto{{Name: sp("Kind")}},
+		}}
+
+		fs, _ := fileFeatures(f)
+		Expect(closedEnums(f, fs)).To(Equal(map[string]string{
+			".pb.Color":        "Color_name",
+			".pb.Product.Kind": "Product_Kind_name",
+		}))
+	})
+
+	It("closedEnums names maps like protoc-gen-gogo", func() {
+		f := file(edition2023, nil)
+		f.EnumType = []*descriptor.EnumDescriptorProto{
+			{Name: sp("status_2"), Options: &descriptor.EnumOptions{XXX_unrecognized: bytesField(enumFeaturesField, closed)}},
+		}
+		f.MessageType = []*descriptor.DescriptorProto{{
+			Name:     sp("order_v2"),
+			Options:  &descriptor.MessageOptions{XXX_unrecognized: bytesField(messageFeaturesField, closed)},
+			EnumType: []*descriptor.EnumDescriptorProto{{Name: sp("kind_3")}},
+		}}
+
+		fs, _ := fileFeatures(f)
+		Expect(closedEnums(f, fs)).To(Equal(map[string]string{
+			".pb.status_2":        "Status_2_name",
+			".pb.order_v2.kind_3": "OrderV2Kind_3_name",
+		}))
+	})
+
+	It("processField sets closed enum", func() {
+		fi := fileInfo{
+			protoPackage: "pb",
+			editions:     true,
+			features:     featureSet{fieldPresence: fieldPresenceImplicit, enumType: enumTypeOpen},
+			closedEnums:  map[string]string{".pb.Color": "Color_name"},
+		}
+
+		f, err := processField(nil, &descriptor.FieldDescriptorProto{
+			Name:     sp("int32_field"),
+			Type:     &enm,
+			TypeName: sp(".pb.Color"),
+			Label:    &opt,
+		}, nil, goStruct, fi)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.ClosedEnum).To(Equal("pb.Color_name"))
+		Expect(f.ProtoIsPointer).To(BeFalse())
+	})
+
+	DescribeTable("formatClosedEnumField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatClosedEnumField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Not a closed enum", Field{ProtoName: "Color"}, true, ""),
+		Entry("Proto to Go", Field{ProtoName: "Color", ClosedEnum: "pb.Color_name"}, false, ""),
+		Entry("Go to proto", Field{ProtoName: "Color", ClosedEnum: "pb.Color_name"}, true, `	if v := s.Color; pb.Color_name[int32(v)] == "" {
+		reportError(opts, fmt.Errorf("Color: value %d is not declared in closed enum", v))
+	}
+`),
+		Entry("Go to proto pointer", Field{ProtoName: "Color", ClosedEnum: "pb.Color_name", ProtoIsPointer: true}, true, `	if p := s.Color; p != nil {
+		if v := *p; pb.Color_name[int32(v)] == "" {
+			reportError(opts, fmt.Errorf("Color: value %d is not declared in closed enum", v))
+		}
+	}
+`),
+	)
+
+	It("EditionsSupport", func() {
+		b, err := proto.Marshal(&plugin.CodeGeneratorResponse{XXX_unrecognized: EditionsSupport()})
+		Expect(err).NotTo(HaveOccurred())
+
+		fields := map[int32]uint64{}
+		rangeFields(b, func(num int32, v uint64, _ []byte) {
+			fields[num] = v
+		})
+
+		Expect(fields).To(Equal(map[int32]uint64{
+			supportedFeaturesField: featureProto3Optional | featureSupportsEditions,
+			minimumEditionField:    edition2023,
+			maximumEditionField:    edition2023,
+		}))
+	})
+})
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"errors"
+	"go/parser"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var errExprImport = errors.New("import entries should be in form path or name path")
+
+// exprOptions contains Go expressions from transformer.to_model_expr and
+// transformer.to_pb_expr options of field, import specs from
+// transformer.expr_imports option and default value from
+// transformer.default_value option.
+type exprOptions struct {
+	toModel  string
+	toPb     string
+	imports  []string
+	defValue string
+}
+
+// extractExprOptions returns expression options of field fdp. Expressions are
+// parsed, so invalid expressions are reported during generation.
+func extractExprOptions(fdp *descriptor.FieldDescriptorProto) (exprOptions, error) {
+	var o exprOptions
+	if fdp.Options == nil {
+		return o, nil
+	}
+
+	o.toModel, _ = getStringOption(fdp.Options, options.E_ToModelExpr)
+	o.toPb, _ = getStringOption(fdp.Options, options.E_ToPbExpr)
+	o.defValue, _ = getStringOption(fdp.Options, options.E_DefaultValue)
+
+	for _, e := range []string{o.toModel, o.toPb, o.defValue} {
+		if err := checkExpr(e); err != nil {
+			return o, err
+		}
+	}
+
+	value, _ := getStringOption(fdp.Options, options.E_ExprImports)
+	imports, err := exprImports(value)
+	if err != nil {
+		return o, err
+	}
+	o.imports = imports
+
+	return o, nil
+}
+
+// checkExpr returns an error if e isn't empty and isn't a valid Go
+// expression.
+func checkExpr(e string) error {
+	if e == "" {
+		return nil
+	}
+
+	if _, err := parser.ParseExpr(e); err != nil {
+		return pkgerrors.Wrapf(err, "invalid Go expression %q", e)
+	}
+
+	return nil
+}
+
+// exprImports returns import specs from comma separated list of packages,
+// each package is "path" or "name path".
+func exprImports(value string) ([]string, error) {
+	var imports []string
+
+	for _, e := range strings.Split(value, ",") {
+		switch parts := strings.Fields(e); len(parts) {
+		case 0:
+		case 1:
+			imports = append(imports, strconv.Quote(parts[0]))
+		case 2:
+			imports = append(imports, parts[0]+" "+strconv.Quote(parts[1]))
+		default:
+			return nil, pkgerrors.Wrap(errExprImport, e)
+		}
+	}
+
+	return imports, nil
+}
+
+// complete returns true if expressions are set for both directions, so field
+// is not converted according to its types.
+func (o exprOptions) complete() bool {
+	return o.toModel != "" && o.toPb != ""
+}
+
+// apply replaces conversions of field f with expressions.
+func (o exprOptions) apply(f *Field) {
+	if o.toModel != "" {
+		f.ProtoToGoExpr = o.toModel
+	}
+	if o.toPb != "" {
+		f.GoToProtoExpr = o.toPb
+	}
+	f.Default = o.defValue
+	f.Imports = append(f.Imports, o.imports...)
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Expressions", func() {
+	str := descriptor.FieldDescriptorProto_TYPE_STRING
+
+	// field returns string proto field with options from opts.
+	field := func(opts map[*proto.ExtensionDesc]interface{}) *descriptor.FieldDescriptorProto {
+		return newField("email", str, opts)
+	}
+
+	DescribeTable("extractExprOptions",
+		func(opts map[*proto.ExtensionDesc]interface{}, expected exprOptions) {
+			o, err := extractExprOptions(field(opts))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(o).To(Equal(expected))
+		},
+
+		Entry("Without options", nil, exprOptions{}),
+		Entry("Expressions", map[*proto.ExtensionDesc]interface{}{
+			options.E_ToModelExpr: "strings.ToLower(src.Email)",
+			options.E_ToPbExpr:    "src.Email",
+		}, exprOptions{toModel: "strings.ToLower(src.Email)", toPb: "src.Email"}),
+		Entry("Default value", map[*proto.ExtensionDesc]interface{}{
+			options.E_DefaultValue: `"USD"`,
+		}, exprOptions{defValue: `"USD"`}),
+		Entry("Imports", map[*proto.ExtensionDesc]interface{}{
+			options.E_ExprImports: "strings, str strings,",
+		}, exprOptions{imports: []string{`"strings"`, `str "strings"`}}),
+	)
+
+	DescribeTable("extractExprOptions returns an error",
+		func(opts map[*proto.ExtensionDesc]interface{}, expected string) {
+			_, err := extractExprOptions(field(opts))
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+
+		Entry("Invalid model expression", map[*proto.ExtensionDesc]interface{}{options.E_ToModelExpr: "src.Email +"}, `invalid Go expression "src.Email +"`),
+		Entry("Invalid proto expression", map[*proto.ExtensionDesc]interface{}{options.E_ToPbExpr: "a b"}, `invalid Go expression "a b"`),
+		Entry("Invalid default value", map[*proto.ExtensionDesc]interface{}{options.E_DefaultValue: "1 +"}, `invalid Go expression "1 +"`),
+		Entry("Invalid import", map[*proto.ExtensionDesc]interface{}{options.E_ExprImports: "a b c"}, errExprImport.Error()),
+	)
+
+	Describe("processField", func() {
+		gf := source.Structure{"Email": {Type: "Address"}, "Name": {Type: "string"}}
+
+		It("uses expressions instead of conversions of types", func() {
+			f, err := processField(&bytes.Buffer{}, field(map[*proto.ExtensionDesc]interface{}{
+				options.E_ToModelExpr: "mail.Parse(src.Email)",
+				options.E_ToPbExpr:    "src.Email.String()",
+				options.E_ExprImports: "mail net/mail",
+			}), nil, gf, fileInfo{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(formatField(*f, false, "model")).To(Equal("Email: mail.Parse(src.Email),"))
+			Expect(formatField(*f, true, "pb")).To(Equal("Email: src.Email.String(),"))
+			Expect(f.Imports).To(Equal([]string{`mail "net/mail"`}))
+		})
+
+		It("keeps conversion of the other direction", func() {
+			fdp := field(map[*proto.ExtensionDesc]interface{}{
+				options.E_ToModelExpr: "strings.ToLower(src.Name)",
+				options.E_MapTo:       "Name",
+			})
+
+			f, err := processField(&bytes.Buffer{}, fdp, nil, gf, fileInfo{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(formatField(*f, false, "model")).To(Equal("Name: strings.ToLower(src.Name),"))
+			Expect(formatField(*f, true, "pb")).To(Equal("Email: src.Name,"))
+		})
+
+		It("sets default value", func() {
+			f, err := processField(&bytes.Buffer{}, field(map[*proto.ExtensionDesc]interface{}{
+				options.E_DefaultValue: `"USD"`,
+				options.E_MapTo:        "Name",
+			}), nil, gf, fileInfo{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(f.Default).To(Equal(`"USD"`))
+			Expect(f.Presence).To(Equal(`%s != ""`))
+			Expect(formatDefaultField(*f, false)).To(Equal("\tif src.Email == \"\" {\n\t\ts.Name = \"USD\"\n\t}\n"))
+		})
+
+		It("returns an error for invalid expressions", func() {
+			_, err := processField(&bytes.Buffer{}, field(map[*proto.ExtensionDesc]interface{}{options.E_ToModelExpr: "("}), nil, gf, fileInfo{})
+			Expect(pkgerrors.Cause(err)).To(HaveOccurred())
+			Expect(err).To(MatchError(ContainSubstring(`Email: invalid Go expression "("`)))
+		})
+	})
+})
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	gogen "github.com/gogo/protobuf/protoc-gen-gogo/generator"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Import spec for package with GetExtension and SetExtension functions,
+// goimports could choose github.com/golang/protobuf/proto instead.
+const gogoProtoImport = `"github.com/gogo/protobuf/proto"`
+
+var (
+	errExtensionType  = errors.New("only singular scalar extensions are supported, message, enum and repeated extensions can not be mapped")
+	errExtensionScope = errors.New("only extensions of top level messages declared in the same file are supported")
+)
+
+// extension is an extension field declared in .proto file.
+type extension struct {
+	fdp *descriptor.FieldDescriptorProto
+	// Name of Go variable with extension descriptor, e.g. E_LegacyCode.
+	varName string
+}
+
+// fileExtensions returns extensions declared in file f on top level and inside
+// messages. Map key is a full name of extended message relative to package of
+// file f, i.e. message name for messages declared in file f. Names of
+// descriptor variables are built like protoc-gen-gogo does: each scope is
+// CamelCased separately and scopes are joined with "_".
+func fileExtensions(f *descriptor.FileDescriptorProto) map[string][]extension {
+	exts := map[string][]extension{}
+	prefix := "." + f.GetPackage() + "."
+
+	add := func(scope []string, fdps []*descriptor.FieldDescriptorProto) {
+		for _, fdp := range fdps {
+			name := strings.TrimPrefix(fdp.GetExtendee(), prefix)
+			exts[name] = append(exts[name], extension{
+				fdp:     fdp,
+				varName: "E_" + strings.Join(append(scope[:len(scope):len(scope)], gogen.CamelCase(fdp.GetName())), "_"),
+			})
+		}
+	}
+
+	add(nil, f.Extension)
+
+	var walk func(scope []string, msgs []*descriptor.DescriptorProto)
+	walk = func(scope []string, msgs []*descriptor.DescriptorProto) {
+		for _, m := range msgs {
+			s := append(scope[:len(scope):len(scope)], gogen.CamelCase(m.GetName()))
+			add(s, m.Extension)
+			walk(s, m.NestedType)
+		}
+	}
+
+	walk(nil, f.MessageType)
+
+	return exts
+}
+
+// checkExtensions returns error for extension with transformer.map_to option
+// which extends message declared in other file or nested message, such
+// extensions are never reached by processMessage.
+func checkExtensions(f *descriptor.FileDescriptorProto, exts map[string][]extension) error {
+	top := map[string]bool{}
+	for _, m := range f.MessageType {
+		top[m.GetName()] = true
+	}
+
+	names := make([]string, 0, len(exts))
+	for name := range exts {
+		if !top[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, ext := range exts[name] {
+			if _, err := getStringOption(ext.fdp.Options, options.E_MapTo); err == nil {
+				return pkgerrors.Wrapf(errExtensionScope, "%s extends %s", ext.fdp.GetName(), ext.fdp.GetExtendee())
+			}
+		}
+	}
+
+	return nil
+}
+
+// processExtension returns *Field for extension field which is bound to model
+// field with transformer.map_to option. Extensions without this option are
+// skipped.
+func processExtension(w io.Writer, ext extension, goStructFields source.Structure, fi fileInfo) (*Field, error) {
+	fdp := ext.fdp
+
+	if _, err := getStringOption(fdp.Options, options.E_MapTo); err != nil {
+		return nil, newLoggableError("extension skipped: %s", fdp.GetName())
+	}
+
+	pbType, ok := pbGoTypes[fdp.GetType()]
+	if !ok || fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return nil, pkgerrors.Wrap(errExtensionType, fdp.GetName())
+	}
+
+	// Extension values are pointers regardless of file syntax, except bytes,
+	// protoc-gen-gogofaster doesn't change extensions. Extension values of
+	// google.golang.org/protobuf are never pointers.
+	fi.proto2, fi.gogofaster, fi.editions = !fi.golang, false, false
+
+	f, err := processField(w, fdp, nil, goStructFields, fi)
+	if err != nil {
+		return nil, err
+	}
+
+	if pbType != "[]byte" && !fi.golang {
+		pbType = "*" + pbType
+	}
+
+	f.Extension = fi.protoPackage + "." + ext.varName
+	f.ExtensionType = pbType
+	if fi.golang {
+		f.Imports = append(f.Imports, golangProtoImport)
+	} else {
+		f.Imports = append(f.Imports, gogoProtoImport)
+	}
+
+	return f, nil
+}
+
+// formatExtensionField returns statements which copy value of extension field
+// from proto message or into it. Proto to Go conversion uses local variable
+// src which shadows source message and contains only extension value.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatExtensionField(f Field, swapped bool) string {
+	if f.Extension == "" {
+		return ""
+	}
+
+	report := fmt.Sprintf("reportError(opts, fmt.Errorf(\"%s: %%v\", err))", f.ProtoName)
+
+	if swapped {
+		return fmt.Sprintf("\tif v := %s; v != nil {\n\t\tif err := proto.SetExtension(&s, %s, v); err != nil {\n\t\t\t%s\n\t\t}\n\t}\n",
+			formatComplexField(f, true), f.Extension, report)
+	}
+
+	return fmt.Sprintf("\tif v, err := proto.GetExtension(&src, %s); err == nil {\n\t\tsrc := struct{ %s %s }{v.(%s)}\n\t\ts.%s = %s\n\t} else if err != proto.ErrMissingExtension {\n\t\t%s\n\t}\n",
+		f.Extension, f.ProtoName, f.ExtensionType, f.ExtensionType, f.Name, formatComplexField(f, false), report)
+}
@@ -0,0 +1,201 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Extension", func() {
+	var (
+		str = descriptor.FieldDescriptorProto_TYPE_STRING
+		i64 = descriptor.FieldDescriptorProto_TYPE_INT64
+		msg = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+		opt = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		rep = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	It("fileExtensions", func() {
+		legacy := &descriptor.FieldDescriptorProto{Name: sp("legacy_code"), Extendee: sp(".pb.Product")}
+		weight := &descriptor.FieldDescriptorProto{Name: sp("weight"), Extendee: sp(".pb.Product")}
+		other := &descriptor.FieldDescriptorProto{Name: sp("other"), Extendee: sp(".other.Message")}
+
+		exts := fileExtensions(&descriptor.FileDescriptorProto{
+			Package:   sp("pb"),
+			Extension: []*descriptor.FieldDescriptorProto{legacy, other},
+			MessageType: []*descriptor.DescriptorProto{{
+				Name: sp("holder"),
+				NestedType: []*descriptor.DescriptorProto{{
+					Name:      sp("Inner"),
+					Extension: []*descriptor.FieldDescriptorProto{weight},
+				}},
+			}},
+		})
+
+		Expect(exts).To(Equal(map[string][]extension{
+			"Product": {
+				{fdp: legacy, varName: "E_LegacyCode"},
+				{fdp: weight, varName: "E_Holder_Inner_Weight"},
+			},
+			".other.Message": {{fdp: other, varName: "E_Other"}},
+		}))
+	})
+
+	It("fileExtensions names descriptors like protoc-gen-gogo", func() {
+		legacy := &descriptor.FieldDescriptorProto{Name: sp("legacy_code_2"), Extendee: sp(".pb.Product")}
+		code := &descriptor.FieldDescriptorProto{Name: sp("code_3"), Extendee: sp(".pb.Product")}
+
+		exts := fileExtensions(&descriptor.FileDescriptorProto{
+			Package:   sp("pb"),
+			Extension: []*descriptor.FieldDescriptorProto{legacy},
+			MessageType: []*descriptor.DescriptorProto{{
+				Name: sp("holder"),
+				NestedType: []*descriptor.DescriptorProto{{
+					Name:      sp("order_2"),
+					Extension: []*descriptor.FieldDescriptorProto{code},
+				}},
+			}},
+		})
+
+		Expect(exts["Product"]).To(Equal([]extension{
+			{fdp: legacy, varName: "E_LegacyCode_2"},
+			{fdp: code, varName: "E_Holder_Order_2_Code_3"},
+		}))
+	})
+
+	Describe("checkExtensions", func() {
+		file := &descriptor.FileDescriptorProto{
+			Package:     sp("pb"),
+			MessageType: []*descriptor.DescriptorProto{{Name: sp("Product")}},
+		}
+
+		ext := func(name, extendee, mapTo string) extension {
+			fdp := &descriptor.FieldDescriptorProto{
+				Name:     sp(name),
+				Extendee: sp(extendee),
+				Options:  &descriptor.FieldOptions{},
+			}
+			if mapTo != "" {
+				err := proto.SetExtension(fdp.Options, options.E_MapTo, sp(mapTo))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			return extension{fdp: fdp}
+		}
+
+		It("accepts extensions of top level messages", func() {
+			err := checkExtensions(file, map[string][]extension{
+				"Product": {ext("legacy_code", ".pb.Product", "LegacyCode")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("ignores extensions without map_to option", func() {
+			err := checkExtensions(file, map[string][]extension{
+				".other.Message": {ext("other", ".other.Message", "")},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("returns error for extensions of other messages",
+			func(extendee string) {
+				err := checkExtensions(file, map[string][]extension{
+					strings.TrimPrefix(extendee, ".pb."): {ext("other", extendee, "Other")},
+				})
+				Expect(err).To(MatchError(pkgerrors.Wrapf(errExtensionScope, "other extends %s", extendee).Error()))
+			},
+
+			Entry("Other file", ".other.Message"),
+			Entry("Nested message", ".pb.Product.Inner"),
+		)
+	})
+
+	Describe("processExtension", func() {
+		fi := fileInfo{protoPackage: "pb"}
+
+		ext := func(typ *descriptor.FieldDescriptorProto_Type, label *descriptor.FieldDescriptorProto_Label, mapTo string) extension {
+			fdp := &descriptor.FieldDescriptorProto{
+				Name:    sp("legacy_code"),
+				Type:    typ,
+				Label:   label,
+				Options: &descriptor.FieldOptions{},
+			}
+			if mapTo != "" {
+				err := proto.SetExtension(fdp.Options, options.E_MapTo, sp(mapTo))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			return extension{fdp: fdp, varName: "E_LegacyCode"}
+		}
+
+		It("skips extension without map_to option", func() {
+			f, err := processExtension(nil, ext(&str, &opt, ""), goStruct, fi)
+			Expect(err).To(Equal(newLoggableError("extension skipped: legacy_code")))
+			Expect(f).To(BeNil())
+		})
+
+		DescribeTable("returns error for unsupported extensions",
+			func(typ descriptor.FieldDescriptorProto_Type, label descriptor.FieldDescriptorProto_Label) {
+				f, err := processExtension(nil, ext(&typ, &label, "StringField"), goStruct, fi)
+				Expect(err.Error()).To(Equal(pkgerrors.Wrap(errExtensionType, "legacy_code").Error()))
+				Expect(f).To(BeNil())
+			},
+
+			Entry("Message", msg, opt),
+			Entry("Repeated scalar", str, rep),
+		)
+
+		It("converts extension into model field", func() {
+			f, err := processExtension(nil, ext(&i64, &opt, "IntField"), goStruct, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Name).To(Equal("IntField"))
+			Expect(f.ProtoName).To(Equal("LegacyCode"))
+			Expect(f.Extension).To(Equal("pb.E_LegacyCode"))
+			Expect(f.ExtensionType).To(Equal("*int64"))
+			Expect(f.Imports).To(Equal([]string{gogoProtoImport}))
+			Expect(f.ProtoToGoExpr).To(HavePrefix("func(in *int64) int {"))
+		})
+	})
+
+	Describe("formatExtensionField", func() {
+		f := Field{
+			Name:          "Code",
+			ProtoName:     "LegacyCode",
+			ProtoToGoType: "StringPtrToString",
+			GoToProtoType: "StringToStringPtr",
+			Extension:     "pb.E_LegacyCode",
+			ExtensionType: "*string",
+		}
+
+		DescribeTable("check returns",
+			func(f Field, swapped bool, expected string) {
+				Expect(formatExtensionField(f, swapped)).To(Equal(expected))
+			},
+
+			Entry("Not an extension", Field{Name: "Code"}, false, ""),
+			Entry("Proto to Go", f, false, `	if v, err := proto.GetExtension(&src, pb.E_LegacyCode); err == nil {
+		src := struct{ LegacyCode *string }{v.(*string)}
+		s.Code =  StringPtrToString(src.LegacyCode )
+	} else if err != proto.ErrMissingExtension {
+		reportError(opts, fmt.Errorf("LegacyCode: %v", err))
+	}
+`),
+			Entry("Go to proto", f, true, `	if v :=  StringToStringPtr(src.Code ); v != nil {
+		if err := proto.SetExtension(&s, pb.E_LegacyCode, v); err != nil {
+			reportError(opts, fmt.Errorf("LegacyCode: %v", err))
+		}
+	}
+`),
+		)
+
+		It("excludes extension from composite literal", func() {
+			Expect(formatField(f, false, "pb")).To(BeEmpty())
+		})
+	})
+})
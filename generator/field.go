@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
+	"github.com/gogo/protobuf/gogoproto"
 	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	gogen "github.com/gogo/protobuf/protoc-gen-gogo/generator"
 	"github.com/iancoleman/strcase"
 	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
 	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
@@ -153,6 +156,12 @@ func processSubMessage(w io.Writer,
 		ProtoIsPointer: isNullable,
 	}
 
+	// Nested field mask paths are translated by sub message functions.
+	isRepeated := fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+	if mo != nil && !mo.Omitted() && !mo.Declared() && mo.FieldMask() && mo.OneofDecl() == "" && !customTransformer && !isRepeated {
+		f.MaskTarget = strcase.ToCamel(mo.Target())
+	}
+
 	if fm, ok := goStructFields[gname]; ok {
 		if mo == nil {
 			return nil, errors.New("mo is nil")
@@ -175,11 +184,23 @@ func processSubMessage(w io.Writer,
 }
 
 // processSimpleField processes fields of basic types such as int, string and
-// so on.
-func processSimpleField(w io.Writer, pname, gname string, ftype *descriptor.FieldDescriptorProto_Type, sf source.FieldInfo, fdp *descriptor.FieldDescriptorProto) (*Field, error) {
+// so on. Default model types of proto types are converted by casts, other
+// numeric model types are converted inline with range checks, see
+// fileScalarMap and numericConv.
+func processSimpleField(w io.Writer, pname, gname string, ftype *descriptor.FieldDescriptorProto_Type, sf source.FieldInfo, fdp *descriptor.FieldDescriptorProto, table map[descriptor.FieldDescriptorProto_Type]typeRel) (*Field, error) {
+
+	pbType := pbGoTypes[*ftype]
+	repeated := fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+	if isNumber(pbType) && isNumber(sf.Type) && sf.Type != pbType && !isDefaultPair(*ftype, sf.Type, table) &&
+		sf.IsSlice == repeated && !(sf.IsSlice && sf.IsPointer) {
+		return numericField(pname, gname, pbType, "0", sf, false), nil
+	}
 
 	sf.Type = strcase.ToCamel(strings.Replace(sf.Type, ".", "", -1)) // pkg.Type => PkgType
-	t := types[*ftype]
+	if table == nil {
+		table = types
+	}
+	t := table[*ftype]
 
 	sft := strings.ToLower(sf.Type)
 	tpb := strings.ToLower(t.pbType)
@@ -229,6 +250,7 @@ func processField(
 	fdp *descriptor.FieldDescriptorProto,
 	subMessages MessageOptionList,
 	goStructFields source.Structure,
+	fi fileInfo,
 ) (*Field, error) {
 	// If field has transformer.skip == true, it will be not processed.
 	if skip := extractSkipOption(fdp.Options); skip {
@@ -256,10 +278,30 @@ func processField(
 		return nil, pkgerrors.Wrap(err, "forceUseHelperPackage option")
 	}
 
+	// Names set by gogoproto options are used if map_as is not set.
+	if mapAs == "" {
+		mapAs = protoGoName(fdp)
+	}
+
 	pname, gname := prepareFieldNames(*fdp.Name, mapAs, mapTo)
 
 	// check if field exists in destination/Go structure.
 	gf, ok := goStructFields[gname]
+
+	// Fields with from_method option are read from methods of model.
+	method := fromMethod(fdp)
+	if method != "" {
+		if gf, ok = fi.methods[fi.model][method]; !ok {
+			return nil, pkgerrors.Wrap(errMethodNotFound, method)
+		}
+		gname = method + "()"
+	}
+
+	if !ok && mapTo == "" {
+		if n, jf, found := fieldByJSONName(goStructFields, fdp.GetJsonName()); found {
+			gname, gf, ok = n, jf, true
+		}
+	}
 	if !ok {
 		// do not check for embedded fields.
 		if isEmbed := extractEmbedOption(fdp.Options); !isEmbed {
@@ -282,27 +324,189 @@ func processField(
 	p(w, "// pname: %q, gname: %q, fdp: %+v\n", pname, gname, fdp)
 	p(w, "// gsf: %+v\n", goStructFields[gname])
 
-	// Process subMessages. For details see comments for the TypeName.
-	if typ := fdp.TypeName; *fdp.Type == descriptor.FieldDescriptorProto_TYPE_MESSAGE && typ != nil {
+	ex, err := extractExprOptions(fdp)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, gname)
+	}
+
+	var f *Field
+
+	// Fields with expressions for both directions are not converted according
+	// to their types. Repeated fields which are transformed into model maps and
+	// fields with Go types changed by gogoproto options are processed first.
+	if ex.complete() {
+		f = &Field{Name: gname, ProtoName: pname}
+	} else if isKeyedField(fdp, gf, fi) {
+		if f, err = keyedField(pname, gname, fdp, gf, subMessages, fi); err != nil {
+			return nil, pkgerrors.Wrap(err, gname)
+		}
+	} else if gt, isGogo := protoGoType(fdp, fi); isGogo {
+		if f, err = gogoTypedField(pname, gname, gt, gf); err != nil {
+			return nil, err
+		}
+	} else if typ := fdp.TypeName; *fdp.Type == descriptor.FieldDescriptorProto_TYPE_MESSAGE && typ != nil {
 		t := *typ
-		switch t {
-		case ".google.protobuf.Timestamp":
-			isNullable := extractNullOption(fdp)
-			return wktgoogleProtobufTimestamp(pname, gname, gf, isNullable), nil
-		case ".google.protobuf.StringValue":
-			return wktgoogleProtobufString(pname, gname, gf.Type), nil
+		// Repeated google.type fields are processed as other sub messages.
+		isRepeated := fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+
+		switch {
+		case !isRepeated && (t == ".google.protobuf.Timestamp" || wrapperTypes[t] != "") && isNullType(gf):
+			if f, ok = nullableMessageField(pname, gname, t, gf, fi.nullable(fdp)); !ok {
+				return nil, pkgerrors.Wrap(fmt.Errorf("%s can not be converted into %s", t[1:], gf.Type), gname)
+			}
+		case t == ".google.protobuf.Timestamp":
+			isNullable := fi.nullable(fdp)
+			to, err := extractTimeOptions(fdp.Options)
+			if err != nil {
+				return nil, pkgerrors.Wrap(err, gname)
+			}
+
+			var inline bool
+			if f, inline, err = wktgoogleProtobufTimestampInline(pname, gname, gf, isNullable, to); err != nil {
+				return nil, pkgerrors.Wrap(err, gname)
+			}
+
+			if !inline {
+				f = wktgoogleProtobufTimestamp(pname, gname, gf, isNullable)
+			}
+		case t == ".google.protobuf.StringValue":
+			f = wktgoogleProtobufString(pname, gname, gf.Type)
+		case !isRepeated && (t == ".google.type.Date" || t == ".google.type.TimeOfDay" || t == ".google.type.LatLng"):
+			validate := getBoolOption(fdp.Options, options.E_Validate)
+			f = wktgoogleType(pname, gname, googleTypes[t], gf, fi.nullable(fdp), validate, fi)
+		case !isRepeated && (t == ".google.type.Money" || t == ".google.type.Decimal"):
+			validate := getBoolOption(fdp.Options, options.E_Validate)
+			no, err := extractNumericOptions(fdp.Options)
+			if err != nil {
+				return nil, pkgerrors.Wrap(err, gname)
+			}
+
+			if f, err = wktgoogleTypeNumeric(pname, gname, t, gf, fi.nullable(fdp), validate, no); err != nil {
+				return nil, pkgerrors.Wrap(err, gname)
+			}
+		case isProtoMessageField(t, gf, fi):
+			pt, _ := protoMessageType(t, fi)
+			clone := getBoolOption(fdp.Options, options.E_Clone)
+			if f, err = protoMessageField(pname, gname, pt, gf, fi.nullable(fdp), clone, fi); err != nil {
+				return nil, pkgerrors.Wrap(err, gname)
+			}
+		default:
+			// if the field has the custom=true - the custom transformer will be used for this field
+			customTransformer := getBoolOption(fdp.Options, options.E_Custom)
+
+			// Submessage has a name like ".package.type", 1: removes first ".".
+			mo, _ := subMessages[t[1:]]
+			if !customTransformer && !forseAssignable && (mo == nil || mo.Omitted() && mo.OneofDecl() == "") {
+				return nil, errNoTransformer{path: fi.message + "." + fdp.GetName(), message: t[1:]}
+			}
+			// TODO(ekhabarov): pass gf instead of goStructFields
+			if f, err = processSubMessage(w, fdp, pname, gname, t, mo, goStructFields, customTransformer, forceUsePackage, forseAssignable); err != nil {
+				return nil, err
+			}
+			// Proto name is the same as generated by protoc-gen-gogo.
+			f.ProtoName = pname
+			// Sub messages of protoc-gen-go structures are always pointers.
+			f.ProtoIsPointer = f.ProtoIsPointer || fi.golang
+		}
+	} else if gt, ok := googleTypes[fdp.GetTypeName()]; ok && gt.enum && fdp.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		validate := getBoolOption(fdp.Options, options.E_Validate)
+		f = wktgoogleType(pname, gname, gt, gf, false, validate, fi)
+	} else if f, err = processScalarField(w, pname, gname, gf, fdp, fi); err != nil {
+		return nil, err
+	}
+
+	ex.apply(f)
+	f.Method = method != ""
+	if f.Default != "" {
+		if f.Presence, err = presenceCond(fdp, fi); err != nil {
+			return nil, pkgerrors.Wrap(err, gname)
+		}
+	}
+	f.ProtoPath = *fdp.Name
+	f.Column = gf.TagName("db")
+
+	// Required fields are checked only if they are pointers or slices. Other
+	// required scalars are rejected, e.g. scalars of gogofaster structures
+	// have no presence.
+	if fi.isRequired(fdp) {
+		switch fdp.GetType() {
+		case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+			f.Required = fi.nullable(fdp)
+		case descriptor.FieldDescriptorProto_TYPE_BYTES:
+			f.Required = true
+		default:
+			if f.Required = isPointerScalar(fdp, fi); !f.Required {
+				return nil, pkgerrors.Wrap(errRequiredValue, gname)
+			}
+		}
+	}
+
+	if e, ok := fi.closedEnums[fdp.GetTypeName()]; ok && fdp.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		f.ClosedEnum = fi.protoPackage + "." + e
+	}
+
+	return f, nil
+}
+
+// processScalarField processes fields of scalar types. String and bytes
+// fields are converted into UUID model types, string fields which contain
+// decimal numbers are converted into decimal model types, fields are
+// converted into nullable model types, e.g. sql.NullString, pointer fields of
+// proto2 files are converted inline if possible, other fields are processed
+// by processSimpleField.
+func processScalarField(w io.Writer, pname, gname string, gf source.FieldInfo, fdp *descriptor.FieldDescriptorProto, fi fileInfo) (*Field, error) {
+	isPointer := isPointerScalar(fdp, fi)
+
+	if isNullType(gf) {
+		f, ok := nullableScalarField(pname, gname, gf, fdp, isPointer)
+		if !ok {
+			return nil, pkgerrors.Wrap(fmt.Errorf("%s can not be converted into %s", strings.ToLower(fdp.GetType().String()[5:]), gf.Type), gname)
+		}
+
+		return f, nil
+	}
+
+	if isPointer {
+		f, ok, err := proto2Field(pname, gname, gf, fdp, fi.types)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, gname)
+		}
+
+		if ok {
+			return f, nil
+		}
+
+		if f, err = processSimpleField(w, pname, gname, fdp.Type, gf, fdp, fi.types); err != nil {
+			return nil, err
+		}
+
+		f.ProtoIsPointer = true
+		f.GoIsPointer = gf.IsPointer
+
+		return f, nil
+	}
+
+	if f, ok := uuidField(pname, gname, gf, fdp, getBoolOption(fdp.Options, options.E_ZeroOnError)); ok {
+		return f, nil
+	}
+
+	if fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_STRING && fdp.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		no, err := extractNumericOptions(fdp.Options)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, gname)
 		}
 
-		// if the field has the custom=true - the custom transformer will be used for this field
-		customTransformer := getBoolOption(fdp.Options, options.E_Custom)
+		f, ok, err := stringDecimalField(pname, gname, gf, no)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, gname)
+		}
 
-		// Submessage has a name like ".package.type", 1: removes first ".".
-		mo, _ := subMessages[t[1:]]
-		// TODO(ekhabarov): pass gf instead of goStructFields
-		return processSubMessage(w, fdp, pname, gname, t, mo, goStructFields, customTransformer, forceUsePackage, forseAssignable)
+		if ok {
+			return f, nil
+		}
 	}
 
-	return processSimpleField(w, pname, gname, fdp.Type, gf, fdp)
+	return processSimpleField(w, pname, gname, fdp.Type, gf, fdp, fi.types)
 }
 
 // abbreviationUpper checks a incoming string for equality and suffixes, if it
@@ -328,24 +532,65 @@ func abbreviationUpper(name string) string {
 }
 
 // prepareFieldNames returns names Protobuf  and Go for field, considering
-// map_to/map_as options and abbreviation rules.
+// map_to/map_as options and abbreviation rules. Protobuf name is a name of
+// field generated by protoc-gen-gogo, e.g. "MapField_1" for map_field_1, while
+// Go name is converted without such exceptions, e.g. "MapField1".
 func prepareFieldNames(fname, mapAs, mapTo string) (string, string) {
-	pname := fname
+	pname := gogen.CamelCase(fname)
+	gname := fname
 
-	if strings.Contains(pname, "_") {
-		pname = strcase.ToCamel(pname)
+	if strings.Contains(gname, "_") {
+		gname = strcase.ToCamel(gname)
 	} else {
-		pname = strings.Title(pname)
+		gname = strings.Title(gname)
 	}
 
 	if mapAs != "" {
-		pname = mapAs
+		pname, gname = mapAs, mapAs
 	}
 
-	gname := abbreviationUpper(pname)
+	gname = abbreviationUpper(gname)
 	if mapTo != "" {
 		gname = mapTo
 	}
 
 	return pname, gname
 }
+
+// protoGoName returns Go name of proto field which is set by
+// gogoproto.customname option or name of embedded message type for fields
+// with gogoproto.embed option. Empty string is returned for other fields.
+func protoGoName(fdp *descriptor.FieldDescriptorProto) string {
+	if gogoproto.IsCustomName(fdp) {
+		return gogoproto.GetCustomName(fdp)
+	}
+
+	if gogoproto.IsEmbed(fdp) && fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		// Only top-level messages are collected as sub messages.
+		return gogen.CamelCase(lastName(fdp.GetTypeName()))
+	}
+
+	return ""
+}
+
+// fieldByJSONName returns name and info of model field which json tag equals
+// to json_name of proto field. Fields are checked in alphabetical order.
+func fieldByJSONName(goStructFields source.Structure, jsonName string) (string, source.FieldInfo, bool) {
+	if jsonName == "" {
+		return "", source.FieldInfo{}, false
+	}
+
+	names := make([]string, 0, len(goStructFields))
+	for n := range goStructFields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		if gf := goStructFields[n]; gf.TagName("json") == jsonName {
+			return n, gf, true
+		}
+	}
+
+	return "", source.FieldInfo{}, false
+}
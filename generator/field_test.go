@@ -3,6 +3,7 @@ package generator
 import (
 	"errors"
 
+	"github.com/gogo/protobuf/gogoproto"
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
 	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
@@ -56,12 +57,39 @@ var _ = Describe("Field", func() {
 							"ProtoName":      Equal(expected.ProtoName),
 							"ProtoToGoType":  Equal(expected.ProtoToGoType),
 							"GoToProtoType":  Equal(expected.GoToProtoType),
+							"ProtoToGoExpr":  Equal(expected.ProtoToGoExpr),
+							"GoToProtoExpr":  Equal(expected.GoToProtoExpr),
+							"Imports":        Equal(expected.Imports),
 							"ProtoType":      Equal(expected.ProtoType),
 							"GoIsPointer":    Equal(expected.GoIsPointer),
 							"ProtoIsPointer": Equal(expected.ProtoIsPointer),
 							"UsePackage":     Equal(expected.UsePackage),
 							"OneofDecl":      Equal(expected.OneofDecl),
 							"Opts":           Equal(expected.Opts),
+							"ProtoPath":      Equal(expected.ProtoPath),
+							"Column":         Equal(expected.Column),
+							"MaskTarget":     Equal(expected.MaskTarget),
+							"Required":       Equal(expected.Required),
+							"Extension":      Equal(expected.Extension),
+							"ExtensionType":  Equal(expected.ExtensionType),
+							"ClosedEnum":     Equal(expected.ClosedEnum),
+							"Getter":         Equal(expected.Getter),
+							"OneofField":     Equal(expected.OneofField),
+							"OneofWrapper":   Equal(expected.OneofWrapper),
+							"OneofCond":      Equal(expected.OneofCond),
+							"Unknown":        Equal(expected.Unknown),
+							"Aliases":        Equal(expected.Aliases),
+							"Presence":       Equal(expected.Presence),
+							"WriteAlias":     Equal(expected.WriteAlias),
+							"Direction":      Equal(expected.Direction),
+							"Flatten":        Equal(expected.Flatten),
+							"FlattenEmpty":   Equal(expected.FlattenEmpty),
+							"ModelPresence":  Equal(expected.ModelPresence),
+							"Nested":         Equal(expected.Nested),
+							"NestedType":     Equal(expected.NestedType),
+							"Default":        Equal(expected.Default),
+							"Method":         Equal(expected.Method),
+							"Keyed":          Equal(expected.Keyed),
 						}))
 					},
 
@@ -104,12 +132,39 @@ var _ = Describe("Field", func() {
 							"ProtoName":      Equal(expected.ProtoName),
 							"ProtoToGoType":  Equal(expected.ProtoToGoType),
 							"GoToProtoType":  Equal(expected.GoToProtoType),
+							"ProtoToGoExpr":  Equal(expected.ProtoToGoExpr),
+							"GoToProtoExpr":  Equal(expected.GoToProtoExpr),
+							"Imports":        Equal(expected.Imports),
 							"ProtoType":      Equal(expected.ProtoType),
 							"GoIsPointer":    Equal(expected.GoIsPointer),
 							"ProtoIsPointer": Equal(expected.ProtoIsPointer),
 							"UsePackage":     Equal(expected.UsePackage),
 							"OneofDecl":      Equal(expected.OneofDecl),
 							"Opts":           Equal(expected.Opts),
+							"ProtoPath":      Equal(expected.ProtoPath),
+							"Column":         Equal(expected.Column),
+							"MaskTarget":     Equal(expected.MaskTarget),
+							"Required":       Equal(expected.Required),
+							"Extension":      Equal(expected.Extension),
+							"ExtensionType":  Equal(expected.ExtensionType),
+							"ClosedEnum":     Equal(expected.ClosedEnum),
+							"Getter":         Equal(expected.Getter),
+							"OneofField":     Equal(expected.OneofField),
+							"OneofWrapper":   Equal(expected.OneofWrapper),
+							"OneofCond":      Equal(expected.OneofCond),
+							"Unknown":        Equal(expected.Unknown),
+							"Aliases":        Equal(expected.Aliases),
+							"Presence":       Equal(expected.Presence),
+							"WriteAlias":     Equal(expected.WriteAlias),
+							"Direction":      Equal(expected.Direction),
+							"Flatten":        Equal(expected.Flatten),
+							"FlattenEmpty":   Equal(expected.FlattenEmpty),
+							"ModelPresence":  Equal(expected.ModelPresence),
+							"Nested":         Equal(expected.Nested),
+							"NestedType":     Equal(expected.NestedType),
+							"Default":        Equal(expected.Default),
+							"Method":         Equal(expected.Method),
+							"Keyed":          Equal(expected.Keyed),
 						}))
 					},
 
@@ -158,12 +213,39 @@ var _ = Describe("Field", func() {
 					"ProtoName":      Equal(expected.ProtoName),
 					"ProtoToGoType":  Equal(expected.ProtoToGoType),
 					"GoToProtoType":  Equal(expected.GoToProtoType),
+					"ProtoToGoExpr":  Equal(expected.ProtoToGoExpr),
+					"GoToProtoExpr":  Equal(expected.GoToProtoExpr),
+					"Imports":        Equal(expected.Imports),
 					"ProtoType":      Equal(expected.ProtoType),
 					"GoIsPointer":    Equal(expected.GoIsPointer),
 					"ProtoIsPointer": Equal(expected.ProtoIsPointer),
 					"UsePackage":     Equal(expected.UsePackage),
 					"OneofDecl":      Equal(expected.OneofDecl),
 					"Opts":           Equal(expected.Opts),
+					"ProtoPath":      Equal(expected.ProtoPath),
+					"Column":         Equal(expected.Column),
+					"MaskTarget":     Equal(expected.MaskTarget),
+					"Required":       Equal(expected.Required),
+					"Extension":      Equal(expected.Extension),
+					"ExtensionType":  Equal(expected.ExtensionType),
+					"ClosedEnum":     Equal(expected.ClosedEnum),
+					"Getter":         Equal(expected.Getter),
+					"OneofField":     Equal(expected.OneofField),
+					"OneofWrapper":   Equal(expected.OneofWrapper),
+					"OneofCond":      Equal(expected.OneofCond),
+					"Unknown":        Equal(expected.Unknown),
+					"Aliases":        Equal(expected.Aliases),
+					"Presence":       Equal(expected.Presence),
+					"WriteAlias":     Equal(expected.WriteAlias),
+					"Direction":      Equal(expected.Direction),
+					"Flatten":        Equal(expected.Flatten),
+					"FlattenEmpty":   Equal(expected.FlattenEmpty),
+					"ModelPresence":  Equal(expected.ModelPresence),
+					"Nested":         Equal(expected.Nested),
+					"NestedType":     Equal(expected.NestedType),
+					"Default":        Equal(expected.Default),
+					"Method":         Equal(expected.Method),
+					"Keyed":          Equal(expected.Keyed),
 				}))
 			},
 
@@ -256,6 +338,44 @@ var _ = Describe("Field", func() {
 					Opts:           ", opts...",
 				}),
 
+			Entry("Sub message with field mask functions",
+				&descriptor.FieldDescriptorProto{
+					Name: &protoField,
+				},
+				protoField, goField, "string", moFieldMask, false,
+				&Field{
+					Name:           "StringField",
+					ProtoName:      "ProtoField",
+					ProtoType:      "Pb",
+					ProtoToGoType:  "PbToMaskTarget",
+					GoToProtoType:  "MaskTargetToPb",
+					GoIsPointer:    false,
+					ProtoIsPointer: true,
+					UsePackage:     false,
+					OneofDecl:      "",
+					Opts:           ", opts...",
+					MaskTarget:     "MaskTarget",
+				}),
+
+			Entry("Repeated sub message with field mask functions",
+				&descriptor.FieldDescriptorProto{
+					Name:  &protoField,
+					Label: &labelRepeated,
+				},
+				protoField, goField, "string", moFieldMask, false,
+				&Field{
+					Name:           "StringField",
+					ProtoName:      "ProtoField",
+					ProtoType:      "Pb",
+					ProtoToGoType:  "PbToStringList",
+					GoToProtoType:  "StringToPbList",
+					GoIsPointer:    false,
+					ProtoIsPointer: true,
+					UsePackage:     false,
+					OneofDecl:      "",
+					Opts:           ", opts...",
+				}),
+
 			Entry("Repeated field when name field found in target struct.",
 				&descriptor.FieldDescriptorProto{
 					Name:  &protoField,
@@ -287,7 +407,7 @@ var _ = Describe("Field", func() {
 
 		DescribeTable("check result",
 			func(pname, gname string, ftype *descriptor.FieldDescriptorProto_Type, sf source.FieldInfo, expected *Field, fdp *descriptor.FieldDescriptorProto) {
-				got, err := processSimpleField(nil, pname, gname, ftype, sf, fdp)
+				got, err := processSimpleField(nil, pname, gname, ftype, sf, fdp, nil)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(*got).To(MatchAllFields(Fields{
@@ -295,12 +415,39 @@ var _ = Describe("Field", func() {
 					"ProtoName":      Equal(expected.ProtoName),
 					"ProtoToGoType":  Equal(expected.ProtoToGoType),
 					"GoToProtoType":  Equal(expected.GoToProtoType),
+					"ProtoToGoExpr":  Equal(expected.ProtoToGoExpr),
+					"GoToProtoExpr":  Equal(expected.GoToProtoExpr),
+					"Imports":        Equal(expected.Imports),
 					"ProtoType":      Equal(expected.ProtoType),
 					"GoIsPointer":    Equal(expected.GoIsPointer),
 					"ProtoIsPointer": Equal(expected.ProtoIsPointer),
 					"UsePackage":     Equal(expected.UsePackage),
 					"OneofDecl":      Equal(expected.OneofDecl),
 					"Opts":           Equal(expected.Opts),
+					"ProtoPath":      Equal(expected.ProtoPath),
+					"Column":         Equal(expected.Column),
+					"MaskTarget":     Equal(expected.MaskTarget),
+					"Required":       Equal(expected.Required),
+					"Extension":      Equal(expected.Extension),
+					"ExtensionType":  Equal(expected.ExtensionType),
+					"ClosedEnum":     Equal(expected.ClosedEnum),
+					"Getter":         Equal(expected.Getter),
+					"OneofField":     Equal(expected.OneofField),
+					"OneofWrapper":   Equal(expected.OneofWrapper),
+					"OneofCond":      Equal(expected.OneofCond),
+					"Unknown":        Equal(expected.Unknown),
+					"Aliases":        Equal(expected.Aliases),
+					"Presence":       Equal(expected.Presence),
+					"WriteAlias":     Equal(expected.WriteAlias),
+					"Direction":      Equal(expected.Direction),
+					"Flatten":        Equal(expected.Flatten),
+					"FlattenEmpty":   Equal(expected.FlattenEmpty),
+					"ModelPresence":  Equal(expected.ModelPresence),
+					"Nested":         Equal(expected.Nested),
+					"NestedType":     Equal(expected.NestedType),
+					"Default":        Equal(expected.Default),
+					"Method":         Equal(expected.Method),
+					"Keyed":          Equal(expected.Keyed),
 				}))
 
 			},
@@ -408,10 +555,57 @@ var _ = Describe("Field", func() {
 			Entry("MapAs without mapTo", "proto_field_name", "map_as", "", "map_as", "map_as"),
 			Entry("MapTo without mapAs", "proto_field_name", "", "map_to", "ProtoFieldName", "map_to"),
 			Entry("MapTo and mapAs", "proto_field_name", "map_as", "map_to", "map_as", "map_to"),
+			Entry("Field with digit", "map_field_1", "", "", "MapField_1", "MapField1"),
 		)
 
 	})
 
+	Describe("protoGoName", func() {
+		fdp := func(typ descriptor.FieldDescriptorProto_Type, ext *proto.ExtensionDesc, v interface{}) *descriptor.FieldDescriptorProto {
+			f := &descriptor.FieldDescriptorProto{
+				Name:     sp("product_id"),
+				Type:     &typ,
+				TypeName: sp(".pb.Address"),
+				Options:  &descriptor.FieldOptions{},
+			}
+			if ext != nil {
+				Expect(proto.SetExtension(f.Options, ext, v)).To(Succeed())
+			}
+
+			return f
+		}
+
+		It("returns custom name", func() {
+			Expect(protoGoName(fdp(typString, gogoproto.E_Customname, sp("ProductID")))).To(Equal("ProductID"))
+		})
+
+		It("returns type name of embedded message", func() {
+			Expect(protoGoName(fdp(typMessage, gogoproto.E_Embed, bp(true)))).To(Equal("Address"))
+		})
+
+		It("returns empty string for other fields", func() {
+			Expect(protoGoName(fdp(typString, nil, nil))).To(BeEmpty())
+			Expect(protoGoName(fdp(typString, gogoproto.E_Embed, bp(true)))).To(BeEmpty())
+		})
+	})
+
+	DescribeTable("fieldByJSONName",
+		func(jsonName, expected string, found bool) {
+			s := source.Structure{
+				"ProductID": {Type: "int64", Tag: `json:"productId,omitempty"`},
+				"Title":     {Type: "string", Tag: `json:"-"`},
+			}
+
+			name, _, ok := fieldByJSONName(s, jsonName)
+			Expect(ok).To(Equal(found))
+			Expect(name).To(Equal(expected))
+		},
+
+		Entry("Tag found", "productId", "ProductID", true),
+		Entry("Tag not found", "title", "", false),
+		Entry("Empty json_name", "", "", false),
+	)
+
 	Describe("processField", func() {
 
 		DescribeTable("check result",
@@ -423,7 +617,7 @@ var _ = Describe("Field", func() {
 				err = proto.SetExtension(f.Options, options.E_Embed, bp(embed))
 				Expect(err).NotTo(HaveOccurred())
 
-				field, err := processField(nil, f, subm, goStruct)
+				field, err := processField(nil, f, subm, goStruct, fileInfo{})
 				if expectedErr == nil {
 					Expect(err).NotTo(HaveOccurred())
 				} else {
@@ -437,12 +631,39 @@ var _ = Describe("Field", func() {
 						"ProtoName":      Equal(expected.ProtoName),
 						"ProtoToGoType":  Equal(expected.ProtoToGoType),
 						"GoToProtoType":  Equal(expected.GoToProtoType),
+						"ProtoToGoExpr":  Equal(expected.ProtoToGoExpr),
+						"GoToProtoExpr":  Equal(expected.GoToProtoExpr),
+						"Imports":        Equal(expected.Imports),
 						"ProtoType":      Equal(expected.ProtoType),
 						"GoIsPointer":    Equal(expected.GoIsPointer),
 						"ProtoIsPointer": Equal(expected.ProtoIsPointer),
 						"UsePackage":     Equal(expected.UsePackage),
 						"OneofDecl":      Equal(expected.OneofDecl),
 						"Opts":           Equal(expected.Opts),
+						"ProtoPath":      Equal(expected.ProtoPath),
+						"Column":         Equal(expected.Column),
+						"MaskTarget":     Equal(expected.MaskTarget),
+						"Required":       Equal(expected.Required),
+						"Extension":      Equal(expected.Extension),
+						"ExtensionType":  Equal(expected.ExtensionType),
+						"ClosedEnum":     Equal(expected.ClosedEnum),
+						"Getter":         Equal(expected.Getter),
+						"OneofField":     Equal(expected.OneofField),
+						"OneofWrapper":   Equal(expected.OneofWrapper),
+						"OneofCond":      Equal(expected.OneofCond),
+						"Unknown":        Equal(expected.Unknown),
+						"Aliases":        Equal(expected.Aliases),
+						"Presence":       Equal(expected.Presence),
+						"WriteAlias":     Equal(expected.WriteAlias),
+						"Direction":      Equal(expected.Direction),
+						"Flatten":        Equal(expected.Flatten),
+						"FlattenEmpty":   Equal(expected.FlattenEmpty),
+						"ModelPresence":  Equal(expected.ModelPresence),
+						"Nested":         Equal(expected.Nested),
+						"NestedType":     Equal(expected.NestedType),
+						"Default":        Equal(expected.Default),
+						"Method":         Equal(expected.Method),
+						"Keyed":          Equal(expected.Keyed),
 					}))
 				}
 			},
@@ -463,6 +684,7 @@ var _ = Describe("Field", func() {
 				UsePackage:     false,
 				OneofDecl:      "",
 				Opts:           "",
+				ProtoPath:      "int64_field",
 			}, nil),
 
 			Entry("int64: capitalized ID", &descriptor.FieldDescriptorProto{
@@ -481,6 +703,7 @@ var _ = Describe("Field", func() {
 				UsePackage:     false,
 				OneofDecl:      "",
 				Opts:           "",
+				ProtoPath:      "ID",
 			}, nil),
 
 			Entry("int64: id", &descriptor.FieldDescriptorProto{
@@ -499,6 +722,7 @@ var _ = Describe("Field", func() {
 				UsePackage:     false,
 				OneofDecl:      "",
 				Opts:           "",
+				ProtoPath:      "id",
 			}, nil),
 
 			Entry("Skip", &descriptor.FieldDescriptorProto{
@@ -531,6 +755,27 @@ var _ = Describe("Field", func() {
 				UsePackage:     false,
 				OneofDecl:      "",
 				Opts:           ", opts...",
+				ProtoPath:      "PkgTypeField",
+			}, nil),
+
+			Entry("Field with db tag", &descriptor.FieldDescriptorProto{
+				Name:     sp("column_field"),
+				TypeName: sp("string"),
+				Type:     &typString,
+				Options:  &descriptor.FieldOptions{},
+			}, false, false, &Field{
+				Name:           "ColumnField",
+				ProtoName:      "ColumnField",
+				ProtoType:      "",
+				ProtoToGoType:  "",
+				GoToProtoType:  "",
+				GoIsPointer:    false,
+				ProtoIsPointer: false,
+				UsePackage:     false,
+				OneofDecl:      "",
+				Opts:           "",
+				ProtoPath:      "column_field",
+				Column:         "column_name",
 			}, nil),
 
 			Entry("WKT: Timestamp", &descriptor.FieldDescriptorProto{
@@ -549,6 +794,7 @@ var _ = Describe("Field", func() {
 				UsePackage:     true,
 				OneofDecl:      "",
 				Opts:           "",
+				ProtoPath:      "time_field",
 			}, nil),
 
 			Entry("WKT: StringValue", &descriptor.FieldDescriptorProto{
@@ -567,9 +813,92 @@ var _ = Describe("Field", func() {
 				UsePackage:     true,
 				OneofDecl:      "",
 				Opts:           "",
+				ProtoPath:      "string_field",
+			}, nil),
+
+			Entry("UUID", &descriptor.FieldDescriptorProto{
+				Name:     sp("UUID_field"),
+				TypeName: sp("string"),
+				Type:     &typString,
+				Options:  &descriptor.FieldOptions{},
+			}, false, false, &Field{
+				Name:          "UUIDField",
+				ProtoName:     "UUIDField",
+				ProtoToGoExpr: "func(in string) uuid.UUID {\n\tv := in\n\tif v == \"\" {\n\t\treturn uuid.Nil\n\t}\n\tu, err := uuid.Parse(v)\n\tif err != nil {\n\t\treportError(opts, fmt.Errorf(\"UUIDField: %v\", err))\n\t\treturn uuid.Nil\n\t}\n\treturn u\n}(src.UUIDField)",
+				GoToProtoExpr: "func(in uuid.UUID) string {\n\tv := in\n\tif v == uuid.Nil {\n\t\treturn \"\"\n\t}\n\treturn v.String()\n}(src.UUIDField)",
+				Imports:       []string{`"github.com/google/uuid"`},
+				ProtoPath:     "UUID_field",
+			}, nil),
+
+			Entry("sql.NullTime", &descriptor.FieldDescriptorProto{
+				Name:     sp("null_time_field"),
+				TypeName: sp(".google.protobuf.Timestamp"),
+				Type:     &typMessage,
+				Options:  &descriptor.FieldOptions{},
+			}, false, false, &Field{
+				Name:          "NullTimeField",
+				ProtoName:     "NullTimeField",
+				ProtoToGoExpr: "func(in *types.Timestamp) sql.NullTime {\n\tif in == nil {\n\t\treturn sql.NullTime{}\n\t}\n\tv := in\n\treturn sql.NullTime{Time: time.Unix(v.Seconds, int64(v.Nanos)).UTC(), Valid: true}\n}(src.NullTimeField)",
+				GoToProtoExpr: "func(in sql.NullTime) *types.Timestamp {\n\tv := in\n\tif !v.Valid {\n\t\treturn nil\n\t}\n\treturn &types.Timestamp{Seconds: v.Time.Unix(), Nanos: int32(v.Time.Nanosecond())}\n}(src.NullTimeField)",
+				Imports:       []string{gogoTypesImport, `"database/sql"`},
+				ProtoPath:     "null_time_field",
+			}, nil),
+
+			Entry("sql.NullTime from string", &descriptor.FieldDescriptorProto{
+				Name:     sp("null_time_field"),
+				TypeName: sp("string"),
+				Type:     &typString,
+				Options:  &descriptor.FieldOptions{},
+			}, false, false, nil, errors.New("NullTimeField: string can not be converted into sql.NullTime")),
+
+			Entry("google.type.Money without currency", &descriptor.FieldDescriptorProto{
+				Name:     sp("int64_field"),
+				TypeName: sp(".google.type.Money"),
+				Type:     &typMessage,
+				Options:  &descriptor.FieldOptions{},
+			}, false, false, nil, pkgerrors.Wrap(errNoCurrency, "Int64Field")),
+
+			Entry("google.type.Month", &descriptor.FieldDescriptorProto{
+				Name:     sp("int_field"),
+				TypeName: sp(".google.type.Month"),
+				Type:     &typEnum,
+				Options:  &descriptor.FieldOptions{},
+			}, false, false, &Field{
+				Name:          "IntField",
+				ProtoName:     "IntField",
+				ProtoToGoExpr: "func(in month.Month) int {\n\tv := in\n\treturn int(v)\n}(src.IntField)",
+				GoToProtoExpr: "func(in int) month.Month {\n\tv := in\n\treturn month.Month(v)\n}(src.IntField)",
+				ProtoPath:     "int_field",
 			}, nil),
 		)
 
+		It("uses gogoproto.customname", func() {
+			fdp := &descriptor.FieldDescriptorProto{
+				Name:    sp("string_id"),
+				Type:    &typString,
+				Options: &descriptor.FieldOptions{},
+			}
+			Expect(proto.SetExtension(fdp.Options, gogoproto.E_Customname, sp("StringField"))).To(Succeed())
+
+			field, err := processField(nil, fdp, subm, goStruct, fileInfo{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(field.Name).To(Equal("StringField"))
+			Expect(field.ProtoName).To(Equal("StringField"))
+		})
+
+		It("finds model field by json_name", func() {
+			fdp := &descriptor.FieldDescriptorProto{
+				Name:     sp("db_column"),
+				JsonName: sp("columnJson"),
+				Type:     &typString,
+			}
+			s := source.Structure{"Column": {Type: "string", Tag: `json:"columnJson"`}}
+
+			field, err := processField(nil, fdp, subm, s, fileInfo{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(field.Name).To(Equal("Column"))
+			Expect(field.ProtoName).To(Equal("DbColumn"))
+		})
 	})
 
 })
@@ -0,0 +1,259 @@
+package generator
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+var (
+	fieldMaskPathT = mt("fieldMaskPath", `// PbTo{{ .Model }}Path translates google.protobuf.FieldMask path of {{ .Message }}
+// message into path of {{ .Model }} fields.
+func PbTo{{ .Model }}Path(path string) (string, error) {
+	parts := strings.SplitN(path, ".", 2)
+
+	switch parts[0] {
+	{{- range .Fields }}
+	case "{{ .ProtoPath }}":
+		if len(parts) == 1 {
+			return "{{ .Name }}", nil
+		}
+		{{- if .MaskTarget }}
+
+		if p, err := PbTo{{ .MaskTarget }}Path(parts[1]); err == nil {
+			return "{{ .Name }}." + p, nil
+		}
+		{{- end }}
+	{{- end }}
+	}
+
+	return "", fmt.Errorf("unknown field mask path %q", path)
+}
+
+// PbTo{{ .Model }}Paths translates list of google.protobuf.FieldMask paths of
+// {{ .Message }} message into paths of {{ .Model }} fields.
+func PbTo{{ .Model }}Paths(paths []string) ([]string, error) {
+	resp := make([]string, len(paths))
+
+	for i, p := range paths {
+		mp, err := PbTo{{ .Model }}Path(p)
+		if err != nil {
+			return nil, err
+		}
+		resp[i] = mp
+	}
+
+	return resp, nil
+}`)
+
+	fieldMaskColumnT = mt("fieldMaskColumn", `// PbTo{{ .Model }}Column translates google.protobuf.FieldMask path of {{ .Message }}
+// message into column name taken from db tag of {{ .Model }} field.
+func PbTo{{ .Model }}Column(path string) (string, error) {
+	parts := strings.SplitN(path, ".", 2)
+
+	switch parts[0] {
+	{{- range .Fields }}
+	case "{{ .ProtoPath }}":
+		{{- if .Column }}
+		if len(parts) == 1 {
+			return "{{ .Column }}", nil
+		}
+		{{- end }}
+		{{- if .MaskTarget }}
+
+		if len(parts) == 2 {
+			if c, err := PbTo{{ .MaskTarget }}Column(parts[1]); err == nil {
+				return {{ if .Column }}"{{ .Column }}." + {{ end }}c, nil
+			}
+		}
+		{{- end }}
+	{{- end }}
+	}
+
+	return "", fmt.Errorf("field mask path %q has no column", path)
+}
+
+// PbTo{{ .Model }}Columns translates list of google.protobuf.FieldMask paths of
+// {{ .Message }} message into column names of {{ .Model }}.
+func PbTo{{ .Model }}Columns(paths []string) ([]string, error) {
+	resp := make([]string, len(paths))
+
+	for i, p := range paths {
+		c, err := PbTo{{ .Model }}Column(p)
+		if err != nil {
+			return nil, err
+		}
+		resp[i] = c
+	}
+
+	return resp, nil
+}`)
+
+	fieldMaskReverseT = mt("fieldMaskReverse", `// {{ .Model }}ToPbPath translates path of {{ .Model }} fields into
+// google.protobuf.FieldMask path of {{ .Message }} message.
+func {{ .Model }}ToPbPath(path string) (string, error) {
+	{{- range .NestedFields }}
+	if path == "{{ .Name }}" {
+		return "{{ .ProtoPath }}", nil
+	}
+	{{- if .MaskTarget }}
+
+	if p := strings.TrimPrefix(path, "{{ .Name }}."); p != path {
+		if p, err := {{ .MaskTarget }}ToPbPath(p); err == nil {
+			return "{{ .ProtoPath }}." + p, nil
+		}
+	}
+	{{- end }}
+{{ end }}
+	parts := strings.SplitN(path, ".", 2)
+
+	switch parts[0] {
+	{{- range .ModelFields }}
+	case "{{ .Name }}":
+		if len(parts) == 1 {
+			return "{{ .ProtoPath }}", nil
+		}
+		{{- if .MaskTarget }}
+
+		if p, err := {{ .MaskTarget }}ToPbPath(parts[1]); err == nil {
+			return "{{ .ProtoPath }}." + p, nil
+		}
+		{{- end }}
+	{{- end }}
+	}
+
+	return "", fmt.Errorf("unknown model field path %q", path)
+}
+
+// {{ .Model }}ToPbPaths translates list of {{ .Model }} field paths into
+// google.protobuf.FieldMask paths of {{ .Message }} message.
+func {{ .Model }}ToPbPaths(paths []string) ([]string, error) {
+	resp := make([]string, len(paths))
+
+	for i, p := range paths {
+		pp, err := {{ .Model }}ToPbPath(p)
+		if err != nil {
+			return nil, err
+		}
+		resp[i] = pp
+	}
+
+	return resp, nil
+}`)
+
+	// Executed with FieldMaskData struct.
+	fieldMaskT = `
+{{ template "fieldMaskPath" . }}
+
+{{ template "fieldMaskColumn" . }}
+
+{{ template "fieldMaskReverse" . }}
+
+`
+)
+
+// FieldMaskData contains data for field mask functions template.
+type FieldMaskData struct {
+	// Proto message name with package prefix, is used in comments.
+	Message string
+	// Model structure name, is used as a part of function names.
+	Model string
+	// Fields which are translated from proto paths, including aliases.
+	Fields []Field
+	// Fields which are translated from model paths, aliases and fields which
+	// share model field with other fields, e.g. by expressions, are not
+	// included because model path has no single proto path.
+	ModelFields []Field
+	// Fields of nested model structures which are translated from model
+	// paths, e.g. "Address.City".
+	NestedFields []Field
+}
+
+// newFieldMaskData returns FieldMaskData for given transformation data
+// regardless of its swapped state.
+func newFieldMaskData(d *Data) FieldMaskData {
+	pref, src, dst := d.SrcPref, d.Src, d.DstFn
+	if d.Swapped {
+		pref, src, dst = d.DstPref, d.Dst, d.SrcFn
+	}
+
+	if pref != "" {
+		src = pref + "." + src
+	}
+
+	// Flattened sub messages have no model fields, constants have no proto
+	// fields or model fields, so they have no paths. Methods are not model
+	// fields too. Nested model structures are replaced with their leaves, paths
+	// of aliases are translated into model fields of their fields.
+	var fields, primary []Field
+	names := map[string]int{}
+	for _, f := range maskFields(d.Fields) {
+		if f.Flatten == nil && !f.Method && f.Name != "" && f.ProtoPath != "" {
+			fields = append(fields, f)
+			fields = append(fields, f.Aliases...)
+			primary = append(primary, f)
+			names[f.Name]++
+		}
+	}
+
+	var modelFields, nestedFields []Field
+	for _, f := range primary {
+		switch {
+		case names[f.Name] > 1:
+		case strings.Contains(f.Name, "."):
+			nestedFields = append(nestedFields, f)
+		default:
+			modelFields = append(modelFields, f)
+		}
+	}
+
+	return FieldMaskData{
+		Message:      src,
+		Model:        dst,
+		Fields:       fields,
+		ModelFields:  modelFields,
+		NestedFields: nestedFields,
+	}
+}
+
+// maskFields returns fields with nested model structures replaced by their
+// fields recursively, names of such fields are model paths.
+func maskFields(fields []Field) []Field {
+	var out []Field
+	for _, f := range fields {
+		if f.Nested != nil {
+			out = append(out, maskFields(f.Nested)...)
+		} else {
+			out = append(out, f)
+		}
+	}
+
+	return out
+}
+
+// execFieldMaskTemplate generates functions for translating
+// google.protobuf.FieldMask paths for each message.
+func execFieldMaskTemplate(w io.Writer, data []*Data) error {
+	t := template.New("fieldMask")
+	for _, v := range []*template.Template{fieldMaskPathT, fieldMaskColumnT, fieldMaskReverseT} {
+		if _, err := t.AddParseTree(at(v)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.Parse(fieldMaskT); err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		if d == nil {
+			continue
+		}
+
+		if err := t.Execute(w, newFieldMaskData(d)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package generator
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FieldMask", func() {
+
+	Describe("newFieldMaskData", func() {
+
+		DescribeTable("check result",
+			func(d Data, expected FieldMaskData) {
+				Expect(newFieldMaskData(&d)).To(Equal(expected))
+			},
+
+			Entry("Not swapped", Data{
+				SrcPref: "pb", Src: "Product", SrcFn: "Pb",
+				DstPref: "model", Dst: "ProductModel", DstFn: "ProductModel",
+			}, FieldMaskData{Message: "pb.Product", Model: "ProductModel"}),
+
+			Entry("Swapped", Data{
+				SrcPref: "model", Src: "ProductModel", SrcFn: "ProductModel",
+				DstPref: "pb", Dst: "Product", DstFn: "Pb",
+				Swapped: true,
+			}, FieldMaskData{Message: "pb.Product", Model: "ProductModel"}),
+
+			Entry("Without prefix", Data{
+				Src: "Product", SrcFn: "Pb", Dst: "ProductModel", DstFn: "ProductModel",
+			}, FieldMaskData{Message: "Product", Model: "ProductModel"}),
+		)
+
+		It("translates alias paths into model fields of their fields", func() {
+			alias := Field{Name: "Title", ProtoPath: "name"}
+			title := Field{Name: "Title", ProtoPath: "title", Aliases: []Field{alias}}
+
+			fmd := newFieldMaskData(&Data{Fields: []Field{title}})
+			Expect(fmd.Fields).To(Equal([]Field{title, alias}))
+			Expect(fmd.ModelFields).To(Equal([]Field{title}))
+		})
+
+		It("translates paths of fields of nested model structures", func() {
+			street := Field{Name: "Address.Street", ProtoPath: "street"}
+			lat := Field{Name: "Billing.Geo.Lat", ProtoPath: "lat"}
+			address := Field{Name: "Address", Nested: []Field{street}}
+			billing := Field{Name: "Billing", Nested: []Field{{Name: "Billing.Geo", Nested: []Field{lat}}}}
+			id := Field{Name: "ID", ProtoPath: "id"}
+
+			fmd := newFieldMaskData(&Data{Fields: []Field{id, address, billing}})
+			Expect(fmd.Fields).To(Equal([]Field{id, street, lat}))
+			Expect(fmd.ModelFields).To(Equal([]Field{id}))
+			Expect(fmd.NestedFields).To(Equal([]Field{street, lat}))
+		})
+
+		It("skips model fields of several proto fields in model paths", func() {
+			first := Field{Name: "FullName", ProtoPath: "first_name"}
+			last := Field{Name: "FullName", ProtoPath: "last_name"}
+			id := Field{Name: "ID", ProtoPath: "id"}
+
+			fmd := newFieldMaskData(&Data{Fields: []Field{first, id, last}})
+			Expect(fmd.Fields).To(Equal([]Field{first, id, last}))
+			Expect(fmd.ModelFields).To(Equal([]Field{id}))
+		})
+	})
+
+	Describe("Template parts", func() {
+		var (
+			w *bytes.Buffer
+			d = FieldMaskData{
+				Message: "pb.Order",
+				Model:   "Order",
+				Fields: []Field{
+					{Name: "ID", ProtoPath: "id", Column: "id"},
+					{Name: "Address", ProtoPath: "address", MaskTarget: "Address"},
+				},
+				ModelFields: []Field{
+					{Name: "ID", ProtoPath: "id", Column: "id"},
+					{Name: "Address", ProtoPath: "address", MaskTarget: "Address"},
+				},
+			}
+		)
+
+		BeforeEach(func() {
+			w = bytes.NewBuffer([]byte{})
+		})
+
+		Context("when execute template fieldMaskPathT", func() {
+
+			It("returns path translation functions", func() {
+				err := fieldMaskPathT.Execute(w, d)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.String()).To(Equal(`// PbToOrderPath translates google.protobuf.FieldMask path of pb.Order
+// message into path of Order fields.
+func PbToOrderPath(path string) (string, error) {
+	parts := strings.SplitN(path, ".", 2)
+
+	switch parts[0] {
+	case "id":
+		if len(parts) == 1 {
+			return "ID", nil
+		}
+	case "address":
+		if len(parts) == 1 {
+			return "Address", nil
+		}
+
+		if p, err := PbToAddressPath(parts[1]); err == nil {
+			return "Address." + p, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown field mask path %q", path)
+}
+
+// PbToOrderPaths translates list of google.protobuf.FieldMask paths of
+// pb.Order message into paths of Order fields.
+func PbToOrderPaths(paths []string) ([]string, error) {
+	resp := make([]string, len(paths))
+
+	for i, p := range paths {
+		mp, err := PbToOrderPath(p)
+		if err != nil {
+			return nil, err
+		}
+		resp[i] = mp
+	}
+
+	return resp, nil
+}`))
+			})
+		})
+
+		Context("when execute template fieldMaskColumnT", func() {
+
+			It("uses only fields with columns", func() {
+				err := fieldMaskColumnT.Execute(w, d)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.String()).To(ContainSubstring(`	case "id":
+		if len(parts) == 1 {
+			return "id", nil
+		}
+	case "address":
+
+		if len(parts) == 2 {
+			if c, err := PbToAddressColumn(parts[1]); err == nil {
+				return c, nil
+			}
+		}
+	}`))
+			})
+		})
+
+		Context("when execute template fieldMaskReverseT", func() {
+
+			It("uses model field names as cases", func() {
+				err := fieldMaskReverseT.Execute(w, d)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.String()).To(ContainSubstring(`	case "Address":
+		if len(parts) == 1 {
+			return "address", nil
+		}
+
+		if p, err := AddressToPbPath(parts[1]); err == nil {
+			return "address." + p, nil
+		}`))
+			})
+
+			It("checks paths of nested model structures first", func() {
+				nd := d
+				nd.NestedFields = []Field{
+					{Name: "Shipping.City", ProtoPath: "city"},
+					{Name: "Shipping.Geo", ProtoPath: "geo", MaskTarget: "Geo"},
+				}
+
+				err := fieldMaskReverseT.Execute(w, nd)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(w.String()).To(ContainSubstring(`func OrderToPbPath(path string) (string, error) {
+	if path == "Shipping.City" {
+		return "city", nil
+	}
+
+	if path == "Shipping.Geo" {
+		return "geo", nil
+	}
+
+	if p := strings.TrimPrefix(path, "Shipping.Geo."); p != path {
+		if p, err := GeoToPbPath(p); err == nil {
+			return "geo." + p, nil
+		}
+	}
+
+	parts := strings.SplitN(path, ".", 2)
+`))
+			})
+		})
+	})
+
+	Describe("execFieldMaskTemplate", func() {
+
+		It("generates functions for each message", func() {
+			w := bytes.NewBuffer([]byte{})
+			err := execFieldMaskTemplate(w, []*Data{
+				{Src: "Order", SrcFn: "Pb", Dst: "Order", DstFn: "Order"},
+				nil,
+				{Src: "Address", SrcFn: "Pb", Dst: "Address", DstFn: "Address"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, fn := range []string{
+				"PbToOrderPath", "PbToOrderPaths", "PbToOrderColumn", "PbToOrderColumns", "OrderToPbPath", "OrderToPbPaths",
+				"PbToAddressPath", "PbToAddressPaths", "PbToAddressColumn", "PbToAddressColumns", "AddressToPbPath", "AddressToPbPaths",
+			} {
+				Expect(w.String()).To(ContainSubstring("func " + fn + "("))
+			}
+		})
+	})
+})
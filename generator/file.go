@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
@@ -52,19 +54,84 @@ func fileHeader(srcFileName, srcFilePackage, dstPackage string) WriteStringer {
 	return w
 }
 
+// fileInfo contains file level information which is used for processing
+// messages and their fields.
+type fileInfo struct {
+	// Package name which contains model structures.
+	repoPackage string
+	// Package name with protobuf structures.
+	protoPackage string
+	// Package of .proto file, it is used for resolving Go types of messages.
+	pkg string
+	// Full name of processed message, e.g. "shop.Order".
+	message string
+	// Model structure of processed message, e.g. "Order".
+	model string
+	// Structures parsed from models file.
+	structs source.StructureList
+	// Methods of types from package of models file, see
+	// source.ParsePackageMethods. Methods are parsed only for files which use
+	// them, see usesMethods.
+	methods source.MethodList
+	// True if file has proto2 syntax, optional scalar fields of such files
+	// are pointers.
+	proto2 bool
+	// True if proto structures are generated by protoc-gen-gogofaster, which
+	// turns off gogoproto.nullable for proto2 scalars without default values.
+	gogofaster bool
+	// True if proto structures are generated by protoc-gen-go, see
+	// runtimeGolang.
+	golang bool
+	// True if file uses protobuf editions, presence of its fields is defined
+	// by features.
+	editions bool
+	// Resolved features of file or message for editions files.
+	features featureSet
+	// Closed enums declared in editions file, see closedEnums.
+	closedEnums map[string]string
+	// Map entry messages declared in file, see fileMapEntries.
+	mapEntries map[string]*descriptor.DescriptorProto
+	// Messages declared in file, see fileMessages.
+	messages map[string]*descriptor.DescriptorProto
+	// Extensions declared in file, see fileExtensions.
+	extensions map[string][]extension
+	// Expected model types of proto scalars, see fileScalarMap. Default table
+	// is used if it's nil.
+	types map[descriptor.FieldDescriptorProto_Type]typeRel
+}
+
+// modelType returns name of model type with package prefix.
+func (fi fileInfo) modelType(name string) string {
+	if fi.repoPackage == "" {
+		return name
+	}
+
+	return fi.repoPackage + "." + name
+}
+
 // CollectAllMessages processes all files passed within plugin request to
 // collect info about all incoming messages. Generator should have information
 // about all messages regardless have those messages transformer options or
-// haven't.
+// haven't. Messages without options, which are referenced by transformed
+// messages, are linked with model structures, see resolveSubMessages.
 func CollectAllMessages(req plugin.CodeGeneratorRequest) (MessageOptionList, error) {
 	mol := MessageOptionList{}
 
 	for _, f := range req.ProtoFile {
+		fieldMask := getBoolOption(f.Options, options.E_FieldMask)
+
 		for _, m := range f.MessageType {
 			structName, _ := extractStructNameOption(m)
 
 			so := messageOption{
 				targetName: structName,
+				fieldMask:  fieldMask,
+			}
+
+			if structName == "" && m.Options != nil {
+				if converter, err := getStringOption(m.Options, options.E_GoConverter); err == nil && converter != "" {
+					so.targetName, so.declared = converter, true
+				}
 			}
 
 			if len(m.OneofDecl) > 0 {
@@ -93,6 +160,8 @@ func CollectAllMessages(req plugin.CodeGeneratorRequest) (MessageOptionList, err
 		}
 	}
 
+	resolveSubMessages(req, mol)
+
 	return mol, nil
 }
 
@@ -112,18 +181,34 @@ func modelsPath(m proto.Message) (string, error) {
 	return path, nil
 }
 
-// ProcessFile processes .proto file and returns content as a string.
-func ProcessFile(f *descriptor.FileDescriptorProto, packageName, helperPackageName *string, messages MessageOptionList, debug bool, paths string) (string, error) {
+// ProcessFile processes .proto file and returns content as a string. Runtime
+// "golang" is used for structures generated by protoc-gen-go, other values are
+// used for gogo/protobuf structures. Scalar map entries like "int64:int64" set
+// model types expected for proto numeric types, see fileScalarMap.
+func ProcessFile(f *descriptor.FileDescriptorProto, packageName, helperPackageName *string, messages MessageOptionList, debug bool, paths string, gogofaster bool, runtime, scalarMap string) (string, error) {
 	path, err := modelsPath(f.Options)
 	if err != nil {
 		return "", err
 	}
 
+	option, _ := getStringOption(f.Options, options.E_ScalarMap)
+	table, err := fileScalarMap(scalarMap, option)
+	if err != nil {
+		return "", err
+	}
+
 	structs, err := source.Parse(path, nil)
 	if err != nil {
 		return "", err
 	}
 
+	var methods source.MethodList
+	if usesMethods(f) {
+		if methods, err = source.ParsePackageMethods(path); err != nil {
+			return "", err
+		}
+	}
+
 	w := fileHeader(*f.Name, *f.Package, *packageName)
 
 	if debug {
@@ -140,10 +225,36 @@ func ProcessFile(f *descriptor.FileDescriptorProto, packageName, helperPackageNa
 		protoPackage = "pb1"
 	}
 
+	extensions := fileExtensions(f)
+	if err := checkExtensions(f, extensions); err != nil {
+		return "", err
+	}
+
+	features, editions := fileFeatures(f)
+	golang := runtime == runtimeGolang
+
+	fi := fileInfo{
+		repoPackage:  repoPackage,
+		protoPackage: protoPackage,
+		pkg:          f.GetPackage(),
+		structs:      structs,
+		methods:      methods,
+		proto2:       f.GetSyntax() != "proto3" && !editions,
+		gogofaster:   gogofaster && !golang,
+		golang:       golang,
+		editions:     editions,
+		features:     features,
+		closedEnums:  closedEnums(f, features),
+		mapEntries:   fileMapEntries(f),
+		messages:     fileMessages(f),
+		extensions:   extensions,
+		types:        table,
+	}
+
 	var data []*Data
 
 	for _, m := range f.MessageType {
-		fields, sno, err := processMessage(w, m, messages, structs, debug)
+		fields, sno, err := processMessage(w, m, messages, fi, debug)
 		if err != nil {
 			if e, ok := err.(loggableError); ok {
 				p(w, "// %s\n", e)
@@ -164,6 +275,7 @@ func ProcessFile(f *descriptor.FileDescriptorProto, packageName, helperPackageNa
 				DstPref:    repoPackage,
 				DstFn:      sno,
 				Fields:     fields,
+				Golang:     golang,
 			})
 	}
 
@@ -175,25 +287,63 @@ func ProcessFile(f *descriptor.FileDescriptorProto, packageName, helperPackageNa
 		return "", err
 	}
 
-	return w.String(), nil
+	if getBoolOption(f.Options, options.E_FieldMask) {
+		if err := execFieldMaskTemplate(w, data); err != nil {
+			return "", err
+		}
+	}
+
+	pkgLine := fmt.Sprintln("\npackage", *packageName)
+
+	return strings.Replace(w.String(), pkgLine, pkgLine+fileImports(data), 1), nil
+}
+
+// fileImports returns import declaration with specs required by fields or an
+// empty string if fields require no imports.
+func fileImports(data []*Data) string {
+	seen := map[string]bool{}
+	var specs []string
+
+	for _, d := range data {
+		if d == nil {
+			continue
+		}
+
+		for _, f := range d.Fields {
+			for _, i := range f.Imports {
+				if !seen[i] {
+					seen[i] = true
+					specs = append(specs, i)
+				}
+			}
+		}
+	}
+
+	if len(specs) == 0 {
+		return ""
+	}
+
+	sort.Strings(specs)
+
+	return fmt.Sprintf("\nimport (\n\t%s\n)\n", strings.Join(specs, "\n\t"))
 }
 
 // execTemplate executes main template twice with given data, second pass is
 // used for generated reverse functions.
 func execTemplate(w io.Writer, data []*Data) error {
 	for _, d := range data {
-		t, err := templateWithHelpers("messages")
+		t, err := templateWithHelpers("messages", d.Golang)
 		if err != nil {
 			return err
 		}
 
-		if err := t.Execute(w, d); err != nil {
+		if err := t.Execute(w, d.directed()); err != nil {
 			return err
 		}
 
 		d.swap()
 
-		if err := t.Execute(w, d); err != nil {
+		if err := t.Execute(w, d.directed()); err != nil {
 			return err
 		}
 	}
@@ -191,7 +191,7 @@ var _ = Describe("File", func() {
 				expectedContent, err := ioutil.ReadFile("testdata/processfile.go.golden")
 				Expect(err).NotTo(HaveOccurred())
 
-				content, err := ProcessFile(f, sp("product"), sp("helper-package"), map[string]MessageOption{}, false, "")
+				content, err := ProcessFile(f, sp("product"), sp("helper-package"), map[string]MessageOption{}, false, "", true, "gogo", "")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(content).To(Equal(string(expectedContent)))
 				//Expect(absPath).To(Equal("product_transformer.go"))
@@ -288,6 +288,21 @@ package fff
 		)
 	})
 
+	Describe("fileImports", func() {
+
+		DescribeTable("check results",
+			func(d []*Data, expected string) {
+				Expect(fileImports(d)).To(Equal(expected))
+			},
+			Entry("No imports", []*Data{{Fields: []Field{{Name: "f"}}}, nil}, ""),
+			Entry("Imports are sorted and unique", []*Data{
+				{Fields: []Field{{Imports: []string{`"b"`, `a "z"`}}}},
+				nil,
+				{Fields: []Field{{Imports: []string{`"b"`}}}},
+			}, "\nimport (\n\t\"b\"\n\ta \"z\"\n)\n"),
+		)
+	})
+
 	Describe("execTemplate", func() {
 
 		DescribeTable("check results",
@@ -0,0 +1,289 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	errFlattenType     = errors.New("only singular message fields can be flattened")
+	errFlattenNotFound = errors.New("flattened message should be declared in the same file")
+	errFlattenOneof    = errors.New("oneof fields of flattened message are not supported")
+	errFlattenMap      = errors.New("flatten_map entries should be in form proto_field=ModelField")
+)
+
+// fileMessages returns messages declared in file f on top level and inside
+// other messages. Map key is a full type name, e.g. ".shop.Product.Dimensions".
+func fileMessages(f *descriptor.FileDescriptorProto) map[string]*descriptor.DescriptorProto {
+	messages := map[string]*descriptor.DescriptorProto{}
+
+	var walk func(prefix string, msgs []*descriptor.DescriptorProto)
+	walk = func(prefix string, msgs []*descriptor.DescriptorProto) {
+		for _, m := range msgs {
+			name := prefix + m.GetName()
+			messages[name] = m
+			walk(name+".", m.NestedType)
+		}
+	}
+
+	prefix := "."
+	if pkg := f.GetPackage(); pkg != "" {
+		prefix += pkg + "."
+	}
+
+	walk(prefix, f.MessageType)
+
+	return messages
+}
+
+// isFlatten returns true if field fdp has transformer.flatten option.
+func isFlatten(fdp *descriptor.FieldDescriptorProto) bool {
+	return getBoolOption(fdp.Options, options.E_Flatten)
+}
+
+// flattenNames returns model field names of flattened sub message fields from
+// transformer.flatten_map option of field fdp.
+func flattenNames(fdp *descriptor.FieldDescriptorProto) (map[string]string, error) {
+	names := map[string]string{}
+
+	value, _ := getStringOption(fdp.Options, options.E_FlattenMap)
+	if value == "" {
+		return names, nil
+	}
+
+	for _, e := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(e), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, pkgerrors.Wrap(errFlattenMap, e)
+		}
+		names[kv[0]] = kv[1]
+	}
+
+	return names, nil
+}
+
+// modelPresence returns format of condition which is true if model field gf
+// has non-zero value and import spec which is required by condition.
+func modelPresence(gf source.FieldInfo) (string, string) {
+	switch {
+	case gf.IsPointer:
+		return "%s != nil", ""
+	case gf.IsSlice || strings.HasPrefix(gf.Type, "map["):
+		return "len(%s) > 0", ""
+	}
+
+	switch gf.Type {
+	case "string":
+		return `%s != ""`, ""
+	case "bool":
+		return "%s", ""
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64", "byte", "rune":
+		return "%s != 0", ""
+	case "time.Time":
+		return "!%s.IsZero()", ""
+	}
+
+	return "!reflect.ValueOf(%s).IsZero()", `"reflect"`
+}
+
+// flattenField returns *Field for sub message field fdp with
+// transformer.flatten option. Fields of sub message are processed as fields
+// of parent message which are mapped into prefixed model fields or model
+// fields from transformer.flatten_map option.
+func flattenField(w io.Writer, fdp *descriptor.FieldDescriptorProto, subMessages MessageOptionList, goStructFields source.Structure, fi fileInfo) (*Field, error) {
+	if fdp.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE || fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return nil, pkgerrors.Wrap(errFlattenType, fdp.GetName())
+	}
+
+	msg, ok := fi.messages[fdp.GetTypeName()]
+	t, isLocal := protoMessageType(fdp.GetTypeName(), fi)
+	if !ok || !isLocal {
+		return nil, pkgerrors.Wrap(errFlattenNotFound, fdp.GetName())
+	}
+
+	names, err := flattenNames(fdp)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, fdp.GetName())
+	}
+	prefix, _ := getStringOption(fdp.Options, options.E_FlattenPrefix)
+
+	if fi.editions {
+		fi.features = messageFeatures(fi.features, msg)
+	}
+
+	pname, _ := prepareFieldNames(fdp.GetName(), protoGoName(fdp), "")
+
+	f := &Field{
+		Name:           pname,
+		ProtoName:      pname,
+		ProtoType:      t,
+		ProtoIsPointer: fi.nullable(fdp),
+		// Field is flattened even if sub message has no fields.
+		Flatten:      []Field{},
+		FlattenEmpty: getBoolOption(fdp.Options, options.E_FlattenEmpty),
+	}
+
+	for _, sf := range msg.Field {
+		if extractSkipOption(sf.Options) {
+			continue
+		}
+
+		if sf.OneofIndex != nil && !isProto3Optional(sf) {
+			return nil, pkgerrors.Wrap(errFlattenOneof, fmt.Sprintf("%s.%s", fdp.GetName(), sf.GetName()))
+		}
+
+		name, ok := names[sf.GetName()]
+		if !ok {
+			_, gname := prepareFieldNames(sf.GetName(), "", "")
+			name = prefix + gname
+		}
+
+		c := proto.Clone(sf).(*descriptor.FieldDescriptorProto)
+		if c.Options == nil {
+			c.Options = &descriptor.FieldOptions{}
+		}
+		if err := proto.SetExtension(c.Options, options.E_MapTo, &name); err != nil {
+			return nil, pkgerrors.Wrap(err, sf.GetName())
+		}
+
+		ff, err := processField(w, c, subMessages, goStructFields, fi)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, fdp.GetName())
+		}
+
+		if fi.golang {
+			golangField(ff, c, fi)
+		}
+		ff.Direction = extractDirectionOption(sf.Options)
+
+		cond, spec := modelPresence(goStructFields[name])
+		ff.ModelPresence = cond
+		if spec != "" {
+			ff.Imports = append(ff.Imports, spec)
+		}
+
+		f.Flatten = append(f.Flatten, *ff)
+		// Imports of flattened fields are collected with imports of their
+		// field, see fileImports.
+		f.Imports = append(f.Imports, ff.Imports...)
+	}
+
+	if fi.golang {
+		f.Getter = true
+	}
+
+	return f, nil
+}
+
+// formatFlattenField returns statements which copy fields of flattened sub
+// message into model fields or build sub message from model fields. Sub
+// message is not built if all model fields have zero values, unless field has
+// transformer.flatten_empty option. Flattened fields are checked and get
+// default values like other fields of message.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatFlattenField(f Field, swapped bool) string {
+	var fields []Field
+	for _, sf := range f.Flatten {
+		if sf.transformed(swapped) {
+			fields = append(fields, sf)
+		}
+	}
+
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+
+	if !swapped {
+		// Source structure is shadowed by sub message, so fields are
+		// converted as fields of parent message.
+		if f.ProtoIsPointer {
+			fmt.Fprintf(&b, "\tif %s != nil {\n", f.srcField(false))
+		} else {
+			b.WriteString("\t{\n")
+		}
+		fmt.Fprintf(&b, "\t\tsrc := %s\n", f.srcField(false))
+		for _, sf := range fields {
+			fmt.Fprintf(&b, "\t\ts.%s = %s\n", sf.Name, formatComplexField(sf, false))
+		}
+		for _, sf := range fields {
+			b.WriteString(indentLines(formatRequiredField(sf, false)+formatDefaultField(sf, false), "\t"))
+		}
+
+		// Fields of nil sub message have no values, so they get default
+		// values.
+		var defaults strings.Builder
+		for _, sf := range fields {
+			if sf.Default != "" {
+				fmt.Fprintf(&defaults, "\t\ts.%s = %s\n", sf.Name, sf.Default)
+			}
+		}
+		if f.ProtoIsPointer && defaults.Len() > 0 {
+			fmt.Fprintf(&b, "\t} else {\n%s", defaults.String())
+		}
+		b.WriteString("\t}\n")
+
+		return b.String()
+	}
+
+	ref := ""
+	if f.ProtoIsPointer {
+		ref = "&"
+	}
+
+	indent := "\t"
+	if f.ProtoIsPointer && !f.FlattenEmpty {
+		conds := make([]string, len(fields))
+		for i, sf := range fields {
+			conds[i] = fmt.Sprintf(sf.ModelPresence, "src."+sf.Name)
+		}
+		fmt.Fprintf(&b, "\tif %s {\n", strings.Join(conds, " || "))
+		indent = "\t\t"
+	}
+
+	fmt.Fprintf(&b, "%ss.%s = %s%s{\n", indent, f.ProtoName, ref, f.ProtoType)
+	for _, sf := range fields {
+		fmt.Fprintf(&b, "%s\t%s\n", indent, formatField(sf, true, ""))
+	}
+	fmt.Fprintf(&b, "%s}\n", indent)
+
+	// Closed enums are checked in sub message, which shadows target
+	// structure.
+	var checks strings.Builder
+	for _, sf := range fields {
+		b.WriteString(indentLines(formatRequiredField(sf, true), indent[1:]))
+		checks.WriteString(formatClosedEnumField(sf, true))
+	}
+	if checks.Len() > 0 {
+		fmt.Fprintf(&b, "%s{\n%s\ts := s.%s\n", indent, indent, f.ProtoName)
+		b.WriteString(indentLines(checks.String(), indent))
+		fmt.Fprintf(&b, "%s}\n", indent)
+	}
+
+	if indent != "\t" {
+		b.WriteString("\t}\n")
+	}
+
+	return b.String()
+}
+
+// indentLines returns statements s with each line prefixed by indent.
+func indentLines(s, indent string) string {
+	if s == "" || indent == "" {
+		return s
+	}
+
+	return indent + strings.Replace(strings.TrimSuffix(s, "\n"), "\n", "\n"+indent, -1) + "\n"
+}
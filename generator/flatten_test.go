@@ -0,0 +1,266 @@
+package generator
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Flatten", func() {
+	var (
+		dbl      = descriptor.FieldDescriptorProto_TYPE_DOUBLE
+		str      = descriptor.FieldDescriptorProto_TYPE_STRING
+		msg      = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+		optional = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	dims := &descriptor.DescriptorProto{
+		Name: sp("Dimensions"),
+		Field: []*descriptor.FieldDescriptorProto{
+			{Name: sp("width"), Type: &dbl, Label: &optional},
+			{Name: sp("unit"), Type: &str, Label: &optional},
+		},
+	}
+
+	file := &descriptor.FileDescriptorProto{
+		Package: sp("shop"),
+		MessageType: []*descriptor.DescriptorProto{
+			{Name: sp("Product"), NestedType: []*descriptor.DescriptorProto{{Name: sp("Size")}}},
+			dims,
+		},
+	}
+
+	fi := fileInfo{gogofaster: true, pkg: "shop", protoPackage: "pb", messages: fileMessages(file)}
+
+	// field returns sub message field with flatten option and options from
+	// opts.
+	field := func(typeName string, opts map[*proto.ExtensionDesc]interface{}) *descriptor.FieldDescriptorProto {
+		all := map[*proto.ExtensionDesc]interface{}{options.E_Flatten: true}
+		for ext, v := range opts {
+			all[ext] = v
+		}
+
+		fdp := newField("dims", msg, all)
+		fdp.Label, fdp.TypeName = &optional, sp(typeName)
+
+		return fdp
+	}
+
+	It("fileMessages returns top level and nested messages", func() {
+		Expect(fileMessages(file)).To(HaveLen(3))
+		Expect(fileMessages(file)).To(HaveKey(".shop.Product.Size"))
+		Expect(fileMessages(file)[".shop.Dimensions"]).To(Equal(dims))
+	})
+
+	DescribeTable("flattenNames",
+		func(value string, expected map[string]string) {
+			names, err := flattenNames(field(".shop.Dimensions", map[*proto.ExtensionDesc]interface{}{options.E_FlattenMap: value}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal(expected))
+		},
+
+		Entry("Empty", "", map[string]string{}),
+		Entry("One field", "width=W", map[string]string{"width": "W"}),
+		Entry("Fields with spaces", "width=W, unit=Unit", map[string]string{"width": "W", "unit": "Unit"}),
+	)
+
+	It("flattenNames returns an error for invalid entries", func() {
+		_, err := flattenNames(field(".shop.Dimensions", map[*proto.ExtensionDesc]interface{}{options.E_FlattenMap: "width"}))
+		Expect(pkgerrors.Cause(err)).To(MatchError(errFlattenMap))
+	})
+
+	DescribeTable("modelPresence",
+		func(gf source.FieldInfo, expected, spec string) {
+			cond, s := modelPresence(gf)
+			Expect(cond).To(Equal(expected))
+			Expect(s).To(Equal(spec))
+		},
+
+		Entry("Pointer", source.FieldInfo{Type: "string", IsPointer: true}, "%s != nil", ""),
+		Entry("Slice", source.FieldInfo{Type: "string", IsSlice: true}, "len(%s) > 0", ""),
+		Entry("Map", source.FieldInfo{Type: "map[string]int64"}, "len(%s) > 0", ""),
+		Entry("String", source.FieldInfo{Type: "string"}, `%s != ""`, ""),
+		Entry("Bool", source.FieldInfo{Type: "bool"}, "%s", ""),
+		Entry("Number", source.FieldInfo{Type: "float64"}, "%s != 0", ""),
+		Entry("Time", source.FieldInfo{Type: "time.Time"}, "!%s.IsZero()", ""),
+		Entry("Other types", source.FieldInfo{Type: "uuid.UUID"}, "!reflect.ValueOf(%s).IsZero()", `"reflect"`),
+	)
+
+	Describe("flattenField", func() {
+		gf := source.Structure{
+			"DimsWidth": {Type: "float64"},
+			"DimsUnit":  {Type: "string"},
+			"Unit":      {Type: "string"},
+		}
+
+		It("maps fields of sub message into prefixed model fields", func() {
+			f, err := flattenField(&bytes.Buffer{}, field(".shop.Dimensions", map[*proto.ExtensionDesc]interface{}{options.E_FlattenPrefix: "Dims"}), nil, gf, fi)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(f.ProtoName).To(Equal("Dims"))
+			Expect(f.ProtoType).To(Equal("pb.Dimensions"))
+			Expect(f.ProtoIsPointer).To(BeTrue())
+			Expect(f.Flatten).To(HaveLen(2))
+			Expect(f.Flatten[0].Name).To(Equal("DimsWidth"))
+			Expect(f.Flatten[0].ProtoName).To(Equal("Width"))
+			Expect(f.Flatten[0].ModelPresence).To(Equal("%s != 0"))
+			Expect(f.Flatten[1].Name).To(Equal("DimsUnit"))
+		})
+
+		It("maps fields of sub message into fields from flatten_map option", func() {
+			f, err := flattenField(&bytes.Buffer{}, field(".shop.Dimensions", map[*proto.ExtensionDesc]interface{}{
+				options.E_FlattenPrefix: "Dims",
+				options.E_FlattenMap:    "unit=Unit",
+			}), nil, gf, fi)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(f.Flatten[0].Name).To(Equal("DimsWidth"))
+			Expect(f.Flatten[1].Name).To(Equal("Unit"))
+		})
+
+		It("returns an error for model fields which are not found", func() {
+			_, err := flattenField(&bytes.Buffer{}, field(".shop.Dimensions", nil), nil, gf, fi)
+			Expect(err).To(MatchError("dims: Width: field not found in destination structure"))
+		})
+
+		It("returns an error for repeated fields", func() {
+			fdp := field(".shop.Dimensions", nil)
+			fdp.Label = &repeated
+
+			_, err := flattenField(&bytes.Buffer{}, fdp, nil, gf, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errFlattenType))
+		})
+
+		It("returns an error for messages from other files", func() {
+			_, err := flattenField(&bytes.Buffer{}, field(".other.Dimensions", nil), nil, gf, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errFlattenNotFound))
+		})
+
+		It("returns an error for oneof fields of sub message", func() {
+			var zero int32
+			oneof := &descriptor.FileDescriptorProto{
+				Package: sp("shop"),
+				MessageType: []*descriptor.DescriptorProto{{
+					Name:  sp("Code"),
+					Field: []*descriptor.FieldDescriptorProto{{Name: sp("sku"), Type: &str, Label: &optional, OneofIndex: &zero}},
+				}},
+			}
+			fi := fi
+			fi.messages = fileMessages(oneof)
+
+			_, err := flattenField(&bytes.Buffer{}, field(".shop.Code", nil), nil, gf, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errFlattenOneof))
+		})
+	})
+
+	DescribeTable("formatFlattenField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatFlattenField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Not flattened", Field{Name: "Dims", ProtoName: "Dims"}, false, ""),
+		Entry("Sub message without fields", Field{Name: "Dims", ProtoName: "Dims", Flatten: []Field{}}, true, ""),
+		Entry("Proto to Go", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoIsPointer: true,
+			Flatten: []Field{
+				{Name: "Width", ProtoName: "Width"},
+				{Name: "Unit", ProtoName: "Unit"},
+			},
+		}, false, "\tif src.Dims != nil {\n\t\tsrc := src.Dims\n\t\ts.Width = src.Width\n\t\ts.Unit = src.Unit\n\t}\n"),
+		Entry("Proto to Go, non-nullable", Field{
+			Name:      "Dims",
+			ProtoName: "Dims",
+			Flatten:   []Field{{Name: "Width", ProtoName: "Width"}},
+		}, false, "\t{\n\t\tsrc := src.Dims\n\t\ts.Width = src.Width\n\t}\n"),
+		Entry("Go to proto", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoType:      "pb.Dimensions",
+			ProtoIsPointer: true,
+			Flatten: []Field{
+				{Name: "Width", ProtoName: "Width", ModelPresence: "%s != 0"},
+				{Name: "Unit", ProtoName: "Unit", ModelPresence: `%s != ""`},
+			},
+		}, true, "\tif src.Width != 0 || src.Unit != \"\" {\n\t\ts.Dims = &pb.Dimensions{\n\t\t\tWidth: src.Width,\n\t\t\tUnit: src.Unit,\n\t\t}\n\t}\n"),
+		Entry("Go to proto, empty sub message", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoType:      "pb.Dimensions",
+			ProtoIsPointer: true,
+			FlattenEmpty:   true,
+			Flatten:        []Field{{Name: "Width", ProtoName: "Width", ModelPresence: "%s != 0"}},
+		}, true, "\ts.Dims = &pb.Dimensions{\n\t\tWidth: src.Width,\n\t}\n"),
+		Entry("Go to proto, non-nullable", Field{
+			Name:      "Dims",
+			ProtoName: "Dims",
+			ProtoType: "pb.Dimensions",
+			Flatten:   []Field{{Name: "Width", ProtoName: "Width", ModelPresence: "%s != 0"}},
+		}, true, "\ts.Dims = pb.Dimensions{\n\t\tWidth: src.Width,\n\t}\n"),
+		Entry("Proto to Go, skips fields of other direction", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoIsPointer: true,
+			Flatten: []Field{
+				{Name: "Width", ProtoName: "Width"},
+				{Name: "Unit", ProtoName: "Unit", Direction: options.Direction_MODEL_TO_PB},
+			},
+		}, false, "\tif src.Dims != nil {\n\t\tsrc := src.Dims\n\t\ts.Width = src.Width\n\t}\n"),
+		Entry("Go to proto, skips fields of other direction", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoType:      "pb.Dimensions",
+			ProtoIsPointer: true,
+			Flatten: []Field{
+				{Name: "Width", ProtoName: "Width", ModelPresence: "%s != 0", Direction: options.Direction_PB_TO_MODEL},
+				{Name: "Unit", ProtoName: "Unit", ModelPresence: `%s != ""`},
+			},
+		}, true, "\tif src.Unit != \"\" {\n\t\ts.Dims = &pb.Dimensions{\n\t\t\tUnit: src.Unit,\n\t\t}\n\t}\n"),
+		Entry("Proto to Go, required and default fields", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoIsPointer: true,
+			Flatten: []Field{
+				{Name: "Width", ProtoName: "Width", Required: true},
+				{Name: "Unit", ProtoName: "Unit", Default: `"cm"`, Presence: `%s != ""`},
+			},
+		}, false, "\tif src.Dims != nil {\n\t\tsrc := src.Dims\n\t\ts.Width = src.Width\n\t\ts.Unit = src.Unit\n"+
+			"\t\tif src.Width == nil {\n\t\t\treportError(opts, errors.New(\"Width: required field is not set\"))\n\t\t}\n"+
+			"\t\tif src.Unit == \"\" {\n\t\t\ts.Unit = \"cm\"\n\t\t}\n"+
+			"\t} else {\n\t\ts.Unit = \"cm\"\n\t}\n"),
+		Entry("Go to proto, required and closed enum fields", Field{
+			Name:           "Dims",
+			ProtoName:      "Dims",
+			ProtoType:      "pb.Dimensions",
+			ProtoIsPointer: true,
+			FlattenEmpty:   true,
+			Flatten: []Field{
+				{Name: "Width", ProtoName: "Width", Required: true, GoIsPointer: true},
+				{Name: "Unit", ProtoName: "Unit", ClosedEnum: "pb.Unit_name"},
+			},
+		}, true, "\ts.Dims = &pb.Dimensions{\n\t\tWidth: src.Width,\n\t\tUnit: src.Unit,\n\t}\n"+
+			"\tif src.Width == nil {\n\t\treportError(opts, errors.New(\"Width: required field is not set\"))\n\t}\n"+
+			"\t{\n\t\ts := s.Dims\n\t\tif v := s.Unit; pb.Unit_name[int32(v)] == \"\" {\n"+
+			"\t\t\treportError(opts, fmt.Errorf(\"Unit: value %d is not declared in closed enum\", v))\n\t\t}\n\t}\n"),
+	)
+
+	It("formatField skips flattened fields", func() {
+		f := Field{Name: "Dims", ProtoName: "Dims", Flatten: []Field{}}
+		Expect(formatField(f, false, "model")).To(BeEmpty())
+		Expect(formatField(f, true, "pb")).To(BeEmpty())
+	})
+
+	It("newFieldMaskData skips flattened fields", func() {
+		d := &Data{Fields: []Field{{Name: "ID", ProtoPath: "id"}, {Name: "Dims", ProtoPath: "dims", Flatten: []Field{}}}}
+		Expect(newFieldMaskData(d).Fields).To(Equal([]Field{{Name: "ID", ProtoPath: "id"}}))
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -16,7 +17,9 @@ func TestGenerator(t *testing.T) {
 
 var (
 	typInt64   = descriptor.FieldDescriptorProto_TYPE_INT64
+	typString  = descriptor.FieldDescriptorProto_TYPE_STRING
 	typMessage = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	typEnum    = descriptor.FieldDescriptorProto_TYPE_ENUM
 
 	sp = func(s string) *string {
 		return &s
@@ -24,6 +27,37 @@ var (
 	bp = func(b bool) *bool {
 		return &b
 	}
+	i32p = func(i int32) *int32 {
+		return &i
+	}
+
+	// newField returns proto field with options from opts, string and bool
+	// values are set by pointers, empty strings are skipped. Field has no
+	// options if none of them is set. Fields are created before specs are run,
+	// so errors can't be checked by Expect.
+	newField = func(name string, typ descriptor.FieldDescriptorProto_Type, opts map[*proto.ExtensionDesc]interface{}) *descriptor.FieldDescriptorProto {
+		fdp := &descriptor.FieldDescriptorProto{Name: sp(name), Type: &typ}
+		for ext, v := range opts {
+			switch t := v.(type) {
+			case string:
+				if t == "" {
+					continue
+				}
+				v = sp(t)
+			case bool:
+				v = bp(t)
+			}
+
+			if fdp.Options == nil {
+				fdp.Options = &descriptor.FieldOptions{}
+			}
+			if err := proto.SetExtension(fdp.Options, ext, v); err != nil {
+				panic(err)
+			}
+		}
+
+		return fdp
+	}
 
 	// key - field name, value - field type
 	// goStruct contains model structure fields.
@@ -43,6 +77,10 @@ var (
 		"TimePtrField": {Type: "*time.Time"},
 		"PkgTypeField": {Type: "pkg.Type"},
 		"ProtoField":   {Type: "proto.FieldType"},
+		"ColumnField":  {Type: "string", Tag: `db:"column_name"`},
+		"UUIDField":    {Type: "uuid.UUID", PkgPath: "github.com/google/uuid"},
+
+		"NullTimeField": {Type: "sql.NullTime", PkgPath: "database/sql"},
 
 		"StringFieldPtr":  {Type: "string", IsPointer: true},
 		"BoolFieldPtr":    {Type: "bool", IsPointer: true},
@@ -73,6 +111,11 @@ var (
 		targetName: "pkgField",
 		fullName:   "full.name",
 	}
+	moFieldMask = messageOption{
+		targetName: "maskTarget",
+		fullName:   "full.name",
+		fieldMask:  true,
+	}
 
 	subm = map[string]MessageOption{
 		"FieldName": mo,
@@ -0,0 +1,249 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/iancoleman/strcase"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// gogoType is a Go type of proto field which is changed by gogoproto options
+// customtype, casttype, stdtime, stdduration, castkey and castvalue.
+type gogoType struct {
+	// Go type of field, type of slice element for repeated fields and map
+	// type for map fields, e.g. "time.Time" or "map[string]pb.Code".
+	goType string
+	// True if field or slice element is a pointer.
+	pointer bool
+	// True if field is repeated.
+	slice bool
+	// True if field is a map.
+	isMap bool
+	// Go type of proto scalar for fields with casttype option, e.g. "int64".
+	underlying string
+	// Import specs for packages of type.
+	imports []string
+}
+
+// fileMapEntries returns map entry messages declared in file f. Map key is a
+// full name of entry message, e.g. ".pb.Product.AttributesEntry".
+func fileMapEntries(f *descriptor.FileDescriptorProto) map[string]*descriptor.DescriptorProto {
+	entries := map[string]*descriptor.DescriptorProto{}
+
+	for name, m := range fileMessages(f) {
+		if m.GetOptions().GetMapEntry() {
+			entries[name] = m
+		}
+	}
+
+	return entries
+}
+
+// castTypeName returns Go type and import spec for type name from gogoproto
+// options, e.g. "github.com/example/types.Code" is converted into "types.Code".
+// Types without package are declared in proto package.
+func castTypeName(name string, fi fileInfo) (string, string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return fi.protoPackage + "." + name, ""
+	}
+
+	path := name[:i]
+	pkg := pkgName(path)
+
+	return pkg + "." + name[i+1:], importSpec(pkg, path)
+}
+
+// typeZero returns zero value of Go type t.
+func typeZero(t string) string {
+	switch t {
+	case "time.Time":
+		return "time.Time{}"
+	case "time.Duration":
+		return "0"
+	}
+
+	for _, pt := range pbGoTypes {
+		if t == pt {
+			return zeroValue(t)
+		}
+	}
+
+	return fmt.Sprintf("*new(%s)", t)
+}
+
+// scalarGoType returns Go type and import spec of map key or value, type is
+// changed by value of castkey or castvalue option. The last return value is
+// false for messages, enums and other types which are not supported.
+func scalarGoType(fdp *descriptor.FieldDescriptorProto, cast string, fi fileInfo) (string, string, bool) {
+	if cast != "" {
+		t, imp := castTypeName(cast, fi)
+		return t, imp, true
+	}
+
+	t, ok := pbGoTypes[fdp.GetType()]
+
+	return t, "", ok
+}
+
+// protoGoType returns Go type of proto field. The second return value is
+// false if Go type is not changed by gogoproto options, such fields are
+// processed as usual. Only maps with castkey or castvalue options and scalar
+// keys and values are returned.
+// Structures generated by protoc-gen-go ignore gogoproto options, so only
+// maps are returned for them.
+func protoGoType(fdp *descriptor.FieldDescriptorProto, fi fileInfo) (gogoType, bool) {
+	gt := gogoType{
+		slice:   fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED,
+		pointer: extractNullOption(fdp),
+	}
+
+	switch {
+	case fi.golang:
+		return mapType(fdp, "", "", fi)
+	case gogoproto.IsCustomType(fdp):
+		t, imp := castTypeName(gogoproto.GetCustomType(fdp), fi)
+		gt.goType, gt.pointer = t, gt.pointer && !gt.slice
+		if imp != "" {
+			gt.imports = append(gt.imports, imp)
+		}
+	case gogoproto.IsStdTime(fdp):
+		gt.goType, gt.imports = "time.Time", []string{`"time"`}
+	case gogoproto.IsStdDuration(fdp):
+		gt.goType, gt.imports = "time.Duration", []string{`"time"`}
+	case gogoproto.IsCastType(fdp):
+		t, imp := castTypeName(gogoproto.GetCastType(fdp), fi)
+		gt.goType, gt.pointer = t, !gt.slice && isPointerScalar(fdp, fi)
+		gt.underlying = pbGoTypes[fdp.GetType()]
+		if imp != "" {
+			gt.imports = append(gt.imports, imp)
+		}
+	case gogoproto.IsCastKey(fdp) || gogoproto.IsCastValue(fdp):
+		return mapType(fdp, gogoproto.GetCastKey(fdp), gogoproto.GetCastValue(fdp), fi)
+	default:
+		return gogoType{}, false
+	}
+
+	return gt, true
+}
+
+// mapType returns Go type of map field fdp, key and value types are changed by
+// castkey and castvalue options. The second return value is false if field is
+// not a map or map has keys or values of unsupported types.
+func mapType(fdp *descriptor.FieldDescriptorProto, castKey, castValue string, fi fileInfo) (gogoType, bool) {
+	entry, ok := fi.mapEntries[fdp.GetTypeName()]
+	if !ok || len(entry.Field) != 2 {
+		return gogoType{}, false
+	}
+
+	k, kimp, kok := scalarGoType(entry.Field[0], castKey, fi)
+	v, vimp, vok := scalarGoType(entry.Field[1], castValue, fi)
+	if !kok || !vok {
+		return gogoType{}, false
+	}
+
+	gt := gogoType{goType: fmt.Sprintf("map[%s]%s", k, v), isMap: true}
+	for _, imp := range []string{kimp, vimp} {
+		if imp != "" {
+			gt.imports = append(gt.imports, imp)
+		}
+	}
+
+	return gt, true
+}
+
+// sameType returns true if model field gf has the same type as Go type of
+// proto field. Slices of values of types from other packages are parsed
+// without package name.
+func (gt gogoType) sameType(gf source.FieldInfo) bool {
+	if gt.slice != gf.IsSlice {
+		return false
+	}
+
+	if gf.IsSlice && !gf.IsPointer && gf.PkgPath != "" {
+		return gf.Type == lastName(gt.goType)
+	}
+
+	return gf.Type == gt.goType
+}
+
+// convs returns expressions which convert value v of proto field into model
+// type t and back. Types with casttype option are converted through their
+// underlying proto scalar types. Empty strings are returned if types can not
+// be converted.
+func (gt gogoType) convs(t string) (string, string) {
+	if gt.underlying == "" {
+		return convertExpr("v", gt.goType, t), convertExpr("v", t, gt.goType)
+	}
+
+	toGo := convertExpr(fmt.Sprintf("%s(v)", gt.underlying), gt.underlying, t)
+	toPb := convertExpr("v", t, gt.underlying)
+	if toGo == "" || toPb == "" {
+		return "", ""
+	}
+
+	return toGo, fmt.Sprintf("%s(%s)", gt.goType, toPb)
+}
+
+// helperName returns part of helper function name for Go type t, e.g.
+// "TimeTime" for time.Time.
+func helperName(t string) string {
+	return strcase.ToCamel(strings.Replace(t, ".", "", -1))
+}
+
+// gogoTypedField returns *Field for proto field which Go type is changed by
+// gogoproto options. Fields of the same types are assigned directly, pointers
+// are dereferenced inline, scalars are converted inline if possible, other
+// fields are converted by helper functions.
+func gogoTypedField(pname, gname string, gt gogoType, gf source.FieldInfo) (*Field, error) {
+	f := &Field{
+		Name:           gname,
+		ProtoName:      pname,
+		GoIsPointer:    gf.IsPointer,
+		ProtoIsPointer: gt.pointer,
+	}
+
+	if gt.isMap {
+		if gf.Type != gt.goType || gf.IsPointer {
+			return nil, pkgerrors.Wrap(fmt.Errorf("%s can not be converted into %s", gt.goType, gf), gname)
+		}
+
+		return f, nil
+	}
+
+	if gt.sameType(gf) && gt.pointer == gf.IsPointer {
+		return f, nil
+	}
+
+	if !gt.slice && !gf.IsSlice {
+		if toGo, toPb := gt.convs(gf.Type); toGo != "" && toPb != "" {
+			pbZero := typeZero(gt.goType)
+			if gt.pointer {
+				pbZero = "nil"
+			}
+
+			f := inlineField(pname, gname,
+				googleType{pbType: gt.goType, pbZero: pbZero, scalar: true, imports: gt.imports},
+				googleTypeConv{goZero: typeZero(gf.Type), toGo: toGo, toPb: toPb},
+				gf, gt.pointer, false)
+			f.GoIsPointer, f.ProtoIsPointer = gf.IsPointer, gt.pointer
+
+			return f, nil
+		}
+	}
+
+	p, g := helperName(gt.goType), helperName(gf.Type)
+	if gt.slice {
+		p, g = p+"List", g+"List"
+	}
+
+	f.ProtoToGoType = fmt.Sprintf("%sTo%s", p, g)
+	f.GoToProtoType = fmt.Sprintf("%sTo%s", g, p)
+	f.UsePackage = true
+
+	return f, nil
+}
@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"errors"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Gogotype", func() {
+	var (
+		i64 = descriptor.FieldDescriptorProto_TYPE_INT64
+		str = descriptor.FieldDescriptorProto_TYPE_STRING
+		msg = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+		opt = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		rep = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	// field returns proto field with gogoproto options.
+	field := func(typ descriptor.FieldDescriptorProto_Type, label descriptor.FieldDescriptorProto_Label, typeName string, opts map[*proto.ExtensionDesc]interface{}) *descriptor.FieldDescriptorProto {
+		fdp := newField("field", typ, opts)
+		fdp.Label, fdp.TypeName = &label, sp(typeName)
+
+		return fdp
+	}
+
+	entry := &descriptor.DescriptorProto{
+		Name: sp("CountsEntry"),
+		Field: []*descriptor.FieldDescriptorProto{
+			{Name: sp("key"), Type: &str},
+			{Name: sp("value"), Type: &i64},
+		},
+		Options: &descriptor.MessageOptions{MapEntry: bp(true)},
+	}
+
+	It("fileMapEntries", func() {
+		Expect(fileMapEntries(&descriptor.FileDescriptorProto{
+			Package: sp("pb"),
+			MessageType: []*descriptor.DescriptorProto{{
+				Name:       sp("Item"),
+				NestedType: []*descriptor.DescriptorProto{entry, {Name: sp("Other")}},
+			}},
+		})).To(Equal(map[string]*descriptor.DescriptorProto{".pb.Item.CountsEntry": entry}))
+	})
+
+	DescribeTable("castTypeName",
+		func(name, expectedType, expectedImport string) {
+			t, imp := castTypeName(name, fileInfo{protoPackage: "pb"})
+			Expect(t).To(Equal(expectedType))
+			Expect(imp).To(Equal(expectedImport))
+		},
+
+		Entry("Type from proto package", "Kind", "pb.Kind", ""),
+		Entry("Type from other package", "github.com/example/types.Count", "types.Count", `"github.com/example/types"`),
+		Entry("Package with version", "github.com/gofrs/uuid/v5.UUID", "uuid.UUID", `"github.com/gofrs/uuid/v5"`),
+	)
+
+	DescribeTable("typeZero",
+		func(t, expected string) {
+			Expect(typeZero(t)).To(Equal(expected))
+		},
+
+		Entry("Time", "time.Time", "time.Time{}"),
+		Entry("Duration", "time.Duration", "0"),
+		Entry("String", "string", `""`),
+		Entry("Number", "int64", "0"),
+		Entry("Other type", "types.Ref", "*new(types.Ref)"),
+	)
+
+	DescribeTable("protoGoType",
+		func(fdp *descriptor.FieldDescriptorProto, fi fileInfo, expected gogoType, ok bool) {
+			fi.protoPackage = "pb"
+			fi.mapEntries = map[string]*descriptor.DescriptorProto{".pb.Item.CountsEntry": entry}
+
+			gt, isGogo := protoGoType(fdp, fi)
+			Expect(isGogo).To(Equal(ok))
+			Expect(gt).To(Equal(expected))
+		},
+
+		Entry("No options", field(i64, opt, "", nil), fileInfo{}, gogoType{}, false),
+		Entry("Message", field(msg, opt, ".pb.Other", nil), fileInfo{}, gogoType{}, false),
+		Entry("stdtime",
+			field(msg, opt, ".google.protobuf.Timestamp", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Stdtime: bp(true)}), fileInfo{},
+			gogoType{goType: "time.Time", pointer: true, imports: []string{`"time"`}}, true),
+		Entry("Not nullable stdduration",
+			field(msg, opt, ".google.protobuf.Duration", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Stdduration: bp(true), gogoproto.E_Nullable: bp(false)}), fileInfo{},
+			gogoType{goType: "time.Duration", imports: []string{`"time"`}}, true),
+		Entry("Repeated stdtime",
+			field(msg, rep, ".google.protobuf.Timestamp", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Stdtime: bp(true)}), fileInfo{},
+			gogoType{goType: "time.Time", pointer: true, slice: true, imports: []string{`"time"`}}, true),
+		Entry("customtype",
+			field(str, opt, "", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Customtype: sp("github.com/example/types.Ref")}), fileInfo{},
+			gogoType{goType: "types.Ref", pointer: true, imports: []string{`"github.com/example/types"`}}, true),
+		Entry("Repeated customtype",
+			field(str, rep, "", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Customtype: sp("Ref")}), fileInfo{},
+			gogoType{goType: "pb.Ref", slice: true}, true),
+		Entry("casttype",
+			field(i64, opt, "", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Casttype: sp("Count")}), fileInfo{},
+			gogoType{goType: "pb.Count", underlying: "int64"}, true),
+		Entry("casttype of proto2 field",
+			field(i64, opt, "", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Casttype: sp("Count")}), fileInfo{proto2: true},
+			gogoType{goType: "pb.Count", pointer: true, underlying: "int64"}, true),
+		Entry("Map without cast options",
+			field(msg, rep, ".pb.Item.CountsEntry", nil), fileInfo{},
+			gogoType{}, false),
+		Entry("Map with castvalue",
+			field(msg, rep, ".pb.Item.CountsEntry", map[*proto.ExtensionDesc]interface{}{gogoproto.E_Castvalue: sp("github.com/example/types.Count")}), fileInfo{},
+			gogoType{goType: "map[string]types.Count", isMap: true, imports: []string{`"github.com/example/types"`}}, true),
+	)
+
+	Describe("gogoTypedField", func() {
+		timeType := gogoType{goType: "time.Time", pointer: true, imports: []string{`"time"`}}
+		count := gogoType{goType: "types.Count", underlying: "int64"}
+
+		It("assigns fields of the same type", func() {
+			f, err := gogoTypedField("Updated", "Updated", timeType, source.FieldInfo{Type: "time.Time", IsPointer: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*f).To(Equal(Field{Name: "Updated", ProtoName: "Updated", GoIsPointer: true, ProtoIsPointer: true}))
+		})
+
+		It("assigns slices of values of the same type", func() {
+			gt := gogoType{goType: "types.Ref", slice: true}
+			f, err := gogoTypedField("Refs", "Refs", gt, source.FieldInfo{Type: "Ref", IsSlice: true, PkgPath: "github.com/example/types"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.ProtoToGoType).To(BeEmpty())
+			Expect(f.ProtoToGoExpr).To(BeEmpty())
+		})
+
+		It("dereferences pointers", func() {
+			f, err := gogoTypedField("Updated", "Updated", timeType, source.FieldInfo{Type: "time.Time"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.ProtoToGoExpr).To(Equal("func(in *time.Time) time.Time {\n\tif in == nil {\n\t\treturn time.Time{}\n\t}\n\tv := *in\n\treturn v\n}(src.Updated)"))
+			Expect(f.GoToProtoExpr).To(Equal("func(in time.Time) *time.Time {\n\tv := in\n\tr := v\n\treturn &r\n}(src.Updated)"))
+			Expect(f.Imports).To(Equal([]string{`"time"`}))
+		})
+
+		It("converts cast types through underlying types", func() {
+			f, err := gogoTypedField("Count", "Count", count, source.FieldInfo{Type: "int"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.ProtoToGoExpr).To(Equal("func(in types.Count) int {\n\tv := in\n\treturn int(int64(v))\n}(src.Count)"))
+			Expect(f.GoToProtoExpr).To(Equal("func(in int) types.Count {\n\tv := in\n\treturn types.Count(int64(v))\n}(src.Count)"))
+		})
+
+		It("uses helper functions for other types", func() {
+			f, err := gogoTypedField("Count", "Count", count, source.FieldInfo{Type: "decimal.Decimal", IsPointer: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.convertFunc(false)).To(Equal("TypesCountToDecimalDecimalValPtr"))
+			Expect(f.convertFunc(true)).To(Equal("DecimalDecimalToTypesCountPtrVal"))
+		})
+
+		It("returns error for maps of different types", func() {
+			gt := gogoType{goType: "map[string]int64", isMap: true}
+			f, err := gogoTypedField("Counts", "Counts", gt, source.FieldInfo{Type: "map[string]int"})
+			Expect(err.Error()).To(Equal(pkgerrors.Wrap(errors.New("map[string]int64 can not be converted into map[string]int"), "Counts").Error()))
+			Expect(f).To(BeNil())
+		})
+	})
+})
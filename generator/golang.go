@@ -0,0 +1,159 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	gogen "github.com/gogo/protobuf/protoc-gen-gogo/generator"
+)
+
+// Structures generated by protoc-gen-go (google.golang.org/protobuf) contain
+// internal state which must not be copied, so in runtime "golang" messages are
+// passed by pointers only and their fields are read by getters.
+
+const (
+	// Value of runtime parameter for structures generated by protoc-gen-go.
+	runtimeGolang = "golang"
+
+	// Import spec for package with HasExtension, GetExtension and
+	// SetExtension functions of google.golang.org/protobuf.
+	golangProtoImport = `"google.golang.org/protobuf/proto"`
+
+	// Field number of proto3_optional field in FieldDescriptorProto, which is
+	// missing in gogo/protobuf descriptors.
+	proto3OptionalField = 17
+)
+
+var (
+	// golangTypes replaces types of gogo/protobuf/types package with types of
+	// google.golang.org/protobuf well-known types packages.
+	golangTypes = strings.NewReplacer(
+		"types.Timestamp", "timestamppb.Timestamp",
+		"types.StringValue", "wrapperspb.StringValue",
+		"types.BytesValue", "wrapperspb.BytesValue",
+		"types.BoolValue", "wrapperspb.BoolValue",
+		"types.Int32Value", "wrapperspb.Int32Value",
+		"types.Int64Value", "wrapperspb.Int64Value",
+		"types.UInt32Value", "wrapperspb.UInt32Value",
+		"types.UInt64Value", "wrapperspb.UInt64Value",
+		"types.FloatValue", "wrapperspb.FloatValue",
+		"types.DoubleValue", "wrapperspb.DoubleValue",
+	)
+
+	// golangTypesImports contains import specs of well-known types packages,
+	// map key is a package name.
+	golangTypesImports = map[string]string{
+		"timestamppb": `"google.golang.org/protobuf/types/known/timestamppb"`,
+		"wrapperspb":  `"google.golang.org/protobuf/types/known/wrapperspb"`,
+	}
+)
+
+// isProto3Optional returns true if field fdp is declared with optional label
+// in proto3 file.
+func isProto3Optional(fdp *descriptor.FieldDescriptorProto) bool {
+	optional := false
+	rangeFields(fdp.XXX_unrecognized, func(num int32, v uint64, _ []byte) {
+		if num == proto3OptionalField {
+			optional = v != 0
+		}
+	})
+
+	return optional
+}
+
+// isOneofMember returns true if field fdp belongs to oneof declared in .proto
+// file. Proto3 optional fields belong to synthetic oneofs, which are not
+// generated.
+func isOneofMember(fdp *descriptor.FieldDescriptorProto) bool {
+	return fdp.OneofIndex != nil && !isProto3Optional(fdp)
+}
+
+// nullable returns true if message field fdp is a pointer. Fields of
+// protoc-gen-go structures are always pointers, gogoproto.nullable option is
+// ignored.
+func (fi fileInfo) nullable(fdp *descriptor.FieldDescriptorProto) bool {
+	return fi.golang || extractNullOption(fdp)
+}
+
+// golangField adapts field f for structures generated by protoc-gen-go. Types
+// of gogo/protobuf/types package are replaced with well-known types of
+// google.golang.org/protobuf and proto fields are read by getters, except
+// pointer scalars which keep nil values.
+func golangField(f *Field, fdp *descriptor.FieldDescriptorProto, fi fileInfo) {
+	var imports []string
+	for _, i := range f.Imports {
+		if i != gogoTypesImport {
+			imports = append(imports, i)
+			continue
+		}
+
+		f.ProtoToGoExpr = golangTypes.Replace(f.ProtoToGoExpr)
+		f.GoToProtoExpr = golangTypes.Replace(f.GoToProtoExpr)
+		for pkg, spec := range golangTypesImports {
+			if strings.Contains(f.ProtoToGoExpr, pkg+".") {
+				imports = append(imports, spec)
+			}
+		}
+	}
+	f.Imports = imports
+
+	if fdp.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE && isPointerScalar(fdp, fi) {
+		return
+	}
+
+	f.Getter = true
+	arg := "(src." + f.ProtoName + ")"
+	if strings.HasSuffix(f.ProtoToGoExpr, arg) {
+		f.ProtoToGoExpr = strings.TrimSuffix(f.ProtoToGoExpr, arg) + "(src.Get" + f.ProtoName + "())"
+	}
+}
+
+// golangOneofField sets oneof attributes of field f if proto field fdp is a
+// member of oneof of message msg. Such fields are set via wrapper types, e.g.
+// pb.Product_Sku for field sku of oneof code in message Product.
+func golangOneofField(f *Field, fdp *descriptor.FieldDescriptorProto, msg *descriptor.DescriptorProto, fi fileInfo) {
+	if !isOneofMember(fdp) || int(fdp.GetOneofIndex()) >= len(msg.OneofDecl) {
+		return
+	}
+
+	f.OneofField = gogen.CamelCase(msg.OneofDecl[fdp.GetOneofIndex()].GetName())
+	f.OneofWrapper = fmt.Sprintf("%s.%s_%s", fi.protoPackage, gogen.CamelCase(msg.GetName()), f.ProtoName)
+
+	// Oneof members are neither repeated nor pointer scalars.
+	cond, _ := presenceCond(fdp, fi)
+	f.OneofCond = fmt.Sprintf(cond, "v")
+}
+
+// formatGolangOneofField returns statement which sets oneof field of proto
+// message if converted value of oneof member is not a zero value. If several
+// members have values, the last one is set.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatGolangOneofField(f Field, swapped bool) string {
+	if f.OneofWrapper == "" || !swapped {
+		return ""
+	}
+
+	return fmt.Sprintf("\tif v := %s; %s {\n\t\ts.%s = &%s{%s: v}\n\t}\n",
+		formatComplexField(f, true), f.OneofCond, f.OneofField, f.OneofWrapper, f.ProtoName)
+}
+
+// formatGolangExtensionField returns statements which copy value of extension
+// field from protoc-gen-go message or into it, see formatExtensionField.
+// Extension values are not pointers, so values converted from model are
+// always set.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatGolangExtensionField(f Field, swapped bool) string {
+	if f.Extension == "" {
+		return ""
+	}
+
+	if swapped {
+		return fmt.Sprintf("\tproto.SetExtension(s, %s, %s)\n", f.Extension, formatComplexField(f, true))
+	}
+
+	return fmt.Sprintf("\tif proto.HasExtension(src, %s) {\n\t\tsrc := struct{ %s %s }{proto.GetExtension(src, %s).(%s)}\n\t\ts.%s = %s\n\t}\n",
+		f.Extension, f.ProtoName, f.ExtensionType, f.Extension, f.ExtensionType, f.Name, formatComplexField(f, false))
+}
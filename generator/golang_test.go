@@ -0,0 +1,261 @@
+package generator
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Golang", func() {
+	var (
+		i32 = descriptor.FieldDescriptorProto_TYPE_INT32
+		str = descriptor.FieldDescriptorProto_TYPE_STRING
+		msg = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+		opt = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+
+		optional = varintField(proto3OptionalField, 1)
+		golang   = fileInfo{protoPackage: "pb", golang: true}
+	)
+
+	// field returns proto field, oneof index is set if index isn't negative.
+	field := func(name string, typ descriptor.FieldDescriptorProto_Type, index int32, unrecognized []byte) *descriptor.FieldDescriptorProto {
+		fdp := newField(name, typ, nil)
+		fdp.Label = &opt
+		fdp.XXX_unrecognized = unrecognized
+		if index >= 0 {
+			fdp.OneofIndex = &index
+		}
+
+		return fdp
+	}
+
+	DescribeTable("isProto3Optional and isOneofMember",
+		func(fdp *descriptor.FieldDescriptorProto, isOptional, isMember bool) {
+			Expect(isProto3Optional(fdp)).To(Equal(isOptional))
+			Expect(isOneofMember(fdp)).To(Equal(isMember))
+		},
+
+		Entry("Regular field", field("name", str, -1, nil), false, false),
+		Entry("Optional field", field("name", str, 0, optional), true, false),
+		Entry("Oneof member", field("name", str, 0, nil), false, true),
+	)
+
+	DescribeTable("isPointerScalar",
+		func(fdp *descriptor.FieldDescriptorProto, fi fileInfo, expected bool) {
+			Expect(isPointerScalar(fdp, fi)).To(Equal(expected))
+		},
+
+		Entry("Proto3 field", field("name", str, -1, nil), golang, false),
+		Entry("Proto3 optional field", field("name", str, 0, optional), golang, true),
+		Entry("Proto2 field", field("name", str, -1, nil), fileInfo{golang: true, proto2: true}, true),
+		Entry("Proto2 oneof member", field("name", str, 0, nil), fileInfo{golang: true, proto2: true}, false),
+	)
+
+	It("protoGoType ignores gogoproto options", func() {
+		fdp := newField("count", i32, map[*proto.ExtensionDesc]interface{}{gogoproto.E_Casttype: "Count"})
+
+		_, isGogo := protoGoType(fdp, golang)
+		Expect(isGogo).To(BeFalse())
+	})
+
+	Describe("golangField", func() {
+		It("replaces gogo/protobuf types", func() {
+			f, _ := nullableMessageField("Note", "Note", ".google.protobuf.StringValue", source.FieldInfo{Type: "sql.NullString", PkgPath: "database/sql"}, true)
+			golangField(f, field("note", msg, -1, nil), golang)
+
+			Expect(f.ProtoToGoExpr).To(HavePrefix("func(in *wrapperspb.StringValue) sql.NullString {"))
+			Expect(f.ProtoToGoExpr).To(HaveSuffix("}(src.GetNote())"))
+			Expect(f.GoToProtoExpr).To(ContainSubstring("return &wrapperspb.StringValue{Value: v.String}"))
+			Expect(f.Imports).To(ConsistOf(`"database/sql"`, golangTypesImports["wrapperspb"]))
+			Expect(f.Getter).To(BeTrue())
+		})
+
+		It("keeps pointer scalars", func() {
+			f := &Field{Name: "Name", ProtoName: "Name", ProtoToGoExpr: "func(in *string) string {}(src.Name)"}
+			golangField(f, field("name", str, 0, optional), golang)
+
+			Expect(f.ProtoToGoExpr).To(Equal("func(in *string) string {}(src.Name)"))
+			Expect(f.Getter).To(BeFalse())
+		})
+	})
+
+	DescribeTable("golangOneofField",
+		func(fdp *descriptor.FieldDescriptorProto, expected Field) {
+			m := &descriptor.DescriptorProto{
+				Name:      sp("product"),
+				OneofDecl: []*descriptor.OneofDescriptorProto{{Name: sp("code_value")}},
+			}
+
+			f := Field{ProtoName: "Sku"}
+			golangOneofField(&f, fdp, m, golang)
+			Expect(f).To(Equal(expected))
+		},
+
+		Entry("Regular field", field("sku", str, -1, nil), Field{ProtoName: "Sku"}),
+		Entry("Optional field", field("sku", str, 0, optional), Field{ProtoName: "Sku"}),
+		Entry("String", field("sku", str, 0, nil), Field{ProtoName: "Sku", OneofField: "CodeValue", OneofWrapper: "pb.Product_Sku", OneofCond: `v != ""`}),
+		Entry("Number", field("sku", i32, 0, nil), Field{ProtoName: "Sku", OneofField: "CodeValue", OneofWrapper: "pb.Product_Sku", OneofCond: "v != 0"}),
+		Entry("Message", field("sku", msg, 0, nil), Field{ProtoName: "Sku", OneofField: "CodeValue", OneofWrapper: "pb.Product_Sku", OneofCond: "v != nil"}),
+	)
+
+	DescribeTable("formatGolangOneofField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatGolangOneofField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Not oneof", Field{Name: "SKU", ProtoName: "Sku"}, true, ""),
+		Entry("Proto to Go", Field{Name: "SKU", ProtoName: "Sku", OneofWrapper: "pb.Product_Sku"}, false, ""),
+		Entry("Go to proto", Field{Name: "SKU", ProtoName: "Sku", OneofField: "Code", OneofWrapper: "pb.Product_Sku", OneofCond: `v != ""`}, true, `	if v := src.SKU; v != "" {
+		s.Code = &pb.Product_Sku{Sku: v}
+	}
+`),
+	)
+
+	DescribeTable("formatGolangExtensionField",
+		func(swapped bool, expected string) {
+			f := Field{Name: "Code", ProtoName: "LegacyCode", Extension: "pb.E_LegacyCode", ExtensionType: "string"}
+			Expect(formatGolangExtensionField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Proto to Go", false, `	if proto.HasExtension(src, pb.E_LegacyCode) {
+		src := struct{ LegacyCode string }{proto.GetExtension(src, pb.E_LegacyCode).(string)}
+		s.Code = src.LegacyCode
+	}
+`),
+		Entry("Go to proto", true, "\tproto.SetExtension(s, pb.E_LegacyCode, src.Code)\n"),
+	)
+
+	It("processExtension returns value extension type", func() {
+		fdp := newField("legacy_code", str, map[*proto.ExtensionDesc]interface{}{options.E_MapTo: "StringField"})
+
+		f, err := processExtension(nil, extension{fdp: fdp, varName: "E_LegacyCode"}, goStruct, golang)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.ExtensionType).To(Equal("string"))
+		Expect(f.Imports).To(Equal([]string{golangProtoImport}))
+	})
+
+	Describe("templates", func() {
+		var w *bytes.Buffer
+
+		BeforeEach(func() {
+			w = bytes.NewBuffer([]byte{})
+		})
+
+		d := Data{
+			Src:        "Product",
+			SrcPref:    "pb",
+			SrcFn:      "Pb",
+			SrcPointer: "*",
+			Dst:        "Product",
+			DstPref:    "model",
+			DstFn:      "Product",
+			Golang:     true,
+			Fields: []Field{
+				{Name: "Title", ProtoName: "Title", Getter: true},
+				{Name: "SKU", ProtoName: "Sku", Getter: true, OneofField: "Code", OneofWrapper: "pb.Product_Sku", OneofCond: `v != ""`},
+			},
+		}
+		swapped := d
+		swapped.swap()
+
+		DescribeTable("golangPtr",
+			func(d Data, expected string) {
+				Expect(golangPtrT.Execute(w, d)).To(Succeed())
+				Expect(w.String()).To(Equal(expected))
+			},
+
+			Entry("Proto to Go", d, `func PbToProductPtr(src *pb.Product, opts ...TransformParam) *model.Product {
+	if src == nil {
+		return nil
+	}
+
+	d := PbToProductPtrVal(src, opts...)
+	return &d
+}`),
+			Entry("Go to proto", swapped, `func ProductToPbPtr(src *model.Product, opts ...TransformParam) *pb.Product {
+	if src == nil {
+		return nil
+	}
+
+	return ProductToPbValPtr(*src, opts...)
+}`),
+		)
+
+		DescribeTable("golangVal",
+			func(d Data, expected string) {
+				Expect(golangValT.Execute(w, d)).To(Succeed())
+				Expect(w.String()).To(Equal(expected))
+			},
+
+			Entry("Proto to Go", d, `func PbToProductPtrVal(src *pb.Product, opts ...TransformParam) model.Product {
+	if src == nil {
+		return model.Product{}
+	}
+
+	opts, ok := nestOptions(opts)
+	if !ok {
+		return model.Product{}
+	}
+
+	s := model.Product{
+			Title: src.GetTitle(),
+			SKU: src.GetSku(),
+	}
+
+	applyOptions(opts...)
+
+
+
+	return s
+}`),
+			Entry("Go to proto", swapped, "func ProductToPbValPtr(src model.Product, opts ...TransformParam) *pb.Product {\n"+
+				"\topts, ok := nestOptions(opts)\n\tif !ok {\n\t\treturn &pb.Product{}\n\t}\n\n"+
+				"\ts := &pb.Product{\n\t\t\tTitle: src.Title,\n\t\t\t\n\t}\n\n\tapplyOptions(opts...)\n\n\n"+
+				"\tif v := src.SKU; v != \"\" {\n\t\ts.Code = &pb.Product_Sku{Sku: v}\n\t}\n\n\treturn s\n}"),
+		)
+
+		DescribeTable("golangValList",
+			func(d Data, expected string) {
+				Expect(golangValListT.Execute(w, d)).To(Succeed())
+				Expect(w.String()).To(Equal(expected))
+			},
+
+			Entry("Proto to Go", d, `func PbToProductPtrValList(src []*pb.Product, opts ...TransformParam) []model.Product {
+	resp := make([]model.Product, len(src))
+
+	for i, s := range src {
+		resp[i] = PbToProductPtrVal(s, opts...)
+	}
+
+	return resp
+}`),
+			Entry("Go to proto", swapped, `func ProductToPbValPtrList(src []model.Product, opts ...TransformParam) []*pb.Product {
+	resp := make([]*pb.Product, len(src))
+
+	for i, s := range src {
+		resp[i] = ProductToPbValPtr(s, opts...)
+	}
+
+	return resp
+}`),
+		)
+
+		It("templateWithHelpers generates functions without proto values", func() {
+			t, err := templateWithHelpers("golang", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(t.Execute(w, d)).To(Succeed())
+
+			out := w.String()
+			Expect(out).To(ContainSubstring("func PbToProductPtrList(src []*pb.Product"))
+			Expect(out).To(ContainSubstring("func PbToProductList(src []*pb.Product"))
+			Expect(out).NotTo(ContainSubstring("src pb.Product"))
+		})
+	})
+})
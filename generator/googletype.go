@@ -0,0 +1,372 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+// googleTypeConv describes conversion between one of google.type types and
+// Go type.
+type googleTypeConv struct {
+	// Go type of model field, if it's empty map key of googleType.convs is
+	// used.
+	goType string
+	// Zero value of Go type.
+	goZero string
+	// Steps and result expression for proto to Go conversion.
+	toGoSteps []step
+	toGo      string
+	// Steps and result expression for Go to proto conversion.
+	toPbSteps []step
+	toPb      string
+	// If true, Go type is always used as a pointer, e.g. *big.Rat. Result of
+	// proto to Go conversion is a pointer and argument of Go to proto conversion
+	// is not dereferenced.
+	pointer bool
+	// Import specs which are required by conversion.
+	imports []string
+	// If true, errors are not passed to error handler.
+	silent bool
+}
+
+// googleType contains information about one of google.type types.
+type googleType struct {
+	// Go type of proto message or enum.
+	pbType string
+	// Zero value of proto type if it's not a pointer.
+	pbZero string
+	// True if type is an enum, enums are never nil.
+	enum bool
+	// True if type is a scalar, e.g. string. Pointer to scalar value is
+	// returned via variable, not via address of composite literal.
+	scalar bool
+	// Import specs which are required by proto type.
+	imports []string
+	// Conversions where map key is a Go type name from model.
+	convs map[string]googleTypeConv
+}
+
+// unspecifiedWeekday is a model value of DAY_OF_WEEK_UNSPECIFIED, it's not
+// equal to any day, so unset proto fields are not converted into Sunday.
+const unspecifiedWeekday = "time.Weekday(-1)"
+
+var (
+	dateSteps = []step{
+		{cond: "v.Year == 0 && v.Month == 0 && v.Day == 0"},
+		{cond: "v.Year < 0 || v.Year > 9999", format: "year %d is out of range", args: []string{"v.Year"}, validation: true},
+		{cond: "v.Month < 1 || v.Month > 12", format: "month %d is out of range", args: []string{"v.Month"}, validation: true},
+		{cond: "v.Day < 1 || int(v.Day) > time.Date(int(v.Year), time.Month(v.Month)+1, 0, 0, 0, 0, 0, time.UTC).Day()", format: "day %d is out of range", args: []string{"v.Day"}, validation: true},
+	}
+
+	timeOfDaySteps = []step{
+		{cond: "v.Hours < 0 || v.Hours > 24 || v.Hours == 24 && (v.Minutes != 0 || v.Seconds != 0 || v.Nanos != 0)", format: "hours %d is out of range", args: []string{"v.Hours"}, validation: true},
+		{cond: "v.Minutes < 0 || v.Minutes > 59", format: "minutes %d is out of range", args: []string{"v.Minutes"}, validation: true},
+		{cond: "v.Seconds < 0 || v.Seconds > 60", format: "seconds %d is out of range", args: []string{"v.Seconds"}, validation: true},
+		{cond: "v.Nanos < 0 || v.Nanos > 999999999", format: "nanos %d is out of range", args: []string{"v.Nanos"}, validation: true},
+	}
+
+	latLngSteps = []step{
+		{cond: "v.Latitude < -90 || v.Latitude > 90", format: "latitude %v is out of range", args: []string{"v.Latitude"}, validation: true},
+		{cond: "v.Longitude < -180 || v.Longitude > 180", format: "longitude %v is out of range", args: []string{"v.Longitude"}, validation: true},
+	}
+
+	// DAY_OF_WEEK_UNSPECIFIED has no time.Weekday value, it's converted into
+	// unspecifiedWeekday and back, see googleTypes.
+	dayOfWeekSteps = []step{
+		{cond: "v == 0", format: "day of week is unspecified", validation: true},
+		{cond: "v == 0"},
+		{cond: "v < 1 || v > 7", format: "day of week %d is out of range", args: []string{"v"}, validation: true},
+	}
+
+	monthSteps = []step{
+		{cond: "v < 1 || v > 12", format: "month %d is out of range", args: []string{"v"}, validation: true},
+	}
+
+	// googleTypes contains conversions for types from google.type package.
+	// Map key is a full proto type name.
+	googleTypes = map[string]googleType{
+		".google.type.Date": {
+			pbType: "date.Date",
+			pbZero: "date.Date{}",
+			convs: map[string]googleTypeConv{
+				"civil.Date": {
+					goZero:    "civil.Date{}",
+					toGoSteps: dateSteps,
+					toGo:      "civil.Date{Year: int(v.Year), Month: time.Month(v.Month), Day: int(v.Day)}",
+					toPbSteps: []step{
+						{cond: "v.IsZero()"},
+						{cond: "!v.IsValid()", format: "invalid date %v", args: []string{"v"}, validation: true},
+					},
+					toPb: "date.Date{Year: int32(v.Year), Month: int32(v.Month), Day: int32(v.Day)}",
+				},
+				"time.Time": {
+					goZero:    "time.Time{}",
+					toGoSteps: dateSteps,
+					toGo:      "time.Date(int(v.Year), time.Month(v.Month), int(v.Day), 0, 0, 0, 0, time.UTC)",
+					toPbSteps: []step{{cond: "v.IsZero()"}},
+					toPb:      "date.Date{Year: int32(v.Year()), Month: int32(v.Month()), Day: int32(v.Day())}",
+				},
+				"string": {
+					goZero:    `""`,
+					toGoSteps: dateSteps,
+					toGo:      `fmt.Sprintf("%04d-%02d-%02d", v.Year, v.Month, v.Day)`,
+					toPbSteps: []step{
+						{cond: `v == ""`},
+						{stmt: `t, err := time.Parse("2006-01-02", v)`},
+						{cond: "err != nil", format: "%v", args: []string{"err"}},
+					},
+					toPb: "date.Date{Year: int32(t.Year()), Month: int32(t.Month()), Day: int32(t.Day())}",
+				},
+			},
+		},
+
+		".google.type.TimeOfDay": {
+			pbType: "timeofday.TimeOfDay",
+			pbZero: "timeofday.TimeOfDay{}",
+			convs: map[string]googleTypeConv{
+				"civil.Time": {
+					goZero:    "civil.Time{}",
+					toGoSteps: timeOfDaySteps,
+					toGo:      "civil.Time{Hour: int(v.Hours), Minute: int(v.Minutes), Second: int(v.Seconds), Nanosecond: int(v.Nanos)}",
+					toPbSteps: []step{
+						{cond: "!v.IsValid()", format: "invalid time %v", args: []string{"v"}, validation: true},
+					},
+					toPb: "timeofday.TimeOfDay{Hours: int32(v.Hour), Minutes: int32(v.Minute), Seconds: int32(v.Second), Nanos: int32(v.Nanosecond)}",
+				},
+				"time.Duration": {
+					goZero:    "0",
+					toGoSteps: timeOfDaySteps,
+					toGo:      "time.Duration(v.Hours)*time.Hour + time.Duration(v.Minutes)*time.Minute + time.Duration(v.Seconds)*time.Second + time.Duration(v.Nanos)",
+					toPbSteps: []step{
+						{cond: "v < 0 || v > 24*time.Hour", format: "duration %v is out of range", args: []string{"v"}, validation: true},
+					},
+					toPb: "timeofday.TimeOfDay{Hours: int32(v / time.Hour), Minutes: int32(v % time.Hour / time.Minute), Seconds: int32(v % time.Minute / time.Second), Nanos: int32(v % time.Second)}",
+				},
+				"string": {
+					goZero:    `""`,
+					toGoSteps: timeOfDaySteps,
+					toGo:      `time.Date(0, 1, 1, int(v.Hours), int(v.Minutes), int(v.Seconds), int(v.Nanos), time.UTC).Format("15:04:05.999999999")`,
+					toPbSteps: []step{
+						{cond: `v == ""`},
+						{stmt: `t, err := time.Parse("15:04:05.999999999", v)`},
+						{cond: "err != nil", format: "%v", args: []string{"err"}},
+					},
+					toPb: "timeofday.TimeOfDay{Hours: int32(t.Hour()), Minutes: int32(t.Minute()), Seconds: int32(t.Second()), Nanos: int32(t.Nanosecond())}",
+				},
+			},
+		},
+
+		".google.type.LatLng": {
+			pbType: "latlng.LatLng",
+			pbZero: "latlng.LatLng{}",
+			// Conversions into model structures are added by latLngConv.
+			convs: map[string]googleTypeConv{},
+		},
+
+		".google.type.DayOfWeek": {
+			pbType: "dayofweek.DayOfWeek",
+			pbZero: "dayofweek.DayOfWeek_DAY_OF_WEEK_UNSPECIFIED",
+			enum:   true,
+			convs: map[string]googleTypeConv{
+				"time.Weekday": {
+					goZero:    unspecifiedWeekday,
+					toGoSteps: dayOfWeekSteps,
+					toGo:      "time.Weekday(v % 7)",
+					toPbSteps: []step{
+						{cond: "v == " + unspecifiedWeekday},
+						{cond: "v < time.Sunday || v > time.Saturday", format: "weekday %d is out of range", args: []string{"v"}, validation: true},
+					},
+					toPb: "dayofweek.DayOfWeek((v+6)%7 + 1)",
+				},
+			},
+		},
+
+		".google.type.Month": {
+			pbType: "month.Month",
+			pbZero: "month.Month_MONTH_UNSPECIFIED",
+			enum:   true,
+			convs: map[string]googleTypeConv{
+				"time.Month": {
+					goZero:    "0",
+					toGoSteps: monthSteps,
+					toGo:      "time.Month(v)",
+					toPbSteps: monthSteps,
+					toPb:      "month.Month(v)",
+				},
+				"int": {
+					goZero:    "0",
+					toGoSteps: monthSteps,
+					toGo:      "int(v)",
+					toPbSteps: monthSteps,
+					toPb:      "month.Month(v)",
+				},
+			},
+		},
+	}
+
+	// Model field names which are used for latitude and longitude.
+	latNames = []string{"Lat", "Latitude"}
+	lngNames = []string{"Lng", "Lon", "Long", "Longitude"}
+)
+
+// findFloatField returns name of first found field of type float32 or float64.
+func findFloatField(s source.Structure, names []string) (string, string) {
+	for _, n := range names {
+		if f, ok := s[n]; ok && !f.IsPointer && (f.Type == "float64" || f.Type == "float32") {
+			return n, f.Type
+		}
+	}
+
+	return "", ""
+}
+
+// latLngConv returns conversion for google.type.LatLng into model structure
+// which has latitude and longitude fields, see latNames and lngNames. The
+// second return value is false if structure has no such fields.
+func latLngConv(goType string, fi fileInfo) (googleTypeConv, bool) {
+	s, ok := fi.structs[goType]
+	if !ok {
+		return googleTypeConv{}, false
+	}
+
+	lat, latType := findFloatField(s, latNames)
+	lng, lngType := findFloatField(s, lngNames)
+	if lat == "" || lng == "" {
+		return googleTypeConv{}, false
+	}
+
+	t := fi.modelType(goType)
+
+	return googleTypeConv{
+		goType:    t,
+		goZero:    t + "{}",
+		toGoSteps: latLngSteps,
+		toGo:      fmt.Sprintf("%s{%s: %s(v.Latitude), %s: %s(v.Longitude)}", t, lat, latType, lng, lngType),
+		toPbSteps: []step{
+			{cond: fmt.Sprintf("v.%s < -90 || v.%s > 90", lat, lat), format: "latitude %v is out of range", args: []string{"v." + lat}, validation: true},
+			{cond: fmt.Sprintf("v.%s < -180 || v.%s > 180", lng, lng), format: "longitude %v is out of range", args: []string{"v." + lng}, validation: true},
+		},
+		toPb: fmt.Sprintf("latlng.LatLng{Latitude: float64(v.%s), Longitude: float64(v.%s)}", lat, lng),
+	}, true
+}
+
+// wktgoogleType returns *Field created out of field of one of google.type
+// types. Types which have no built-in conversion into model field type are
+// converted by helper functions, like google.protobuf.Timestamp fields.
+func wktgoogleType(pname, gname string, gt googleType, gf source.FieldInfo, pnullable, validate bool, fi fileInfo) *Field {
+	pnullable = pnullable && !gt.enum
+
+	conv, ok := gt.convs[gf.Type]
+	if !ok {
+		conv, ok = latLngConv(gf.Type, fi)
+	}
+
+	if !ok {
+		return helperField(pname, gname, gt, gf, pnullable)
+	}
+
+	return inlineField(pname, gname, gt, conv, gf, pnullable, validate)
+}
+
+// helperField returns *Field which is converted by helper functions, names of
+// functions are based on proto and Go types, e.g. DateToCivilDate.
+func helperField(pname, gname string, gt googleType, gf source.FieldInfo, pnullable bool) *Field {
+	p := lastName(gt.pbType)
+	if pnullable {
+		p += "Ptr"
+	}
+
+	g := strcase.ToCamel(strings.Replace(gf.Type, ".", "", -1))
+	if gf.IsPointer {
+		g += "Ptr"
+	}
+
+	return &Field{
+		Name:          gname,
+		ProtoName:     pname,
+		ProtoToGoType: fmt.Sprintf("%sTo%s", p, g),
+		GoToProtoType: fmt.Sprintf("%sTo%s", g, p),
+		UsePackage:    true,
+	}
+}
+
+// inlineField returns *Field with conversion expressions created out of conv.
+func inlineField(pname, gname string, gt googleType, conv googleTypeConv, gf source.FieldInfo, pnullable, validate bool) *Field {
+	goType := conv.goType
+	if goType == "" {
+		goType = gf.Type
+	}
+
+	toGo := inlineConv{
+		name:     pname,
+		arg:      "src." + pname,
+		in:       gt.pbType,
+		out:      goType,
+		zero:     conv.goZero,
+		nilable:  pnullable,
+		steps:    conv.toGoSteps,
+		result:   conv.toGo,
+		validate: validate,
+		silent:   conv.silent,
+	}
+
+	toPb := inlineConv{
+		name:     pname,
+		arg:      "src." + gname,
+		in:       goType,
+		out:      gt.pbType,
+		zero:     gt.pbZero,
+		nilable:  gf.IsPointer,
+		deref:    gf.IsPointer,
+		steps:    conv.toPbSteps,
+		result:   conv.toPb,
+		validate: validate,
+		silent:   conv.silent,
+	}
+
+	if pnullable {
+		toGo.in = "*" + toGo.in
+		toPb.out = "*" + toPb.out
+		toPb.zero = "nil"
+		if gt.scalar {
+			toGo.deref = true
+			toPb.outPtr = true
+		} else {
+			toPb.result = "&" + toPb.result
+		}
+	}
+
+	switch {
+	case conv.pointer:
+		toGo.out = "*" + toGo.out
+		toGo.zero = "nil"
+		toPb.in = "*" + toPb.in
+		toPb.deref = false
+	case gf.IsPointer:
+		toGo.out = "*" + toGo.out
+		toGo.zero = "nil"
+		toGo.outPtr = true
+		toPb.in = "*" + toPb.in
+	}
+
+	var imports []string
+	imports = append(imports, gt.imports...)
+	imports = append(imports, conv.imports...)
+
+	f := &Field{
+		Name:          gname,
+		ProtoName:     pname,
+		ProtoToGoExpr: toGo.String(),
+		GoToProtoExpr: toPb.String(),
+		Imports:       imports,
+	}
+
+	if gf.IsSlice {
+		f.ProtoToGoExpr, f.GoToProtoExpr = toGo.list(), toPb.list()
+	}
+
+	return f
+}
@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GoogleType", func() {
+
+	var fi = fileInfo{
+		repoPackage: "model",
+		structs: source.StructureList{
+			"Point":  {"Lat": {Type: "float64"}, "Long": {Type: "float32"}},
+			"NoGeo":  {"X": {Type: "float64"}, "Y": {Type: "float64"}},
+			"PtrGeo": {"Lat": {Type: "float64", IsPointer: true}, "Lng": {Type: "float64"}},
+		},
+	}
+
+	Describe("latLngConv", func() {
+
+		DescribeTable("check result",
+			func(goType string, ok bool, toGo, toPb string) {
+				c, found := latLngConv(goType, fi)
+				Expect(found).To(Equal(ok))
+				Expect(c.toGo).To(Equal(toGo))
+				Expect(c.toPb).To(Equal(toPb))
+			},
+
+			Entry("Structure with fields", "Point", true,
+				"model.Point{Lat: float64(v.Latitude), Long: float32(v.Longitude)}",
+				"latlng.LatLng{Latitude: float64(v.Lat), Longitude: float64(v.Long)}"),
+			Entry("Structure without fields", "NoGeo", false, "", ""),
+			Entry("Pointer fields are ignored", "PtrGeo", false, "", ""),
+			Entry("Unknown structure", "Unknown", false, "", ""),
+		)
+	})
+
+	Describe("wktgoogleType", func() {
+
+		DescribeTable("uses helper functions for unsupported types",
+			func(typeName string, gf source.FieldInfo, pnullable bool, p2g, g2p string) {
+				f := wktgoogleType("Pb", "Go", googleTypes[typeName], gf, pnullable, false, fi)
+				Expect(*f).To(Equal(Field{
+					Name:          "Go",
+					ProtoName:     "Pb",
+					ProtoToGoType: p2g,
+					GoToProtoType: g2p,
+					UsePackage:    true,
+				}))
+			},
+
+			Entry("Date", ".google.type.Date", source.FieldInfo{Type: "int64"}, false, "DateToInt64", "Int64ToDate"),
+			Entry("Nullable date", ".google.type.Date", source.FieldInfo{Type: "int64"}, true, "DatePtrToInt64", "Int64ToDatePtr"),
+			Entry("Pointer", ".google.type.TimeOfDay", source.FieldInfo{Type: "my.Time", IsPointer: true}, false, "TimeOfDayToMyTimePtr", "MyTimePtrToTimeOfDay"),
+			Entry("Enum is never nullable", ".google.type.Month", source.FieldInfo{Type: "string"}, true, "MonthToString", "StringToMonth"),
+		)
+
+		It("allows year 0 in validated dates", func() {
+			f := wktgoogleType("Pb", "Go", googleTypes[".google.type.Date"], source.FieldInfo{Type: "civil.Date"}, false, true, fi)
+			Expect(f.ProtoToGoExpr).To(ContainSubstring("if v.Year < 0 || v.Year > 9999 {"))
+		})
+
+		DescribeTable("uses inline conversions for supported types",
+			func(typeName string, gf source.FieldInfo, pnullable, validate bool, p2g, g2p string) {
+				f := wktgoogleType("Pb", "Go", googleTypes[typeName], gf, pnullable, validate, fi)
+				Expect(f.UsePackage).To(BeFalse())
+				Expect(f.ProtoToGoType).To(BeEmpty())
+				Expect(f.ProtoToGoExpr).To(Equal(p2g))
+				Expect(f.GoToProtoExpr).To(Equal(g2p))
+			},
+
+			Entry("Month", ".google.type.Month", source.FieldInfo{Type: "time.Month"}, true, false,
+				`func(in month.Month) time.Month {
+	v := in
+	return time.Month(v)
+}(src.Pb)`,
+				`func(in time.Month) month.Month {
+	v := in
+	return month.Month(v)
+}(src.Go)`),
+
+			Entry("Day of week without validation", ".google.type.DayOfWeek", source.FieldInfo{Type: "time.Weekday"}, false, false,
+				`func(in dayofweek.DayOfWeek) time.Weekday {
+	v := in
+	if v == 0 {
+		return time.Weekday(-1)
+	}
+	return time.Weekday(v % 7)
+}(src.Pb)`,
+				`func(in time.Weekday) dayofweek.DayOfWeek {
+	v := in
+	if v == time.Weekday(-1) {
+		return dayofweek.DayOfWeek_DAY_OF_WEEK_UNSPECIFIED
+	}
+	return dayofweek.DayOfWeek((v+6)%7 + 1)
+}(src.Go)`),
+
+			Entry("Day of week with validation", ".google.type.DayOfWeek", source.FieldInfo{Type: "time.Weekday"}, false, true,
+				`func(in dayofweek.DayOfWeek) time.Weekday {
+	v := in
+	if v == 0 {
+		reportError(opts, fmt.Errorf("Pb: day of week is unspecified"))
+		return time.Weekday(-1)
+	}
+	if v == 0 {
+		return time.Weekday(-1)
+	}
+	if v < 1 || v > 7 {
+		reportError(opts, fmt.Errorf("Pb: day of week %d is out of range", v))
+		return time.Weekday(-1)
+	}
+	return time.Weekday(v % 7)
+}(src.Pb)`,
+				`func(in time.Weekday) dayofweek.DayOfWeek {
+	v := in
+	if v == time.Weekday(-1) {
+		return dayofweek.DayOfWeek_DAY_OF_WEEK_UNSPECIFIED
+	}
+	if v < time.Sunday || v > time.Saturday {
+		reportError(opts, fmt.Errorf("Pb: weekday %d is out of range", v))
+		return dayofweek.DayOfWeek_DAY_OF_WEEK_UNSPECIFIED
+	}
+	return dayofweek.DayOfWeek((v+6)%7 + 1)
+}(src.Go)`),
+
+			Entry("Day of week into pointer", ".google.type.DayOfWeek", source.FieldInfo{Type: "time.Weekday", IsPointer: true}, false, false,
+				`func(in dayofweek.DayOfWeek) *time.Weekday {
+	v := in
+	if v == 0 {
+		return nil
+	}
+	r := time.Weekday(v % 7)
+	return &r
+}(src.Pb)`,
+				`func(in *time.Weekday) dayofweek.DayOfWeek {
+	if in == nil {
+		return dayofweek.DayOfWeek_DAY_OF_WEEK_UNSPECIFIED
+	}
+	v := *in
+	if v == time.Weekday(-1) {
+		return dayofweek.DayOfWeek_DAY_OF_WEEK_UNSPECIFIED
+	}
+	return dayofweek.DayOfWeek((v+6)%7 + 1)
+}(src.Go)`),
+
+			Entry("Nullable date into pointer", ".google.type.Date", source.FieldInfo{Type: "time.Time", IsPointer: true}, true, false,
+				`func(in *date.Date) *time.Time {
+	if in == nil {
+		return nil
+	}
+	v := in
+	if v.Year == 0 && v.Month == 0 && v.Day == 0 {
+		return nil
+	}
+	r := time.Date(int(v.Year), time.Month(v.Month), int(v.Day), 0, 0, 0, 0, time.UTC)
+	return &r
+}(src.Pb)`,
+				`func(in *time.Time) *date.Date {
+	if in == nil {
+		return nil
+	}
+	v := *in
+	if v.IsZero() {
+		return nil
+	}
+	return &date.Date{Year: int32(v.Year()), Month: int32(v.Month()), Day: int32(v.Day())}
+}(src.Go)`),
+
+			Entry("Validated LatLng", ".google.type.LatLng", source.FieldInfo{Type: "Point"}, false, true,
+				`func(in latlng.LatLng) model.Point {
+	v := in
+	if v.Latitude < -90 || v.Latitude > 90 {
+		reportError(opts, fmt.Errorf("Pb: latitude %v is out of range", v.Latitude))
+		return model.Point{}
+	}
+	if v.Longitude < -180 || v.Longitude > 180 {
+		reportError(opts, fmt.Errorf("Pb: longitude %v is out of range", v.Longitude))
+		return model.Point{}
+	}
+	return model.Point{Lat: float64(v.Latitude), Long: float32(v.Longitude)}
+}(src.Pb)`,
+				`func(in model.Point) latlng.LatLng {
+	v := in
+	if v.Lat < -90 || v.Lat > 90 {
+		reportError(opts, fmt.Errorf("Pb: latitude %v is out of range", v.Lat))
+		return latlng.LatLng{}
+	}
+	if v.Long < -180 || v.Long > 180 {
+		reportError(opts, fmt.Errorf("Pb: longitude %v is out of range", v.Long))
+		return latlng.LatLng{}
+	}
+	return latlng.LatLng{Latitude: float64(v.Lat), Longitude: float64(v.Long)}
+}(src.Go)`),
+		)
+	})
+})
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// step is one step of inline conversion.
+type step struct {
+	// Go statement, is added to function literal as is.
+	stmt string
+	// If cond is true, conversion stops and zero value is returned.
+	cond string
+	// Error message format and its arguments. If format is not empty, error is
+	// passed to error handler when cond is true.
+	format string
+	args   []string
+	// If true, step is added only for fields with transformer.validate option.
+	validation bool
+}
+
+// inlineConv contains information for generating function literal which
+// converts one value into another in place, i.e. inside composite literal of
+// destination structure. Function literal uses "in" as a name of an argument
+// and "v" as a name of value which is converted.
+type inlineConv struct {
+	// Field name which is used in error messages.
+	name string
+	// Argument of function literal, e.g. "src.Birthday".
+	arg string
+	// Types of argument and result.
+	in, out string
+	// Zero value of result type.
+	zero string
+	// If true, nil argument is converted into zero value.
+	nilable bool
+	// If true, argument is dereferenced before conversion.
+	deref bool
+	// If true, function literal returns a pointer to converted value.
+	outPtr bool
+	// Conversion steps, executed before result expression.
+	steps []step
+	// Result expression.
+	result string
+	// If true, validation steps are added.
+	validate bool
+	// If true, errors are not passed to error handler, values are just
+	// replaced with zero values.
+	silent bool
+}
+
+// failIf returns statement which reports an error if format is not empty and
+// returns zero value if cond is true.
+func failIf(name, zero string, s step) string {
+	report := ""
+	if s.format != "" {
+		args := ""
+		if len(s.args) > 0 {
+			args = ", " + strings.Join(s.args, ", ")
+		}
+		report = fmt.Sprintf("\t\treportError(opts, fmt.Errorf(\"%s: %s\"%s))\n", name, s.format, args)
+	}
+
+	return fmt.Sprintf("\tif %s {\n%s\t\treturn %s\n\t}\n", s.cond, report, zero)
+}
+
+// String returns function literal with call.
+func (c inlineConv) String() string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "func(in %s) %s {\n", c.in, c.out)
+
+	if c.nilable {
+		fmt.Fprintf(b, "\tif in == nil {\n\t\treturn %s\n\t}\n", c.zero)
+	}
+
+	if c.deref {
+		b.WriteString("\tv := *in\n")
+	} else {
+		b.WriteString("\tv := in\n")
+	}
+
+	for _, s := range c.steps {
+		if s.validation && !c.validate {
+			continue
+		}
+
+		if s.stmt != "" {
+			fmt.Fprintf(b, "\t%s\n", s.stmt)
+		}
+
+		if s.cond != "" {
+			if c.silent {
+				s.format = ""
+			}
+			b.WriteString(failIf(c.name, c.zero, s))
+		}
+	}
+
+	if c.outPtr {
+		fmt.Fprintf(b, "\tr := %s\n\treturn &r\n", c.result)
+	} else {
+		fmt.Fprintf(b, "\treturn %s\n", c.result)
+	}
+
+	fmt.Fprintf(b, "}(%s)", c.arg)
+
+	return b.String()
+}
+
+// list returns function literal which converts slice of values, each element
+// is converted by function literal c.
+func (c inlineConv) list() string {
+	arg := c.arg
+	c.arg = "e"
+	elem := strings.Replace(c.String(), "\n", "\n\t\t", -1)
+
+	return fmt.Sprintf("func(in []%s) []%s {\n\tif in == nil {\n\t\treturn nil\n\t}\n\tr := make([]%s, len(in))\n\tfor i, e := range in {\n\t\tr[i] = %s\n\t}\n\treturn r\n}(%s)",
+		c.in, c.out, c.out, elem, arg)
+}
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inline", func() {
+
+	Describe("failIf", func() {
+
+		DescribeTable("check result",
+			func(s step, expected string) {
+				Expect(failIf("Field", "0", s)).To(Equal(expected))
+			},
+
+			Entry("Without error", step{cond: "v == 0"}, "\tif v == 0 {\n\t\treturn 0\n\t}\n"),
+
+			Entry("With error", step{cond: "v < 0", format: "value %d is negative", args: []string{"v"}},
+				"\tif v < 0 {\n\t\treportError(opts, fmt.Errorf(\"Field: value %d is negative\", v))\n\t\treturn 0\n\t}\n"),
+		)
+	})
+
+	Describe("inlineConv.String", func() {
+		var c inlineConv
+
+		BeforeEach(func() {
+			c = inlineConv{
+				name:   "Month",
+				arg:    "src.Month",
+				in:     "month.Month",
+				out:    "int",
+				zero:   "0",
+				steps:  monthSteps,
+				result: "int(v)",
+			}
+		})
+
+		It("skips validation steps", func() {
+			Expect(c.String()).To(Equal(`func(in month.Month) int {
+	v := in
+	return int(v)
+}(src.Month)`))
+		})
+
+		It("adds validation steps", func() {
+			c.validate = true
+			Expect(c.String()).To(Equal(`func(in month.Month) int {
+	v := in
+	if v < 1 || v > 12 {
+		reportError(opts, fmt.Errorf("Month: month %d is out of range", v))
+		return 0
+	}
+	return int(v)
+}(src.Month)`))
+		})
+
+		It("handles pointers", func() {
+			c.in, c.out, c.zero = "*month.Month", "*int", "nil"
+			c.nilable, c.deref, c.outPtr = true, true, true
+			Expect(c.String()).To(Equal(`func(in *month.Month) *int {
+	if in == nil {
+		return nil
+	}
+	v := *in
+	r := int(v)
+	return &r
+}(src.Month)`))
+		})
+	})
+})
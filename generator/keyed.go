@@ -0,0 +1,364 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/iancoleman/strcase"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+var (
+	errKeyedKey      = errors.New("map_key option is required for repeated messages which are transformed into model maps")
+	errKeyedType     = errors.New("model field should be a map with message values or a set like map[string]struct{}")
+	errKeyedNotFound = errors.New("message of keyed field should be declared in the same file")
+	errKeyedKeyField = errors.New("key field should be a scalar or enum field of message")
+	errKeyedOrder    = errors.New("map keys should be ordered types like strings or numbers, they are sorted in model to proto conversion")
+	errKeyedConvert  = errors.New("model key type can not be converted from proto key type, numbers and enums are converted into numbers, strings into strings")
+)
+
+// orderedTypes contains predeclared Go types which can be compared by <
+// operator. Named model types are converted from proto keys, so they are
+// ordered if proto keys are ordered.
+var orderedTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"byte": true, "rune": true, "float32": true, "float64": true, "string": true,
+}
+
+// orderedKey returns true if model key type keyType which is converted from
+// proto key type protoKey can be sorted by < operator. Bool keys are not
+// ordered.
+func orderedKey(keyType, protoKey string) bool {
+	if protoKey == "bool" {
+		return false
+	}
+
+	return orderedTypes[keyType] || ast.IsExported(keyType) || strings.Contains(keyType, ".")
+}
+
+// keyKind returns "string", "number" or "bool" for predeclared Go type t and
+// an empty string for named types.
+func keyKind(t string) string {
+	switch {
+	case t == "string" || t == "bool":
+		return t
+	case isNumber(t) || t == "rune" || t == "uintptr":
+		return "number"
+	}
+
+	return ""
+}
+
+// convertibleKey returns true if proto key of Go type protoKey can be
+// converted into model key type keyType. Predeclared model types should be of
+// the same kind as proto keys, enum keys are numbers. Named model types are
+// converted as is.
+func convertibleKey(keyType, protoKey string, enum bool) bool {
+	mk := keyKind(keyType)
+	if mk == "" {
+		return true
+	}
+
+	pk := keyKind(protoKey)
+	if enum {
+		pk = "number"
+	}
+
+	return mk == pk
+}
+
+// KeyedMap contains information about model map or set which is transformed
+// from repeated proto field, see formatKeyedField.
+type KeyedMap struct {
+	// Model map type, e.g. "map[string]model.Attribute".
+	Type string
+	// Model key type, e.g. "string".
+	KeyType string
+	// Format of expression which returns model key of proto element, e.g.
+	// "%s.Name". Verb is replaced by element.
+	Key string
+	// Format of expression which returns proto element of model key, it's
+	// used for sets only, e.g. "pb.Color(%s)".
+	Elem string
+	// Proto element type, e.g. "*pb.Attribute" or "string".
+	ProtoElem string
+	// True if proto elements are pointers, nil elements are skipped.
+	ProtoIsPointer bool
+	// Functions which transform proto elements into model values and vice
+	// versa. Empty for sets.
+	ToModel string
+	ToPb    string
+	// Policy for proto elements with the same key.
+	Duplicates options.DuplicateKeys
+}
+
+// extractDuplicatesOption returns value of transformer.map_duplicates option
+// or options.DuplicateKeys_KEEP_LAST if option does not exist.
+func extractDuplicatesOption(m proto.Message) options.DuplicateKeys {
+	if m == nil || !proto.HasExtension(m, options.E_MapDuplicates) {
+		return options.DuplicateKeys_KEEP_LAST
+	}
+
+	ext, err := proto.GetExtension(m, options.E_MapDuplicates)
+	if err != nil {
+		return options.DuplicateKeys_KEEP_LAST
+	}
+
+	option, ok := ext.(*options.DuplicateKeys)
+	if !ok {
+		return options.DuplicateKeys_KEEP_LAST
+	}
+
+	return *option
+}
+
+// isKeyedField returns true if repeated proto field fdp is transformed into
+// model map gf. Proto maps and fields with custom transformers are not keyed.
+func isKeyedField(fdp *descriptor.FieldDescriptorProto, gf source.FieldInfo, fi fileInfo) bool {
+	if fdp.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED || gf.IsSlice || gf.IsPointer {
+		return false
+	}
+
+	if _, ok := fi.mapEntries[fdp.GetTypeName()]; ok {
+		return false
+	}
+
+	return strings.HasPrefix(gf.Type, "map[") && !getBoolOption(fdp.Options, options.E_Custom)
+}
+
+// protoElemType returns Go type of proto scalar or enum field fdp or an empty
+// string for other fields.
+func protoElemType(fdp *descriptor.FieldDescriptorProto, fi fileInfo) string {
+	if fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_ENUM {
+		t, _ := protoMessageType(fdp.GetTypeName(), fi)
+		return t
+	}
+
+	if fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_BYTES {
+		return ""
+	}
+
+	return pbGoTypes[fdp.GetType()]
+}
+
+// modelElemType returns model type of map key or value, exported types
+// declared without package are declared in models file.
+func modelElemType(e ast.Expr, fi fileInfo) (string, bool) {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return fi.modelType(t.Name), true
+		}
+		return t.Name, true
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// convertType returns format of expression which converts value of type from
+// into type to. Format is not changed if types are equal.
+func convertType(format, from, to string) string {
+	if from == to {
+		return format
+	}
+
+	return to + "(" + format + ")"
+}
+
+// keyedField returns *Field for repeated proto field fdp which is transformed
+// into model map gf. Elements of repeated messages are keyed by field from
+// transformer.map_key option, repeated scalars are transformed into sets like
+// map[string]struct{}.
+func keyedField(pname, gname string, fdp *descriptor.FieldDescriptorProto, gf source.FieldInfo, subMessages MessageOptionList, fi fileInfo) (*Field, error) {
+	e, err := parser.ParseExpr(gf.Type)
+	if err != nil {
+		return nil, errKeyedType
+	}
+
+	mt, ok := e.(*ast.MapType)
+	if !ok {
+		return nil, errKeyedType
+	}
+
+	keyType, ok := modelElemType(mt.Key, fi)
+	if !ok {
+		return nil, errKeyedType
+	}
+
+	k := &KeyedMap{
+		KeyType:    keyType,
+		Duplicates: extractDuplicatesOption(fdp.Options),
+	}
+	f := &Field{
+		Name:      gname,
+		ProtoName: pname,
+		Keyed:     k,
+		Imports:   []string{`"sort"`},
+	}
+	if k.Duplicates == options.DuplicateKeys_REPORT_DUPLICATES {
+		f.Imports = append(f.Imports, `"fmt"`)
+	}
+
+	if fdp.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		if st, ok := mt.Value.(*ast.StructType); !ok || len(st.Fields.List) > 0 {
+			return nil, errKeyedType
+		}
+
+		if k.ProtoElem = protoElemType(fdp, fi); k.ProtoElem == "" {
+			return nil, errKeyedType
+		}
+
+		if !orderedKey(keyType, k.ProtoElem) {
+			return nil, errKeyedOrder
+		}
+
+		if !convertibleKey(keyType, k.ProtoElem, fdp.GetType() == descriptor.FieldDescriptorProto_TYPE_ENUM) {
+			return nil, errKeyedConvert
+		}
+
+		k.Type = fmt.Sprintf("map[%s]struct{}", keyType)
+		k.Key = convertType("%s", k.ProtoElem, keyType)
+		k.Elem = convertType("%s", keyType, k.ProtoElem)
+
+		return f, nil
+	}
+
+	mapKey, _ := getStringOption(fdp.Options, options.E_MapKey)
+	if mapKey == "" {
+		return nil, errKeyedKey
+	}
+
+	msg, ok := fi.messages[fdp.GetTypeName()]
+	if !ok {
+		return nil, errKeyedNotFound
+	}
+
+	var kf *descriptor.FieldDescriptorProto
+	for _, sf := range msg.Field {
+		if sf.GetName() == mapKey {
+			kf = sf
+		}
+	}
+
+	if kf == nil || kf.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED || protoElemType(kf, fi) == "" {
+		return nil, errKeyedKeyField
+	}
+
+	if !orderedKey(keyType, protoElemType(kf, fi)) {
+		return nil, errKeyedOrder
+	}
+
+	if !convertibleKey(keyType, protoElemType(kf, fi), kf.GetType() == descriptor.FieldDescriptorProto_TYPE_ENUM) {
+		return nil, errKeyedConvert
+	}
+
+	valueType := mt.Value
+	se, isPointer := valueType.(*ast.StarExpr)
+	if isPointer {
+		valueType = se.X
+	}
+
+	value, ok := modelElemType(valueType, fi)
+	if !ok {
+		return nil, errKeyedType
+	}
+
+	// Submessage has a name like ".package.type", 1: removes first ".".
+	t := fdp.GetTypeName()
+	mo := subMessages[t[1:]]
+	if mo == nil || mo.Omitted() && mo.OneofDecl() == "" {
+		return nil, errNoTransformer{path: fi.message + "." + fdp.GetName(), message: t[1:]}
+	}
+
+	kname, _ := prepareFieldNames(kf.GetName(), protoGoName(kf), "")
+	k.Key = convertType("%s."+kname, protoElemType(kf, fi), keyType)
+
+	if isPointer {
+		value = "*" + value
+	}
+	k.Type = fmt.Sprintf("map[%s]%s", keyType, value)
+
+	k.ProtoIsPointer = fi.nullable(fdp)
+	k.ProtoElem, _ = protoMessageType(t, fi)
+	if k.ProtoIsPointer {
+		k.ProtoElem = "*" + k.ProtoElem
+	}
+
+	// Elements are transformed by functions of sub message, see
+	// Field.convertFunc.
+	target := strcase.ToCamel(mo.Target())
+	elem := Field{
+		ProtoToGoType:  "PbTo" + target,
+		GoToProtoType:  target + "ToPb",
+		GoIsPointer:    isPointer,
+		ProtoIsPointer: k.ProtoIsPointer,
+	}
+	k.ToModel, k.ToPb = elem.convertFunc(false), elem.convertFunc(true)
+
+	return f, nil
+}
+
+// formatKeyedField returns statements which transform repeated proto field
+// into model map or set and vice versa. Model keys are sorted, so proto
+// elements have deterministic order.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatKeyedField(f Field, swapped bool) string {
+	k := f.Keyed
+	if k == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	if swapped {
+		elem := fmt.Sprintf(k.Elem, "k")
+		if k.ToPb != "" {
+			elem = fmt.Sprintf("%s(src.%s[k], opts...)", k.ToPb, f.Name)
+		}
+
+		fmt.Fprintf(&b, "\tif src.%s != nil {\n", f.Name)
+		fmt.Fprintf(&b, "\t\tkeys := make([]%s, 0, len(src.%s))\n", k.KeyType, f.Name)
+		fmt.Fprintf(&b, "\t\tfor k := range src.%s {\n\t\t\tkeys = append(keys, k)\n\t\t}\n", f.Name)
+		b.WriteString("\t\tsort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })\n\n")
+		fmt.Fprintf(&b, "\t\ts.%s = make([]%s, len(keys))\n", f.ProtoName, k.ProtoElem)
+		fmt.Fprintf(&b, "\t\tfor i, k := range keys {\n\t\t\ts.%s[i] = %s\n\t\t}\n\t}\n", f.ProtoName, elem)
+
+		return b.String()
+	}
+
+	value := "struct{}{}"
+	if k.ToModel != "" {
+		value = fmt.Sprintf("%s(v, opts...)", k.ToModel)
+	}
+
+	fmt.Fprintf(&b, "\tif src.%s != nil {\n", f.ProtoName)
+	fmt.Fprintf(&b, "\t\ts.%s = make(%s, len(src.%s))\n", f.Name, k.Type, f.ProtoName)
+	fmt.Fprintf(&b, "\t\tfor _, v := range src.%s {\n", f.ProtoName)
+	if k.ProtoIsPointer {
+		b.WriteString("\t\t\tif v == nil {\n\t\t\t\tcontinue\n\t\t\t}\n")
+	}
+	fmt.Fprintf(&b, "\t\t\tk := %s\n", fmt.Sprintf(k.Key, "v"))
+
+	switch k.Duplicates {
+	case options.DuplicateKeys_KEEP_FIRST:
+		fmt.Fprintf(&b, "\t\t\tif _, ok := s.%s[k]; ok {\n\t\t\t\tcontinue\n\t\t\t}\n", f.Name)
+	case options.DuplicateKeys_REPORT_DUPLICATES:
+		fmt.Fprintf(&b, "\t\t\tif _, ok := s.%s[k]; ok {\n\t\t\t\treportError(opts, fmt.Errorf(\"%s: duplicate key %%v\", k))\n\t\t\t\tcontinue\n\t\t\t}\n", f.Name, f.ProtoName)
+	}
+
+	fmt.Fprintf(&b, "\t\t\ts.%s[k] = %s\n\t\t}\n\t}\n", f.Name, value)
+
+	return b.String()
+}
@@ -0,0 +1,243 @@
+package generator
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Keyed", func() {
+	var (
+		str      = descriptor.FieldDescriptorProto_TYPE_STRING
+		i32      = descriptor.FieldDescriptorProto_TYPE_INT32
+		bytesT   = descriptor.FieldDescriptorProto_TYPE_BYTES
+		boolT    = descriptor.FieldDescriptorProto_TYPE_BOOL
+		enum     = descriptor.FieldDescriptorProto_TYPE_ENUM
+		msg      = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+		optional = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	file := &descriptor.FileDescriptorProto{
+		Package: sp("shop"),
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: sp("Attribute"),
+				Field: []*descriptor.FieldDescriptorProto{
+					{Name: sp("name"), Type: &str, Label: &optional},
+					{Name: sp("rank"), Type: &i32, Label: &optional},
+					{Name: sp("values"), Type: &str, Label: &repeated},
+					{Name: sp("active"), Type: &boolT, Label: &optional},
+					{Name: sp("color"), Type: &enum, TypeName: sp(".shop.Color"), Label: &optional},
+				},
+			},
+			{
+				Name:       sp("Product"),
+				NestedType: []*descriptor.DescriptorProto{{Name: sp("CountsEntry"), Options: &descriptor.MessageOptions{MapEntry: bp(true)}}},
+			},
+		},
+	}
+
+	fi := fileInfo{
+		pkg:          "shop",
+		protoPackage: "pb",
+		repoPackage:  "model",
+		message:      "shop.Product",
+		messages:     fileMessages(file),
+		mapEntries:   fileMapEntries(file),
+	}
+
+	subMessages := MessageOptionList{"shop.Attribute": &messageOption{targetName: "Attribute"}}
+
+	// field returns repeated proto field with options from opts.
+	field := func(typ descriptor.FieldDescriptorProto_Type, typeName string, opts map[*proto.ExtensionDesc]interface{}) *descriptor.FieldDescriptorProto {
+		fdp := newField("attributes", typ, opts)
+		fdp.Label = &repeated
+		if typeName != "" {
+			fdp.TypeName = sp(typeName)
+		}
+
+		return fdp
+	}
+
+	attributes := field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "name"})
+
+	DescribeTable("isKeyedField",
+		func(fdp *descriptor.FieldDescriptorProto, gf source.FieldInfo, expected bool) {
+			Expect(isKeyedField(fdp, gf, fi)).To(Equal(expected))
+		},
+
+		Entry("Repeated message and map", attributes, source.FieldInfo{Type: "map[string]Attribute"}, true),
+		Entry("Repeated scalar and set", field(str, "", nil), source.FieldInfo{Type: "map[string]struct{}"}, true),
+		Entry("Slice", attributes, source.FieldInfo{Type: "Attribute", IsSlice: true}, false),
+		Entry("Proto map", field(msg, ".shop.Product.CountsEntry", nil), source.FieldInfo{Type: "map[string]int64"}, false),
+		Entry("Not repeated", &descriptor.FieldDescriptorProto{Name: sp("tags"), Type: &str, Label: &optional}, source.FieldInfo{Type: "map[string]struct{}"}, false),
+	)
+
+	It("extractDuplicatesOption returns KEEP_LAST by default", func() {
+		Expect(extractDuplicatesOption(nil)).To(Equal(options.DuplicateKeys_KEEP_LAST))
+		Expect(extractDuplicatesOption(attributes.Options)).To(Equal(options.DuplicateKeys_KEEP_LAST))
+	})
+
+	DescribeTable("keyedField",
+		func(fdp *descriptor.FieldDescriptorProto, gf source.FieldInfo, fi fileInfo, expected KeyedMap) {
+			f, err := keyedField("Attributes", "Attributes", fdp, gf, subMessages, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*f.Keyed).To(Equal(expected))
+		},
+
+		Entry("Map of values", attributes, source.FieldInfo{Type: "map[string]Attribute"}, fi, KeyedMap{
+			Type:           "map[string]model.Attribute",
+			KeyType:        "string",
+			Key:            "%s.Name",
+			ProtoElem:      "*pb.Attribute",
+			ProtoIsPointer: true,
+			ToModel:        "PbToAttributePtrVal",
+			ToPb:           "AttributeToPbValPtr",
+		}),
+		Entry("Map of pointers with converted keys", field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "rank"}),
+			source.FieldInfo{Type: "map[int]*Attribute"}, fi, KeyedMap{
+				Type:           "map[int]*model.Attribute",
+				KeyType:        "int",
+				Key:            "int(%s.Rank)",
+				ProtoElem:      "*pb.Attribute",
+				ProtoIsPointer: true,
+				ToModel:        "PbToAttributePtr",
+				ToPb:           "AttributeToPbPtr",
+			}),
+		Entry("Set of strings", field(str, "", nil), source.FieldInfo{Type: "map[string]struct{}"}, fi, KeyedMap{
+			Type:      "map[string]struct{}",
+			KeyType:   "string",
+			Key:       "%s",
+			Elem:      "%s",
+			ProtoElem: "string",
+		}),
+		Entry("Set of enums", field(enum, ".shop.Color", nil), source.FieldInfo{Type: "map[Color]struct{}"}, fi, KeyedMap{
+			Type:      "map[model.Color]struct{}",
+			KeyType:   "model.Color",
+			Key:       "model.Color(%s)",
+			Elem:      "pb.Color(%s)",
+			ProtoElem: "pb.Color",
+		}),
+	)
+
+	It("keyedField uses non-nullable elements", func() {
+		fdp := field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{
+			options.E_MapKey:     "name",
+			gogoproto.E_Nullable: false,
+		})
+
+		f, err := keyedField("Attributes", "Attributes", fdp, source.FieldInfo{Type: "map[string]Attribute"}, subMessages, fi)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Keyed.ProtoElem).To(Equal("pb.Attribute"))
+		Expect(f.Keyed.ToModel).To(Equal("PbToAttribute"))
+		Expect(f.Imports).To(Equal([]string{`"sort"`}))
+	})
+
+	DescribeTable("keyedField returns an error",
+		func(fdp *descriptor.FieldDescriptorProto, gf source.FieldInfo, expected interface{}) {
+			_, err := keyedField("Attributes", "Attributes", fdp, gf, subMessages, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(expected))
+		},
+
+		Entry("Map of scalars", field(str, "", nil), source.FieldInfo{Type: "map[string]bool"}, errKeyedType),
+		Entry("Set of bytes", field(bytesT, "", nil), source.FieldInfo{Type: "map[string]struct{}"}, errKeyedType),
+		Entry("Map with invalid key", attributes, source.FieldInfo{Type: "map[[2]int]Attribute"}, errKeyedType),
+		Entry("Without map_key", field(msg, ".shop.Attribute", nil), source.FieldInfo{Type: "map[string]Attribute"}, errKeyedKey),
+		Entry("Key field not found", field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "id"}), source.FieldInfo{Type: "map[string]Attribute"}, errKeyedKeyField),
+		Entry("Repeated key field", field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "values"}), source.FieldInfo{Type: "map[string]Attribute"}, errKeyedKeyField),
+		Entry("Message from other file", field(msg, ".other.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "name"}), source.FieldInfo{Type: "map[string]Attribute"}, errKeyedNotFound),
+		Entry("Set of bools", field(boolT, "", nil), source.FieldInfo{Type: "map[bool]struct{}"}, errKeyedOrder),
+		Entry("Set with unordered model key", field(str, "", nil), source.FieldInfo{Type: "map[any]struct{}"}, errKeyedOrder),
+		Entry("Bool key field", field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "active"}), source.FieldInfo{Type: "map[bool]Attribute"}, errKeyedOrder),
+		Entry("Set of enums into strings", field(enum, ".shop.Color", nil), source.FieldInfo{Type: "map[string]struct{}"}, errKeyedConvert),
+		Entry("Enum key field into string", field(msg, ".shop.Attribute", map[*proto.ExtensionDesc]interface{}{options.E_MapKey: "color"}), source.FieldInfo{Type: "map[string]Attribute"}, errKeyedConvert),
+		Entry("String key field into int", attributes, source.FieldInfo{Type: "map[int]Attribute"}, errKeyedConvert),
+	)
+
+	It("keyedField returns an error for messages without transformer", func() {
+		_, err := keyedField("Attributes", "Attributes", attributes, source.FieldInfo{Type: "map[string]Attribute"}, MessageOptionList{}, fi)
+		Expect(err).To(MatchError(ContainSubstring("shop.Product.attributes")))
+	})
+
+	It("processField transforms repeated fields into maps", func() {
+		gf := source.Structure{"Attributes": {Type: "map[string]Attribute"}}
+
+		f, err := processField(&bytes.Buffer{}, attributes, subMessages, gf, fi)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Keyed).NotTo(BeNil())
+		Expect(f.ProtoPath).To(Equal("attributes"))
+		Expect(formatField(*f, false, "model")).To(BeEmpty())
+		Expect(formatField(*f, true, "pb")).To(BeEmpty())
+	})
+
+	DescribeTable("formatKeyedField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatKeyedField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Not keyed", Field{Name: "Tags", ProtoName: "Tags"}, false, ""),
+		Entry("Map, proto to Go", Field{Name: "Attrs", ProtoName: "Attributes", Keyed: &KeyedMap{
+			Type:           "map[string]model.Attribute",
+			Key:            "%s.Name",
+			ProtoIsPointer: true,
+			ToModel:        "PbToAttributePtrVal",
+		}}, false, "\tif src.Attributes != nil {\n"+
+			"\t\ts.Attrs = make(map[string]model.Attribute, len(src.Attributes))\n"+
+			"\t\tfor _, v := range src.Attributes {\n"+
+			"\t\t\tif v == nil {\n\t\t\t\tcontinue\n\t\t\t}\n"+
+			"\t\t\tk := v.Name\n"+
+			"\t\t\ts.Attrs[k] = PbToAttributePtrVal(v, opts...)\n"+
+			"\t\t}\n\t}\n"),
+		Entry("Map, Go to proto", Field{Name: "Attrs", ProtoName: "Attributes", Keyed: &KeyedMap{
+			KeyType:   "string",
+			ProtoElem: "*pb.Attribute",
+			ToPb:      "AttributeToPbValPtr",
+		}}, true, "\tif src.Attrs != nil {\n"+
+			"\t\tkeys := make([]string, 0, len(src.Attrs))\n"+
+			"\t\tfor k := range src.Attrs {\n\t\t\tkeys = append(keys, k)\n\t\t}\n"+
+			"\t\tsort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })\n\n"+
+			"\t\ts.Attributes = make([]*pb.Attribute, len(keys))\n"+
+			"\t\tfor i, k := range keys {\n\t\t\ts.Attributes[i] = AttributeToPbValPtr(src.Attrs[k], opts...)\n\t\t}\n\t}\n"),
+		Entry("Set, proto to Go, keep first", Field{Name: "Tags", ProtoName: "Tags", Keyed: &KeyedMap{
+			Type:       "map[string]struct{}",
+			Key:        "%s",
+			Duplicates: options.DuplicateKeys_KEEP_FIRST,
+		}}, false, "\tif src.Tags != nil {\n"+
+			"\t\ts.Tags = make(map[string]struct{}, len(src.Tags))\n"+
+			"\t\tfor _, v := range src.Tags {\n"+
+			"\t\t\tk := v\n"+
+			"\t\t\tif _, ok := s.Tags[k]; ok {\n\t\t\t\tcontinue\n\t\t\t}\n"+
+			"\t\t\ts.Tags[k] = struct{}{}\n"+
+			"\t\t}\n\t}\n"),
+		Entry("Set, proto to Go, report duplicates", Field{Name: "Tags", ProtoName: "Tags", Keyed: &KeyedMap{
+			Type:       "map[string]struct{}",
+			Key:        "%s",
+			Duplicates: options.DuplicateKeys_REPORT_DUPLICATES,
+		}}, false, "\tif src.Tags != nil {\n"+
+			"\t\ts.Tags = make(map[string]struct{}, len(src.Tags))\n"+
+			"\t\tfor _, v := range src.Tags {\n"+
+			"\t\t\tk := v\n"+
+			"\t\t\tif _, ok := s.Tags[k]; ok {\n\t\t\t\treportError(opts, fmt.Errorf(\"Tags: duplicate key %v\", k))\n\t\t\t\tcontinue\n\t\t\t}\n"+
+			"\t\t\ts.Tags[k] = struct{}{}\n"+
+			"\t\t}\n\t}\n"),
+		Entry("Set, Go to proto", Field{Name: "Colors", ProtoName: "Colors", Keyed: &KeyedMap{
+			KeyType:   "model.Color",
+			Elem:      "pb.Color(%s)",
+			ProtoElem: "pb.Color",
+		}}, true, "\tif src.Colors != nil {\n"+
+			"\t\tkeys := make([]model.Color, 0, len(src.Colors))\n"+
+			"\t\tfor k := range src.Colors {\n\t\t\tkeys = append(keys, k)\n\t\t}\n"+
+			"\t\tsort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })\n\n"+
+			"\t\ts.Colors = make([]pb.Color, len(keys))\n"+
+			"\t\tfor i, k := range keys {\n\t\t\ts.Colors[i] = pb.Color(k)\n\t\t}\n\t}\n"),
+	)
+})
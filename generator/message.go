@@ -1,9 +1,11 @@
 package generator
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
 	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
 )
 
@@ -14,11 +16,18 @@ func processMessage(
 	w io.Writer,
 	msg *descriptor.DescriptorProto,
 	subMessages map[string]MessageOption,
-	str source.StructureList,
+	fi fileInfo,
 	debug bool,
 ) ([]Field, string, error) {
 
+	name := fmt.Sprintf("%s.%s", fi.pkg, msg.GetName())
+
 	structName, err := extractStructNameOption(msg)
+	// Messages referenced by transformed messages are linked with model
+	// structures by resolveSubMessages.
+	if mo, ok := subMessages[name]; err != nil && ok && !mo.Omitted() && !mo.Declared() {
+		structName, err = mo.Target(), nil
+	}
 	if err != nil {
 		if msg != nil {
 			for _, d := range msg.OneofDecl {
@@ -29,7 +38,7 @@ func processMessage(
 		return nil, "", err
 	}
 
-	tsf, err := source.Lookup(str, structName)
+	tsf, err := source.Lookup(fi.structs, structName)
 	if err != nil {
 		return nil, "", err
 	}
@@ -41,10 +50,34 @@ func processMessage(
 
 	p(debugWriter, "%s", tsf)
 
+	if fi.editions {
+		fi.features = messageFeatures(fi.features, msg)
+	}
+	fi.message = name
+	fi.model = structName
+
+	// Fields with map_to options like "Address.City" are mapped into fields
+	// of nested model structures.
+	if tsf, err = nestedStructure(tsf, msg, fi); err != nil {
+		return nil, "", err
+	}
+
 	fields := []Field{}
+	var aliases []*descriptor.FieldDescriptorProto
 
 	for _, f := range msg.Field {
-		pf, err := processField(debugWriter, f, subMessages, tsf)
+		// Aliases are added to their fields after all fields are processed.
+		if aliasOf(f) != "" {
+			aliases = append(aliases, f)
+			continue
+		}
+
+		process := processField
+		if isFlatten(f) {
+			process = flattenField
+		}
+
+		pf, err := process(debugWriter, f, subMessages, tsf, fi)
 		if err != nil {
 			if e, ok := err.(loggableError); ok {
 				p(w, "// %s\n", e)
@@ -57,8 +90,62 @@ func processMessage(
 			continue
 		}
 
+		if fi.golang {
+			golangField(pf, f, fi)
+			golangOneofField(pf, f, msg, fi)
+		}
+		pf.Direction = extractDirectionOption(f.Options)
+		// Methods are read only, so they are transformed into proto fields
+		// only.
+		if pf.Method {
+			pf.Direction = options.Direction_MODEL_TO_PB
+		}
+		if nestedPath(f) != "" {
+			// Presence is checked only for pointers to nested structures,
+			// see nestFields.
+			pf.Presence, _ = presenceCond(f, fi)
+		}
+
+		fields = append(fields, *pf)
+	}
+
+	for _, f := range aliases {
+		if err := processAliasField(debugWriter, f, msg, fields, subMessages, tsf, fi); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if fields, err = nestFields(fields, tsf, fi); err != nil {
+		return nil, "", err
+	}
+
+	for _, ext := range fi.extensions[msg.GetName()] {
+		pf, err := processExtension(debugWriter, ext, tsf, fi)
+		if err != nil {
+			if e, ok := err.(loggableError); ok {
+				p(w, "// %s\n", e)
+				continue
+			}
+			return nil, "", err
+		}
+		pf.Direction = extractDirectionOption(ext.fdp.Options)
+
 		fields = append(fields, *pf)
 	}
 
+	pf, ok, err := unknownField(msg, tsf, fi)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		fields = append(fields, *pf)
+	}
+
+	cf, err := constFields(msg, fields, tsf)
+	if err != nil {
+		return nil, "", err
+	}
+	fields = append(fields, cf...)
+
 	return fields, structName, nil
 }
@@ -14,6 +14,11 @@ type MessageOption interface {
 	Omitted() bool
 	// Returns Oneof message name.
 	OneofDecl() string
+	// If true, field mask functions are generated for proto message.
+	FieldMask() bool
+	// If true, transformer functions of proto message are written manually,
+	// see go_converter option.
+	Declared() bool
 }
 
 // MessageOptionList is a list of proto message option. Map key is a message
@@ -24,8 +29,8 @@ type MessageOptionList map[string]MessageOption
 func (sol MessageOptionList) String() string {
 	s := "\n"
 	for k, v := range sol {
-		s += fmt.Sprintf("// %q: target: %q, Omitted: %t, OneofDecl: %q\n",
-			k, v.Target(), v.Omitted(), v.OneofDecl())
+		s += fmt.Sprintf("// %q: target: %q, Omitted: %t, OneofDecl: %q, FieldMask: %t, Declared: %t\n",
+			k, v.Target(), v.Omitted(), v.OneofDecl(), v.FieldMask(), v.Declared())
 	}
 
 	return s
@@ -38,6 +43,10 @@ type messageOption struct {
 	fullName string
 	// OneOf name.
 	oneofDecl string
+	// Value of transformer.field_mask option of file which contains message.
+	fieldMask bool
+	// True if targetName is taken from transformer.go_converter option.
+	declared bool
 }
 
 func (so messageOption) Target() string {
@@ -55,3 +64,11 @@ func (so messageOption) Omitted() bool {
 func (so messageOption) OneofDecl() string {
 	return so.oneofDecl
 }
+
+func (so messageOption) FieldMask() bool {
+	return so.fieldMask
+}
+
+func (so messageOption) Declared() bool {
+	return so.declared
+}
@@ -29,7 +29,7 @@ var _ = Describe("Message", func() {
 					Expect(err).NotTo(HaveOccurred())
 				}
 
-				fields, structName, err := processMessage(nil, msg, subm, messagesData, false)
+				fields, structName, err := processMessage(nil, msg, subm, fileInfo{structs: messagesData}, false)
 				if expError == nil {
 					Expect(err).NotTo(HaveOccurred())
 				} else {
@@ -89,6 +89,7 @@ var _ = Describe("Message", func() {
 					UsePackage:     false,
 					OneofDecl:      "",
 					Opts:           "",
+					ProtoPath:      "int64_field",
 				},
 			}, "msg1", nil),
 
@@ -117,6 +118,7 @@ var _ = Describe("Message", func() {
 					UsePackage:     false,
 					OneofDecl:      "",
 					Opts:           "",
+					ProtoPath:      "ID",
 				},
 			}, "msg1", nil),
 		)
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"errors"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+)
+
+var errMethodNotFound = errors.New("method without arguments which returns one value not found in model type")
+
+// fromMethod returns value of transformer.from_method option of field fdp or
+// an empty string.
+func fromMethod(fdp *descriptor.FieldDescriptorProto) string {
+	if fdp.Options == nil {
+		return ""
+	}
+
+	name, _ := getStringOption(fdp.Options, options.E_FromMethod)
+
+	return name
+}
+
+// usesMethods returns true if any field of messages declared in file f has
+// transformer.from_method option, methods of model types are parsed only for
+// such files.
+func usesMethods(f *descriptor.FileDescriptorProto) bool {
+	var walk func(msgs []*descriptor.DescriptorProto) bool
+	walk = func(msgs []*descriptor.DescriptorProto) bool {
+		for _, m := range msgs {
+			for _, fdp := range m.Field {
+				if fromMethod(fdp) != "" {
+					return true
+				}
+			}
+			if walk(m.NestedType) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return walk(f.MessageType)
+}
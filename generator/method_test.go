@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Methods", func() {
+	var (
+		str = descriptor.FieldDescriptorProto_TYPE_STRING
+		i64 = descriptor.FieldDescriptorProto_TYPE_INT64
+	)
+
+	// field returns proto field with from_method option if method isn't empty.
+	field := func(name string, typ descriptor.FieldDescriptorProto_Type, method string) *descriptor.FieldDescriptorProto {
+		return newField(name, typ, map[*proto.ExtensionDesc]interface{}{options.E_FromMethod: method})
+	}
+
+	fi := fileInfo{
+		model: "Order",
+		structs: source.StructureList{
+			"Order": {"ID": {Type: "string"}},
+		},
+		methods: source.MethodList{
+			"Order": {
+				"DisplayName": {Type: "string"},
+				"Total":       {Type: "int"},
+			},
+		},
+	}
+
+	DescribeTable("fromMethod",
+		func(fdp *descriptor.FieldDescriptorProto, expected string) {
+			Expect(fromMethod(fdp)).To(Equal(expected))
+		},
+
+		Entry("Without options", field("name", str, ""), ""),
+		Entry("Method", field("name", str, "DisplayName"), "DisplayName"),
+	)
+
+	Describe("processField", func() {
+		It("reads model value from method", func() {
+			f, err := processField(&bytes.Buffer{}, field("display_name", str, "DisplayName"), nil, fi.structs["Order"], fi)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(f.Method).To(BeTrue())
+			Expect(f.Name).To(Equal("DisplayName()"))
+			Expect(formatField(*f, true, "pb")).To(Equal("DisplayName: src.DisplayName(),"))
+		})
+
+		It("converts type of method result", func() {
+			f, err := processField(&bytes.Buffer{}, field("total", i64, "Total"), nil, fi.structs["Order"], fi)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(formatField(*f, true, "pb")).To(Equal("Total:  int64(src.Total() ),"))
+		})
+
+		It("returns an error if method not found", func() {
+			_, err := processField(&bytes.Buffer{}, field("total", i64, "Sum"), nil, fi.structs["Order"], fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errMethodNotFound))
+		})
+	})
+
+	It("processMessage transforms methods into proto fields only", func() {
+		msg := &descriptor.DescriptorProto{
+			Name:    sp("Order"),
+			Field:   []*descriptor.FieldDescriptorProto{field("id", str, ""), field("display_name", str, "DisplayName")},
+			Options: &descriptor.MessageOptions{},
+		}
+		if err := proto.SetExtension(msg.Options, options.E_GoStruct, sp("Order")); err != nil {
+			panic(err)
+		}
+
+		fields, _, err := processMessage(nil, msg, nil, fi, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fields).To(HaveLen(2))
+		Expect(fields[1].Direction).To(Equal(options.Direction_MODEL_TO_PB))
+		Expect(newFieldMaskData(&Data{Fields: fields}).Fields).To(HaveLen(1))
+	})
+
+	Describe("ProcessFile", func() {
+		var dir, path string
+
+		// file returns proto file with Order message which fields are
+		// created out of fdps.
+		file := func(fdps ...*descriptor.FieldDescriptorProto) *descriptor.FileDescriptorProto {
+			f := &descriptor.FileDescriptorProto{
+				Name:    sp("order.proto"),
+				Package: sp("shop"),
+				Syntax:  sp("proto3"),
+				Options: &descriptor.FileOptions{},
+				MessageType: []*descriptor.DescriptorProto{
+					{Name: sp("Order"), Field: fdps, Options: &descriptor.MessageOptions{}},
+				},
+			}
+			Expect(proto.SetExtension(f.Options, options.E_GoModelsFilePath, sp(path))).To(Succeed())
+			Expect(proto.SetExtension(f.MessageType[0].Options, options.E_GoStruct, sp("Order"))).To(Succeed())
+
+			return f
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "models")
+			Expect(err).NotTo(HaveOccurred())
+
+			path = filepath.Join(dir, "order.go")
+			Expect(os.WriteFile(path, []byte("package model\n\ntype Order struct {\n\tID string\n}\n"), 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package model\n\nfunc (o Order"), 0o644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("doesn't parse other files of models package for files without methods", func() {
+			_, err := ProcessFile(file(field("id", str, "")), sp("transform"), sp("helper"), MessageOptionList{}, false, "", true, "gogo", "")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("parses other files of models package for files with methods", func() {
+			_, err := ProcessFile(file(field("id", str, ""), field("total", i64, "Total")), sp("transform"), sp("helper"), MessageOptionList{}, false, "", true, "gogo", "")
+			Expect(err).To(MatchError(ContainSubstring("broken.go")))
+		})
+	})
+})
@@ -0,0 +1,288 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+const (
+	// Import specs for packages which have the same name "decimal".
+	shopspringImport = `"github.com/shopspring/decimal"`
+	pbDecimalImport  = `pbdecimal "google.golang.org/genproto/googleapis/type/decimal"`
+)
+
+var (
+	errNoCurrency = errors.New("transformer.currency option is required")
+	errNoScale    = errors.New("transformer.scale option is required")
+	errBadScale   = errors.New("transformer.scale option should be in range 0..9")
+
+	moneyType = googleType{
+		pbType: "money.Money",
+		pbZero: "money.Money{}",
+	}
+
+	pbDecimalType = googleType{
+		pbType:  "pbdecimal.Decimal",
+		pbZero:  "pbdecimal.Decimal{}",
+		imports: []string{pbDecimalImport},
+	}
+
+	stringDecimalType = googleType{
+		pbType: "string",
+		pbZero: `""`,
+	}
+)
+
+// numericOptions contains field options which are used for conversion of
+// money and decimal values.
+type numericOptions struct {
+	// Value of transformer.currency option.
+	currency string
+	// Value of transformer.scale option, hasScale is true if option is set.
+	scale    int32
+	hasScale bool
+}
+
+// extractNumericOptions returns values of transformer.currency and
+// transformer.scale options.
+func extractNumericOptions(m proto.Message) (numericOptions, error) {
+	o := numericOptions{}
+
+	c, err := getStringOption(m, options.E_Currency)
+	if _, ok := err.(errOptionNotExists); err != nil && err != ErrNilOptions && !ok {
+		return o, err
+	}
+	o.currency = c
+
+	s, err := getInt32Option(m, options.E_Scale)
+	if _, ok := err.(errOptionNotExists); err != nil && err != ErrNilOptions && !ok {
+		return o, err
+	}
+	o.scale, o.hasScale = s, err == nil
+
+	if o.hasScale && (o.scale < 0 || o.scale > 9) {
+		return o, errBadScale
+	}
+
+	return o, nil
+}
+
+// pow10 returns 10^n as a string which is used as Go constant.
+func pow10(n int32) string {
+	return "1" + strings.Repeat("0", int(n))
+}
+
+// ratToMoney returns steps which convert *big.Rat variable r into variables
+// units and nanos. Values with more than 9 fractional digits or integer part
+// which overflows int64 are rejected.
+func ratToMoney(r string) []step {
+	return []step{
+		{stmt: fmt.Sprintf("n := new(big.Rat).Mul(%s, big.NewRat(1e9, 1))", r)},
+		{cond: "!n.IsInt()", format: "value %s has more than 9 fractional digits", args: []string{r + ".RatString()"}},
+		{stmt: "units, nanos := new(big.Int).QuoRem(n.Num(), big.NewInt(1e9), new(big.Int))"},
+		{cond: "!units.IsInt64()", format: "value %s overflows int64", args: []string{r + ".RatString()"}},
+	}
+}
+
+// ratToString returns steps which format *big.Rat variable r into variable s
+// with scale fractional digits. Values with more fractional digits are
+// rejected.
+func ratToString(r string, scale int32) []step {
+	return []step{
+		{stmt: fmt.Sprintf("s := %s.FloatString(%d)", r, scale)},
+		{stmt: "back, _ := new(big.Rat).SetString(s)"},
+		{cond: fmt.Sprintf("back.Cmp(%s) != 0", r), format: fmt.Sprintf("value %%s has more than %d fractional digits", scale), args: []string{r + ".RatString()"}},
+	}
+}
+
+// moneyConv returns conversion between google.type.Money and model type. The
+// second return value is false if model type is not supported.
+func moneyConv(gf source.FieldInfo, o numericOptions) (googleTypeConv, bool, error) {
+	supported := gf.Type == "decimal.Decimal" || gf.Type == "int64" || gf.Type == "string" ||
+		gf.Type == "big.Rat" && gf.IsPointer
+
+	if !supported {
+		return googleTypeConv{}, false, nil
+	}
+
+	if o.currency == "" {
+		return googleTypeConv{}, false, errNoCurrency
+	}
+
+	money := func(units, nanos string) string {
+		return fmt.Sprintf("money.Money{CurrencyCode: %q, Units: %s, Nanos: %s}", o.currency, units, nanos)
+	}
+
+	toGoSteps := []step{
+		{cond: fmt.Sprintf("v.CurrencyCode != %q", o.currency), format: "unexpected currency %q", args: []string{"v.CurrencyCode"}},
+		{cond: "v.Units > 0 && v.Nanos < 0 || v.Units < 0 && v.Nanos > 0 || v.Nanos <= -1e9 || v.Nanos >= 1e9", format: "invalid nanos %d", args: []string{"v.Nanos"}, validation: true},
+	}
+	rat := "new(big.Rat).Add(new(big.Rat).SetInt64(v.Units), big.NewRat(int64(v.Nanos), 1e9))"
+
+	switch gf.Type {
+	case "decimal.Decimal":
+		return googleTypeConv{
+			goZero:    "decimal.Decimal{}",
+			toGoSteps: toGoSteps,
+			toGo:      "decimal.New(v.Units, 0).Add(decimal.New(int64(v.Nanos), -9))",
+			toPbSteps: []step{
+				{stmt: "units := v.Truncate(0)"},
+				{cond: "!units.BigInt().IsInt64()", format: "value %s overflows int64", args: []string{"v"}},
+				{stmt: "nanos := v.Sub(units).Shift(9)"},
+				{cond: "!nanos.Equal(nanos.Truncate(0))", format: "value %s has more than 9 fractional digits", args: []string{"v"}},
+			},
+			toPb:    money("units.IntPart()", "int32(nanos.IntPart())"),
+			imports: []string{shopspringImport},
+		}, true, nil
+
+	case "big.Rat":
+		return googleTypeConv{
+			goZero:    "nil",
+			toGoSteps: toGoSteps,
+			toGo:      rat,
+			toPbSteps: ratToMoney("v"),
+			toPb:      money("units.Int64()", "int32(nanos.Int64())"),
+			pointer:   true,
+		}, true, nil
+
+	case "string":
+		return googleTypeConv{
+			goZero:    `""`,
+			toGoSteps: toGoSteps,
+			toGo:      fmt.Sprintf(`strings.TrimSuffix(strings.TrimRight(%s.FloatString(9), "0"), ".")`, rat),
+			toPbSteps: append([]step{
+				{cond: `v == ""`},
+				{stmt: "r, ok := new(big.Rat).SetString(v)"},
+				{cond: "!ok", format: "invalid decimal %q", args: []string{"v"}},
+			}, ratToMoney("r")...),
+			toPb: money("units.Int64()", "int32(nanos.Int64())"),
+		}, true, nil
+	}
+
+	// int64 minor units.
+	if !o.hasScale {
+		return googleTypeConv{}, false, errNoScale
+	}
+
+	m, d := pow10(o.scale), pow10(9-o.scale)
+
+	return googleTypeConv{
+		goZero: "0",
+		toGoSteps: append(toGoSteps,
+			step{cond: fmt.Sprintf("v.Nanos%%%s != 0", d), format: fmt.Sprintf("nanos %%d has more than %d fractional digits", o.scale), args: []string{"v.Nanos"}},
+			step{cond: fmt.Sprintf("v.Units > math.MaxInt64/%s || v.Units < math.MinInt64/%s", m, m), format: "units %d overflows int64", args: []string{"v.Units"}},
+		),
+		toGo: fmt.Sprintf("v.Units*%s + int64(v.Nanos)/%s", m, d),
+		toPb: money("v / "+m, fmt.Sprintf("int32(v %% %s * %s)", m, d)),
+	}, true, nil
+}
+
+// decimalConv returns conversion between decimal value and model type. Value
+// is an expression which returns decimal string from proto value and wrap is
+// a format which wraps decimal string into proto value. The second return
+// value is false if model type is not supported.
+func decimalConv(gf source.FieldInfo, o numericOptions, value, wrap string) (googleTypeConv, bool, error) {
+	zero := step{cond: value + ` == ""`}
+
+	switch {
+	case gf.Type == "decimal.Decimal":
+		return googleTypeConv{
+			goZero: "decimal.Decimal{}",
+			toGoSteps: []step{
+				zero,
+				{stmt: fmt.Sprintf("d, err := decimal.NewFromString(%s)", value)},
+				{cond: "err != nil", format: "%v", args: []string{"err"}},
+			},
+			toGo:    "d",
+			toPb:    fmt.Sprintf(wrap, "v.String()"),
+			imports: []string{shopspringImport},
+		}, true, nil
+
+	case gf.Type == "big.Rat" && gf.IsPointer:
+		if !o.hasScale {
+			return googleTypeConv{}, false, errNoScale
+		}
+
+		return googleTypeConv{
+			goZero: "nil",
+			toGoSteps: []step{
+				zero,
+				{stmt: fmt.Sprintf("r, ok := new(big.Rat).SetString(%s)", value)},
+				{cond: "!ok", format: "invalid decimal %q", args: []string{value}},
+			},
+			toGo:      "r",
+			toPbSteps: ratToString("v", o.scale),
+			toPb:      fmt.Sprintf(wrap, "s"),
+			pointer:   true,
+		}, true, nil
+
+	case gf.Type == "string" && value != "v":
+		valid := func(s string) step {
+			return step{
+				stmt:       fmt.Sprintf("_, ok := new(big.Rat).SetString(%s)", s),
+				cond:       fmt.Sprintf(`%s != "" && !ok`, s),
+				format:     "invalid decimal %q",
+				args:       []string{s},
+				validation: true,
+			}
+		}
+
+		return googleTypeConv{
+			goZero:    `""`,
+			toGoSteps: []step{valid(value)},
+			toGo:      value,
+			toPbSteps: []step{valid("v")},
+			toPb:      fmt.Sprintf(wrap, "v"),
+		}, true, nil
+	}
+
+	return googleTypeConv{}, false, nil
+}
+
+// wktgoogleTypeNumeric returns *Field created out of google.type.Money or
+// google.type.Decimal field. Types which have no built-in conversion are
+// converted by helper functions.
+func wktgoogleTypeNumeric(pname, gname, typeName string, gf source.FieldInfo, pnullable, validate bool, o numericOptions) (*Field, error) {
+	var (
+		gt   googleType
+		conv googleTypeConv
+		ok   bool
+		err  error
+	)
+
+	switch typeName {
+	case ".google.type.Money":
+		gt = moneyType
+		conv, ok, err = moneyConv(gf, o)
+	case ".google.type.Decimal":
+		gt = pbDecimalType
+		conv, ok, err = decimalConv(gf, o, "v.Value", "pbdecimal.Decimal{Value: %s}")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return helperField(pname, gname, gt, gf, pnullable), nil
+	}
+
+	return inlineField(pname, gname, gt, conv, gf, pnullable, validate), nil
+}
+
+// stringDecimalField returns *Field for proto string field which contains
+// decimal number and model field of decimal.Decimal or *big.Rat type. The
+// second return value is false if model field has other type.
+func stringDecimalField(pname, gname string, gf source.FieldInfo, o numericOptions) (*Field, bool, error) {
+	conv, ok, err := decimalConv(gf, o, "v", "%s")
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return inlineField(pname, gname, stringDecimalType, conv, gf, false, false), true, nil
+}
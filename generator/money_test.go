@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Money", func() {
+
+	Describe("extractNumericOptions", func() {
+
+		DescribeTable("check result",
+			func(currency *string, scale *int32, expected numericOptions, expErr error) {
+				o := &descriptor.FieldOptions{}
+				if currency != nil {
+					Expect(proto.SetExtension(o, options.E_Currency, currency)).To(Succeed())
+				}
+				if scale != nil {
+					Expect(proto.SetExtension(o, options.E_Scale, scale)).To(Succeed())
+				}
+
+				no, err := extractNumericOptions(o)
+				if expErr == nil {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(no).To(Equal(expected))
+				} else {
+					Expect(err).To(Equal(expErr))
+				}
+			},
+
+			Entry("No options", nil, nil, numericOptions{}, nil),
+			Entry("Currency", sp("USD"), nil, numericOptions{currency: "USD"}, nil),
+			Entry("Zero scale", nil, i32p(0), numericOptions{hasScale: true}, nil),
+			Entry("Currency and scale", sp("EUR"), i32p(2), numericOptions{currency: "EUR", scale: 2, hasScale: true}, nil),
+			Entry("Scale out of range", nil, i32p(10), numericOptions{}, errBadScale),
+		)
+	})
+
+	Describe("wktgoogleTypeNumeric", func() {
+		var cents = numericOptions{currency: "USD", scale: 2, hasScale: true}
+
+		DescribeTable("returns an error",
+			func(typeName string, gf source.FieldInfo, o numericOptions, expErr error) {
+				_, err := wktgoogleTypeNumeric("Pb", "Go", typeName, gf, false, false, o)
+				Expect(err).To(Equal(expErr))
+			},
+
+			Entry("Money without currency", ".google.type.Money", source.FieldInfo{Type: "decimal.Decimal"}, numericOptions{}, errNoCurrency),
+			Entry("Money into cents without scale", ".google.type.Money", source.FieldInfo{Type: "int64"}, numericOptions{currency: "USD"}, errNoScale),
+			Entry("Decimal into *big.Rat without scale", ".google.type.Decimal", source.FieldInfo{Type: "big.Rat", IsPointer: true}, numericOptions{}, errNoScale),
+		)
+
+		DescribeTable("uses helper functions for unsupported types",
+			func(typeName string, gf source.FieldInfo, p2g, g2p string) {
+				f, err := wktgoogleTypeNumeric("Pb", "Go", typeName, gf, true, false, numericOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*f).To(Equal(Field{
+					Name:          "Go",
+					ProtoName:     "Pb",
+					ProtoToGoType: p2g,
+					GoToProtoType: g2p,
+					UsePackage:    true,
+				}))
+			},
+
+			Entry("Money", ".google.type.Money", source.FieldInfo{Type: "float64"}, "MoneyPtrToFloat64", "Float64ToMoneyPtr"),
+			Entry("big.Rat value", ".google.type.Money", source.FieldInfo{Type: "big.Rat"}, "MoneyPtrToBigRat", "BigRatToMoneyPtr"),
+			Entry("Decimal", ".google.type.Decimal", source.FieldInfo{Type: "float64"}, "DecimalPtrToFloat64", "Float64ToDecimalPtr"),
+		)
+
+		It("converts money into minor units", func() {
+			f, err := wktgoogleTypeNumeric("Price", "Price", ".google.type.Money", source.FieldInfo{Type: "int64"}, false, false, cents)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Imports).To(BeNil())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in money.Money) int64 {
+	v := in
+	if v.CurrencyCode != "USD" {
+		reportError(opts, fmt.Errorf("Price: unexpected currency %q", v.CurrencyCode))
+		return 0
+	}
+	if v.Nanos%10000000 != 0 {
+		reportError(opts, fmt.Errorf("Price: nanos %d has more than 2 fractional digits", v.Nanos))
+		return 0
+	}
+	if v.Units > math.MaxInt64/100 || v.Units < math.MinInt64/100 {
+		reportError(opts, fmt.Errorf("Price: units %d overflows int64", v.Units))
+		return 0
+	}
+	return v.Units*100 + int64(v.Nanos)/10000000
+}(src.Price)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in int64) money.Money {
+	v := in
+	return money.Money{CurrencyCode: "USD", Units: v / 100, Nanos: int32(v % 100 * 10000000)}
+}(src.Price)`))
+		})
+
+		It("converts *big.Rat without dereferencing", func() {
+			f, err := wktgoogleTypeNumeric("Amount", "Amount", ".google.type.Decimal", source.FieldInfo{Type: "big.Rat", IsPointer: true}, true, false, cents)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Imports).To(Equal([]string{pbDecimalImport}))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in *big.Rat) *pbdecimal.Decimal {
+	if in == nil {
+		return nil
+	}
+	v := in
+	s := v.FloatString(2)
+	back, _ := new(big.Rat).SetString(s)
+	if back.Cmp(v) != 0 {
+		reportError(opts, fmt.Errorf("Amount: value %s has more than 2 fractional digits", v.RatString()))
+		return nil
+	}
+	return &pbdecimal.Decimal{Value: s}
+}(src.Amount)`))
+		})
+	})
+
+	It("generated conversions do not panic without error handler", func() {
+		if _, err := exec.LookPath("go"); err != nil {
+			Skip("go command is not available")
+		}
+
+		cents := numericOptions{currency: "USD", scale: 2, hasScale: true}
+		price, err := wktgoogleTypeNumeric("Price", "Price", ".google.type.Money", source.FieldInfo{Type: "int64"}, false, false, cents)
+		Expect(err).NotTo(HaveOccurred())
+		amount, err := wktgoogleTypeNumeric("Amount", "Amount", ".google.type.Money", source.FieldInfo{Type: "big.Rat", IsPointer: true}, false, false, cents)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir, err := os.MkdirTemp(".", "run")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		Expect(os.Mkdir(filepath.Join(dir, "money"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "money", "money.go"), []byte(`package money
+
+type Money struct {
+	CurrencyCode string
+	Units        int64
+	Nanos        int32
+}
+`), 0o644)).To(Succeed())
+
+		main := fmt.Sprintf(`package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/generator/%s/money"
+)
+
+%s
+func main() {
+	var opts []TransformParam
+	src := struct {
+		Price  money.Money
+		Amount *big.Rat
+	}{
+		Price:  money.Money{CurrencyCode: "USD", Units: math.MaxInt64},
+		Amount: big.NewRat(1, 3),
+	}
+
+	price := %s
+	amount := %s
+	fmt.Print(price, " ", amount.Units, " ", amount.Nanos)
+}
+`, filepath.Base(dir), optionsT, price.ProtoToGoExpr, amount.GoToProtoExpr)
+		Expect(os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0o644)).To(Succeed())
+
+		out, err := exec.Command("go", "run", "./"+filepath.Base(dir)).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		Expect(string(out)).To(Equal("0 0 0"))
+	})
+
+	Describe("stringDecimalField", func() {
+
+		DescribeTable("check result",
+			func(gf source.FieldInfo, ok bool, imports []string) {
+				f, found, err := stringDecimalField("Pb", "Go", gf, numericOptions{scale: 2, hasScale: true})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(Equal(ok))
+				if ok {
+					Expect(f.Imports).To(Equal(imports))
+				}
+			},
+
+			Entry("decimal.Decimal", source.FieldInfo{Type: "decimal.Decimal"}, true, []string{shopspringImport}),
+			Entry("*big.Rat", source.FieldInfo{Type: "big.Rat", IsPointer: true}, true, nil),
+			Entry("string", source.FieldInfo{Type: "string"}, false, nil),
+			Entry("int64", source.FieldInfo{Type: "int64"}, false, nil),
+		)
+	})
+})
@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	errNestedType     = errors.New("nested model field should be a structure from models file")
+	errNestedOneof    = errors.New("oneof fields can not be mapped into nested model fields")
+	errNestedPresence = errors.New("presence of non-nullable message can not be checked, nested structure should not be a pointer")
+)
+
+// nestedPath returns value of transformer.map_to option of field fdp if it's
+// a path of nested model field, e.g. "Address.City", or an empty string.
+func nestedPath(fdp *descriptor.FieldDescriptorProto) string {
+	if fdp.Options == nil {
+		return ""
+	}
+
+	mapTo, _ := getStringOption(fdp.Options, options.E_MapTo)
+	if !strings.Contains(mapTo, ".") {
+		return ""
+	}
+
+	return mapTo
+}
+
+// nestedStructure returns copy of model structure goStructFields with fields
+// of nested structures which are referenced by map_to options of fields of
+// message msg. Keys of such fields are paths, e.g. "Address" and
+// "Address.City".
+func nestedStructure(goStructFields source.Structure, msg *descriptor.DescriptorProto, fi fileInfo) (source.Structure, error) {
+	s := source.Structure{}
+	for k, v := range goStructFields {
+		s[k] = v
+	}
+
+	for _, fdp := range msg.Field {
+		path := nestedPath(fdp)
+		if path == "" {
+			continue
+		}
+
+		parts := strings.Split(path, ".")
+		fields := goStructFields
+
+		for i, name := range parts {
+			gf, ok := fields[name]
+			if !ok {
+				return nil, pkgerrors.Wrap(errors.New("field not found in destination structure"), path)
+			}
+			s[strings.Join(parts[:i+1], ".")] = gf
+
+			if i == len(parts)-1 {
+				break
+			}
+
+			if gf.IsSlice || strings.Contains(gf.Type, ".") {
+				return nil, pkgerrors.Wrap(errNestedType, path)
+			}
+
+			st, err := source.Lookup(fi.structs, gf.Type)
+			if err != nil {
+				return nil, pkgerrors.Wrap(errNestedType, path)
+			}
+			fields = st
+		}
+	}
+
+	return s, nil
+}
+
+// nestFields replaces fields which are mapped into fields of nested model
+// structures with fields of these structures. Fields of nested structures
+// keep their order. Pointers to nested structures are allocated only if
+// proto fields have values, see formatNestedField.
+func nestFields(fields []Field, goStructFields source.Structure, fi fileInfo) ([]Field, error) {
+	return groupFields(fields, "", goStructFields, fi)
+}
+
+// groupFields groups fields with names which start with prefix by the next
+// part of their path.
+func groupFields(fields []Field, prefix string, goStructFields source.Structure, fi fileInfo) ([]Field, error) {
+	out := make([]Field, 0, len(fields))
+	groups := map[string]int{}
+
+	for _, f := range fields {
+		rest := strings.TrimPrefix(f.Name, prefix)
+		i := strings.Index(rest, ".")
+		if i < 0 {
+			out = append(out, f)
+			continue
+		}
+
+		if f.IsOneof() || f.OneofField != "" {
+			return nil, pkgerrors.Wrap(errNestedOneof, f.Name)
+		}
+
+		name := prefix + rest[:i]
+		j, ok := groups[name]
+		if !ok {
+			gf := goStructFields[name]
+			out = append(out, Field{
+				Name:        name,
+				GoIsPointer: gf.IsPointer,
+				NestedType:  fi.modelType(gf.Type),
+				Nested:      []Field{},
+			})
+			j = len(out) - 1
+			groups[name] = j
+		}
+
+		out[j].Nested = append(out[j].Nested, f)
+		// Imports of nested fields are collected with imports of their
+		// structure, see fileImports.
+		out[j].Imports = append(out[j].Imports, f.Imports...)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		g := &out[groups[name]]
+
+		nested, err := groupFields(g.Nested, name+".", goStructFields, fi)
+		if err != nil {
+			return nil, err
+		}
+		g.Nested = nested
+
+		if !g.GoIsPointer {
+			continue
+		}
+
+		for _, f := range nestedLeaves(*g, false) {
+			if f.Presence == "" {
+				return nil, pkgerrors.Wrap(errNestedPresence, f.Name)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// nestedLeaves returns fields of nested structure f and its nested structures
+// which are transformed in direction based on swapped flag.
+func nestedLeaves(f Field, swapped bool) []Field {
+	var leaves []Field
+
+	for _, nf := range f.Nested {
+		switch {
+		case nf.Nested != nil:
+			leaves = append(leaves, nestedLeaves(nf, swapped)...)
+		case nf.transformed(swapped):
+			leaves = append(leaves, nf)
+		}
+	}
+
+	return leaves
+}
+
+// formatNestedField returns statements which set fields of nested model
+// structure from proto fields or vice versa. Pointer to nested structure is
+// allocated only if at least one proto field has a value, nil pointer is
+// transformed into proto fields with zero values.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatNestedField(f Field, swapped bool) string {
+	if f.Nested == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	writeNestedField(&b, f, swapped, "\t")
+
+	return b.String()
+}
+
+// writeNestedField writes statements of formatNestedField for nested
+// structure f into b.
+func writeNestedField(b *strings.Builder, f Field, swapped bool, indent string) {
+	leaves := nestedLeaves(f, swapped)
+	if len(leaves) == 0 {
+		return
+	}
+
+	inner := indent
+	if f.GoIsPointer {
+		inner += "\t"
+
+		if swapped {
+			fmt.Fprintf(b, "%sif src.%s != nil {\n", indent, f.Name)
+		} else {
+			conds := make([]string, len(leaves))
+			for i, l := range leaves {
+				conds[i] = fmt.Sprintf(l.Presence, l.srcField(false))
+			}
+			fmt.Fprintf(b, "%sif %s {\n", indent, strings.Join(conds, " || "))
+			fmt.Fprintf(b, "%ss.%s = &%s{}\n", inner, f.Name, f.NestedType)
+		}
+	}
+
+	for _, nf := range f.Nested {
+		switch {
+		case nf.Nested != nil:
+			writeNestedField(b, nf, swapped, inner)
+		case nf.transformed(swapped):
+			fmt.Fprintf(b, "%ss.%s = %s\n", inner, nf.name(!swapped), formatComplexField(nf, swapped))
+		}
+	}
+
+	if f.GoIsPointer {
+		fmt.Fprintf(b, "%s}\n", indent)
+	}
+}
@@ -0,0 +1,175 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Nested", func() {
+	str := descriptor.FieldDescriptorProto_TYPE_STRING
+
+	// field returns string proto field with map_to option if mapTo isn't
+	// empty.
+	field := func(name, mapTo string) *descriptor.FieldDescriptorProto {
+		return newField(name, str, map[*proto.ExtensionDesc]interface{}{options.E_MapTo: mapTo})
+	}
+
+	gf := source.Structure{
+		"Name":    {Type: "string"},
+		"Address": {Type: "Address"},
+		"Billing": {Type: "Billing", IsPointer: true},
+		"Tags":    {Type: "Tag", IsSlice: true},
+		"Owner":   {Type: "users.User"},
+	}
+
+	fi := fileInfo{
+		repoPackage: "model",
+		structs: source.StructureList{
+			"Address": {"City": {Type: "string"}},
+			"Billing": {"Zip": {Type: "string"}, "Geo": {Type: "Geo", IsPointer: true}},
+			"Geo":     {"Lat": {Type: "float64"}},
+		},
+	}
+
+	DescribeTable("nestedPath",
+		func(fdp *descriptor.FieldDescriptorProto, expected string) {
+			Expect(nestedPath(fdp)).To(Equal(expected))
+		},
+
+		Entry("Without options", field("city", ""), ""),
+		Entry("Model field", field("city", "Town"), ""),
+		Entry("Nested model field", field("city", "Address.City"), "Address.City"),
+	)
+
+	It("nestedStructure adds fields of nested structures", func() {
+		msg := &descriptor.DescriptorProto{Field: []*descriptor.FieldDescriptorProto{
+			field("name", ""),
+			field("city", "Address.City"),
+			field("lat", "Billing.Geo.Lat"),
+		}}
+
+		s, err := nestedStructure(gf, msg, fi)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s).To(HaveKeyWithValue("Address.City", source.FieldInfo{Type: "string"}))
+		Expect(s).To(HaveKeyWithValue("Billing.Geo", source.FieldInfo{Type: "Geo", IsPointer: true}))
+		Expect(s).To(HaveKeyWithValue("Billing.Geo.Lat", source.FieldInfo{Type: "float64"}))
+		Expect(gf).NotTo(HaveKey("Address.City"))
+	})
+
+	DescribeTable("nestedStructure returns an error",
+		func(mapTo string, expected interface{}) {
+			msg := &descriptor.DescriptorProto{Field: []*descriptor.FieldDescriptorProto{field("city", mapTo)}}
+
+			_, err := nestedStructure(gf, msg, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(expected))
+		},
+
+		Entry("Field not found", "Address.Town", "field not found in destination structure"),
+		Entry("Slice", "Tags.Name", errNestedType),
+		Entry("Structure from other package", "Owner.Name", errNestedType),
+		Entry("Not a structure", "Name.First", errNestedType),
+	)
+
+	Describe("nestFields", func() {
+		s := source.Structure{
+			"Address":     {Type: "Address"},
+			"Billing":     {Type: "Billing", IsPointer: true},
+			"Billing.Geo": {Type: "Geo", IsPointer: true},
+		}
+
+		It("groups fields by nested structures", func() {
+			fields, err := nestFields([]Field{
+				{Name: "Name"},
+				{Name: "Address.City", Imports: []string{`"a"`}},
+				{Name: "Billing.Zip", Presence: `%s != ""`},
+				{Name: "Address.Street"},
+				{Name: "Billing.Geo.Lat", Presence: "%s != 0"},
+			}, s, fi)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fields).To(Equal([]Field{
+				{Name: "Name"},
+				{
+					Name:       "Address",
+					NestedType: "model.Address",
+					Imports:    []string{`"a"`},
+					Nested:     []Field{{Name: "Address.City", Imports: []string{`"a"`}}, {Name: "Address.Street"}},
+				},
+				{
+					Name:        "Billing",
+					GoIsPointer: true,
+					NestedType:  "model.Billing",
+					Nested: []Field{
+						{Name: "Billing.Zip", Presence: `%s != ""`},
+						{
+							Name:        "Billing.Geo",
+							GoIsPointer: true,
+							NestedType:  "model.Geo",
+							Nested:      []Field{{Name: "Billing.Geo.Lat", Presence: "%s != 0"}},
+						},
+					},
+				},
+			}))
+		})
+
+		It("returns an error for oneof fields", func() {
+			_, err := nestFields([]Field{{Name: "Address.City", OneofDecl: "code"}}, s, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errNestedOneof))
+		})
+
+		It("returns an error for fields of pointers without presence", func() {
+			_, err := nestFields([]Field{{Name: "Billing.Zip"}}, s, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(errNestedPresence))
+		})
+	})
+
+	DescribeTable("formatNestedField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatNestedField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Not nested", Field{Name: "Name", ProtoName: "Name"}, false, ""),
+		Entry("Structure, proto to Go", Field{
+			Name:   "Address",
+			Nested: []Field{{Name: "Address.City", ProtoName: "City"}},
+		}, false, "\ts.Address.City = src.City\n"),
+		Entry("Structure, Go to proto", Field{
+			Name:   "Address",
+			Nested: []Field{{Name: "Address.City", ProtoName: "City"}},
+		}, true, "\ts.City = src.Address.City\n"),
+		Entry("Pointer, proto to Go", Field{
+			Name:        "Billing",
+			GoIsPointer: true,
+			NestedType:  "model.Billing",
+			Nested: []Field{
+				{Name: "Billing.Zip", ProtoName: "Zip", Presence: `%s != ""`},
+				{Name: "Billing.Geo", GoIsPointer: true, NestedType: "model.Geo", Nested: []Field{
+					{Name: "Billing.Geo.Lat", ProtoName: "Lat", Presence: "%s != 0"},
+				}},
+			},
+		}, false, "\tif src.Zip != \"\" || src.Lat != 0 {\n\t\ts.Billing = &model.Billing{}\n\t\ts.Billing.Zip = src.Zip\n"+
+			"\t\tif src.Lat != 0 {\n\t\t\ts.Billing.Geo = &model.Geo{}\n\t\t\ts.Billing.Geo.Lat = src.Lat\n\t\t}\n\t}\n"),
+		Entry("Pointer, Go to proto", Field{
+			Name:        "Billing",
+			GoIsPointer: true,
+			Nested:      []Field{{Name: "Billing.Zip", ProtoName: "Zip", Presence: `%s != ""`}},
+		}, true, "\tif src.Billing != nil {\n\t\ts.Zip = src.Billing.Zip\n\t}\n"),
+		Entry("Fields of other direction", Field{
+			Name:        "Billing",
+			GoIsPointer: true,
+			Nested:      []Field{{Name: "Billing.Zip", ProtoName: "Zip", Presence: `%s != ""`, Direction: options.Direction_MODEL_TO_PB}},
+		}, false, ""),
+	)
+
+	It("formatField skips nested structures", func() {
+		f := Field{Name: "Address", Nested: []Field{}}
+		Expect(formatField(f, false, "model")).To(BeEmpty())
+		Expect(formatField(f, true, "pb")).To(BeEmpty())
+	})
+})
@@ -0,0 +1,306 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+// nullType describes nullable Go type such as sql.NullString. All supported
+// types have a field Valid which is false for NULL values.
+type nullType struct {
+	// Name of field which contains value, e.g. "String" for sql.NullString.
+	field string
+	// Go type of value.
+	valueType string
+}
+
+var (
+	// nullTypes contains nullable types, first key is an import path of
+	// package, second one is a type name.
+	nullTypes = map[string]map[string]nullType{
+		"database/sql": {
+			"NullString":  {"String", "string"},
+			"NullInt64":   {"Int64", "int64"},
+			"NullInt32":   {"Int32", "int32"},
+			"NullInt16":   {"Int16", "int16"},
+			"NullByte":    {"Byte", "byte"},
+			"NullFloat64": {"Float64", "float64"},
+			"NullBool":    {"Bool", "bool"},
+			"NullTime":    {"Time", "time.Time"},
+		},
+		"github.com/jackc/pgx/v5/pgtype": {
+			"Text":        {"String", "string"},
+			"Int8":        {"Int64", "int64"},
+			"Int4":        {"Int32", "int32"},
+			"Int2":        {"Int16", "int16"},
+			"Float8":      {"Float64", "float64"},
+			"Float4":      {"Float32", "float32"},
+			"Bool":        {"Bool", "bool"},
+			"Timestamptz": {"Time", "time.Time"},
+			"Timestamp":   {"Time", "time.Time"},
+			"Date":        {"Time", "time.Time"},
+		},
+		"github.com/gobuffalo/nulls": {
+			"String":  {"String", "string"},
+			"Int":     {"Int", "int"},
+			"Int32":   {"Int32", "int32"},
+			"Int64":   {"Int64", "int64"},
+			"UInt32":  {"UInt32", "uint32"},
+			"Float32": {"Float32", "float32"},
+			"Float64": {"Float64", "float64"},
+			"Bool":    {"Bool", "bool"},
+			"Time":    {"Time", "time.Time"},
+		},
+	}
+
+	// nullPackages contains import paths of packages which are used if
+	// models file has no import for package name.
+	nullPackages = map[string]string{
+		"sql":    "database/sql",
+		"pgtype": "github.com/jackc/pgx/v5/pgtype",
+		"nulls":  "github.com/gobuffalo/nulls",
+	}
+
+	// pbGoTypes contains Go types of proto scalar fields.
+	pbGoTypes = map[descriptor.FieldDescriptorProto_Type]string{
+		descriptor.FieldDescriptorProto_TYPE_STRING:   "string",
+		descriptor.FieldDescriptorProto_TYPE_BYTES:    "[]byte",
+		descriptor.FieldDescriptorProto_TYPE_BOOL:     "bool",
+		descriptor.FieldDescriptorProto_TYPE_INT32:    "int32",
+		descriptor.FieldDescriptorProto_TYPE_SINT32:   "int32",
+		descriptor.FieldDescriptorProto_TYPE_INT64:    "int64",
+		descriptor.FieldDescriptorProto_TYPE_SINT64:   "int64",
+		descriptor.FieldDescriptorProto_TYPE_UINT32:   "uint32",
+		descriptor.FieldDescriptorProto_TYPE_UINT64:   "uint64",
+		descriptor.FieldDescriptorProto_TYPE_FIXED32:  "uint32",
+		descriptor.FieldDescriptorProto_TYPE_FIXED64:  "uint64",
+		descriptor.FieldDescriptorProto_TYPE_SFIXED32: "int32",
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64: "int64",
+		descriptor.FieldDescriptorProto_TYPE_FLOAT:    "float32",
+		descriptor.FieldDescriptorProto_TYPE_DOUBLE:   "float64",
+	}
+
+	// wrapperTypes contains Go types of google.protobuf wrappers values.
+	wrapperTypes = map[string]string{
+		".google.protobuf.StringValue": "string",
+		".google.protobuf.BytesValue":  "[]byte",
+		".google.protobuf.BoolValue":   "bool",
+		".google.protobuf.Int32Value":  "int32",
+		".google.protobuf.Int64Value":  "int64",
+		".google.protobuf.UInt32Value": "uint32",
+		".google.protobuf.UInt64Value": "uint64",
+		".google.protobuf.FloatValue":  "float32",
+		".google.protobuf.DoubleValue": "float64",
+	}
+)
+
+// importSpec returns import spec for package with given import path which is
+// used with name pkg.
+func importSpec(pkg, path string) string {
+	if pkgName(path) == pkg {
+		return fmt.Sprintf("%q", path)
+	}
+
+	return fmt.Sprintf("%s %q", pkg, path)
+}
+
+// pkgName returns last element of import path without major version suffix,
+// e.g. "uuid" for github.com/gofrs/uuid/v5.
+func pkgName(path string) string {
+	p := majorVersion.ReplaceAllString(path, "")
+	return lastName(strings.Replace(p, "/", ".", -1))
+}
+
+// nullableType returns description of nullable model type and import spec for
+// its package. The last return value is false if model type is not nullable
+// or pointer or slice.
+func nullableType(gf source.FieldInfo) (nullType, string, bool) {
+	i := strings.Index(gf.Type, ".")
+	if i < 1 || gf.IsPointer || gf.IsSlice {
+		return nullType{}, "", false
+	}
+
+	pkg, name := gf.Type[:i], gf.Type[i+1:]
+
+	path := gf.PkgPath
+	if path == "" {
+		path = nullPackages[pkg]
+	}
+
+	// Generic type sql.Null[T].
+	if path == "database/sql" && strings.HasPrefix(name, "Null[") && strings.HasSuffix(name, "]") {
+		return nullType{field: "V", valueType: name[5 : len(name)-1]}, importSpec(pkg, path), true
+	}
+
+	nt, ok := nullTypes[path][name]
+	if !ok {
+		return nullType{}, "", false
+	}
+
+	return nt, importSpec(pkg, path), true
+}
+
+// isNullType returns true if model field has one of supported nullable types.
+func isNullType(gf source.FieldInfo) bool {
+	_, _, ok := nullableType(gf)
+	return ok
+}
+
+// isNumber returns true if Go type is one of integer or float types.
+func isNumber(t string) bool {
+	switch t {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "float32", "float64":
+		return true
+	}
+
+	return false
+}
+
+// convertExpr returns expression which converts value of type from into type
+// to or an empty string if types can not be converted.
+func convertExpr(expr, from, to string) string {
+	switch {
+	case from == to:
+		return expr
+	case isNumber(from) && isNumber(to):
+		return fmt.Sprintf("%s(%s)", to, expr)
+	}
+
+	return ""
+}
+
+// zeroValue returns zero value of Go type of proto scalar.
+func zeroValue(t string) string {
+	switch t {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	case "[]byte":
+		return "nil"
+	}
+
+	return "0"
+}
+
+// zeroCond returns condition which is true if value v of Go type t of proto
+// scalar is zero.
+func zeroCond(v, t string) string {
+	switch t {
+	case "bool":
+		return "!" + v
+	case "[]byte":
+		return fmt.Sprintf("len(%s) == 0", v)
+	}
+
+	return v + " == " + zeroValue(t)
+}
+
+// nullableConv returns conversion between proto value and nullable model
+// type. Value is an expression for proto value of Go type pbType, wrap is a
+// format which wraps model value into proto value. If zero is true, zero
+// proto values are converted into NULL values, otherwise only nil values are
+// NULLs. The last return value is false if types can not be converted.
+func nullableConv(gf source.FieldInfo, nt nullType, spec, value, pbType, wrap string, zero bool) (googleTypeConv, bool) {
+	toGo := convertExpr(value, pbType, nt.valueType)
+	toPb := convertExpr("v."+nt.field, nt.valueType, pbType)
+	if toGo == "" || toPb == "" {
+		return googleTypeConv{}, false
+	}
+
+	conv := googleTypeConv{
+		goZero:    gf.Type + "{}",
+		toGo:      fmt.Sprintf("%s{%s: %s, Valid: true}", gf.Type, nt.field, toGo),
+		toPbSteps: []step{{cond: "!v.Valid"}},
+		toPb:      fmt.Sprintf(wrap, toPb),
+		imports:   []string{spec},
+	}
+
+	if zero {
+		conv.toGoSteps = []step{{cond: zeroCond(value, pbType)}}
+	}
+
+	return conv, true
+}
+
+// nullableScalarField returns *Field for proto scalar field and nullable model
+// field. If pnullable is true, proto field is a pointer, e.g. optional field
+// of proto2 file, and nil is converted into NULL, otherwise zero value is
+// converted into NULL. The second return value is false if fields are not
+// supported.
+func nullableScalarField(pname, gname string, gf source.FieldInfo, fdp *descriptor.FieldDescriptorProto, pnullable bool) (*Field, bool) {
+	nt, spec, ok := nullableType(gf)
+	if !ok || fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return nil, false
+	}
+
+	pbType, ok := pbGoTypes[fdp.GetType()]
+	if !ok {
+		return nil, false
+	}
+
+	conv, ok := nullableConv(gf, nt, spec, "v", pbType, "%s", !pnullable)
+	if !ok {
+		return nil, false
+	}
+
+	gt := googleType{pbType: pbType, pbZero: zeroValue(pbType), scalar: true}
+
+	return inlineField(pname, gname, gt, conv, gf, pnullable, false), true
+}
+
+// nullableMessageField returns *Field for google.protobuf.Timestamp or one of
+// google.protobuf wrappers field and nullable model field. The second return
+// value is false if fields are not supported.
+func nullableMessageField(pname, gname, typeName string, gf source.FieldInfo, pnullable bool) (*Field, bool) {
+	nt, spec, ok := nullableType(gf)
+	if !ok {
+		return nil, false
+	}
+
+	var (
+		gt   googleType
+		conv googleTypeConv
+	)
+
+	if typeName == ".google.protobuf.Timestamp" {
+		if nt.valueType != "time.Time" {
+			return nil, false
+		}
+
+		gt = timestampType
+		conv = googleTypeConv{
+			goZero:    gf.Type + "{}",
+			toGo:      fmt.Sprintf("%s{%s: time.Unix(v.Seconds, int64(v.Nanos)).UTC(), Valid: true}", gf.Type, nt.field),
+			toPbSteps: []step{{cond: "!v.Valid"}},
+			toPb:      fmt.Sprintf("types.Timestamp{Seconds: v.%s.Unix(), Nanos: int32(v.%s.Nanosecond())}", nt.field, nt.field),
+			imports:   []string{spec},
+		}
+
+		if !pnullable {
+			conv.toGoSteps = []step{{cond: "v.Seconds == 0 && v.Nanos == 0"}}
+		}
+	} else {
+		pbType, found := wrapperTypes[typeName]
+		if !found {
+			return nil, false
+		}
+
+		name := lastName(typeName)
+		gt = googleType{
+			pbType:  "types." + name,
+			pbZero:  "types." + name + "{}",
+			imports: []string{gogoTypesImport},
+		}
+
+		// Non-nullable wrappers have no nil values, so zero values are NULLs.
+		if conv, ok = nullableConv(gf, nt, spec, "v.Value", pbType, gt.pbType+"{Value: %s}", !pnullable); !ok {
+			return nil, false
+		}
+	}
+
+	return inlineField(pname, gname, gt, conv, gf, pnullable, false), true
+}
@@ -0,0 +1,149 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Nullable", func() {
+
+	DescribeTable("nullableType",
+		func(gf source.FieldInfo, expected nullType, spec string, ok bool) {
+			nt, s, found := nullableType(gf)
+			Expect(found).To(Equal(ok))
+			Expect(nt).To(Equal(expected))
+			Expect(s).To(Equal(spec))
+		},
+
+		Entry("sql.NullString", source.FieldInfo{Type: "sql.NullString", PkgPath: "database/sql"},
+			nullType{"String", "string"}, `"database/sql"`, true),
+		Entry("Generic sql.Null", source.FieldInfo{Type: "sql.Null[time.Time]", PkgPath: "database/sql"},
+			nullType{"V", "time.Time"}, `"database/sql"`, true),
+		Entry("pgtype without import", source.FieldInfo{Type: "pgtype.Timestamptz"},
+			nullType{"Time", "time.Time"}, `"github.com/jackc/pgx/v5/pgtype"`, true),
+		Entry("nulls with alias", source.FieldInfo{Type: "n.String", PkgPath: "github.com/gobuffalo/nulls"},
+			nullType{"String", "string"}, `n "github.com/gobuffalo/nulls"`, true),
+		Entry("Pointer", source.FieldInfo{Type: "sql.NullString", IsPointer: true}, nullType{}, "", false),
+		Entry("Unknown type", source.FieldInfo{Type: "sql.RawBytes"}, nullType{}, "", false),
+		Entry("Unknown package", source.FieldInfo{Type: "my.NullString", PkgPath: "example.com/my"}, nullType{}, "", false),
+		Entry("Not a nullable type", source.FieldInfo{Type: "string"}, nullType{}, "", false),
+	)
+
+	Describe("nullableScalarField", func() {
+		var (
+			str = descriptor.FieldDescriptorProto_TYPE_STRING
+			i32 = descriptor.FieldDescriptorProto_TYPE_INT32
+			rep = descriptor.FieldDescriptorProto_LABEL_REPEATED
+		)
+
+		DescribeTable("skips unsupported fields",
+			func(gf source.FieldInfo, fdp *descriptor.FieldDescriptorProto) {
+				f, ok := nullableScalarField("Pb", "Go", gf, fdp, false)
+				Expect(ok).To(BeFalse())
+				Expect(f).To(BeNil())
+			},
+
+			Entry("String into integer", source.FieldInfo{Type: "sql.NullInt64"}, &descriptor.FieldDescriptorProto{Type: &str}),
+			Entry("Integer into time", source.FieldInfo{Type: "sql.NullTime"}, &descriptor.FieldDescriptorProto{Type: &i32}),
+			Entry("Repeated field", source.FieldInfo{Type: "sql.NullString"}, &descriptor.FieldDescriptorProto{Type: &str, Label: &rep}),
+		)
+
+		It("converts zero values into NULLs", func() {
+			f, ok := nullableScalarField("Age", "Age", source.FieldInfo{Type: "sql.NullInt64", PkgPath: "database/sql"}, &descriptor.FieldDescriptorProto{Type: &i32}, false)
+			Expect(ok).To(BeTrue())
+			Expect(f.Imports).To(Equal([]string{`"database/sql"`}))
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in int32) sql.NullInt64 {
+	v := in
+	if v == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(v), Valid: true}
+}(src.Age)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in sql.NullInt64) int32 {
+	v := in
+	if !v.Valid {
+		return 0
+	}
+	return int32(v.Int64)
+}(src.Age)`))
+		})
+
+		It("converts nil pointers into NULLs", func() {
+			f, ok := nullableScalarField("Name", "Name", source.FieldInfo{Type: "pgtype.Text"}, &descriptor.FieldDescriptorProto{Type: &str}, true)
+			Expect(ok).To(BeTrue())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in *string) pgtype.Text {
+	if in == nil {
+		return pgtype.Text{}
+	}
+	v := *in
+	return pgtype.Text{String: v, Valid: true}
+}(src.Name)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in pgtype.Text) *string {
+	v := in
+	if !v.Valid {
+		return nil
+	}
+	r := v.String
+	return &r
+}(src.Name)`))
+		})
+	})
+
+	Describe("nullableMessageField", func() {
+
+		DescribeTable("skips unsupported fields",
+			func(typeName string, gf source.FieldInfo) {
+				f, ok := nullableMessageField("Pb", "Go", typeName, gf, true)
+				Expect(ok).To(BeFalse())
+				Expect(f).To(BeNil())
+			},
+
+			Entry("Timestamp into string", ".google.protobuf.Timestamp", source.FieldInfo{Type: "sql.NullString"}),
+			Entry("StringValue into time", ".google.protobuf.StringValue", source.FieldInfo{Type: "sql.NullTime"}),
+			Entry("Other message", ".google.protobuf.Duration", source.FieldInfo{Type: "sql.NullInt64"}),
+			Entry("Not a nullable type", ".google.protobuf.Timestamp", source.FieldInfo{Type: "time.Time"}),
+		)
+
+		It("converts Timestamp into sql.Null[time.Time]", func() {
+			f, ok := nullableMessageField("CreatedAt", "CreatedAt", ".google.protobuf.Timestamp", source.FieldInfo{Type: "sql.Null[time.Time]"}, true)
+			Expect(ok).To(BeTrue())
+			Expect(f.Imports).To(Equal([]string{gogoTypesImport, `"database/sql"`}))
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in *types.Timestamp) sql.Null[time.Time] {
+	if in == nil {
+		return sql.Null[time.Time]{}
+	}
+	v := in
+	return sql.Null[time.Time]{V: time.Unix(v.Seconds, int64(v.Nanos)).UTC(), Valid: true}
+}(src.CreatedAt)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in sql.Null[time.Time]) *types.Timestamp {
+	v := in
+	if !v.Valid {
+		return nil
+	}
+	return &types.Timestamp{Seconds: v.V.Unix(), Nanos: int32(v.V.Nanosecond())}
+}(src.CreatedAt)`))
+		})
+
+		It("converts non-nullable wrapper with zero value into NULL", func() {
+			f, ok := nullableMessageField("Count", "Count", ".google.protobuf.Int64Value", source.FieldInfo{Type: "sql.NullInt32"}, false)
+			Expect(ok).To(BeTrue())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in types.Int64Value) sql.NullInt32 {
+	v := in
+	if v.Value == 0 {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(v.Value), Valid: true}
+}(src.Count)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in sql.NullInt32) types.Int64Value {
+	v := in
+	if !v.Valid {
+		return types.Int64Value{}
+	}
+	return types.Int64Value{Value: int64(v.Int32)}
+}(src.Count)`))
+		})
+	})
+})
@@ -175,22 +175,96 @@ func gtTopt(s string, dst *dst_pref.pt, v string) {
 	headerOne = `// Code generated by protoc-gen-struct-transformer, version: v1.1.1. DO NOT EDIT.
 
 package one
+import "errors"
+
 var version string
 
+// ErrMaxDepth is passed to error handler for messages which are nested deeper
+// than maximal depth set by WithMaxDepth.
+var ErrMaxDepth = errors.New("maximal depth of nested messages exceeded")
+
 // TransformParam is a function option type.
-type TransformParam func()
+type TransformParam func(*transformOptions)
+
+// transformOptions contains values set by TransformParam functions.
+type transformOptions struct {
+	errorHandler func(error)
+	maxDepth     int
+	depth        int
+}
 
 // WithVersion sets global version variable.
 func WithVersion(v string) TransformParam {
-	return func() {
+	return func(*transformOptions) {
 		version = v
 	}
 }
 
-func applyOptions(opts ...TransformParam) {
-	for _, o := range opts {
-		o()
+// WithErrorHandler sets a function which is called for each value which can
+// not be transformed, e.g. invalid date. Such values are replaced with zero
+// values.
+func WithErrorHandler(h func(error)) TransformParam {
+	return func(o *transformOptions) {
+		o.errorHandler = h
+	}
+}
+
+func applyOptions(opts ...TransformParam) transformOptions {
+	o := transformOptions{}
+	for _, opt := range opts {
+		opt(&o)
 	}
+
+	return o
+}
+
+// reportError passes err to error handler if it's set.
+func reportError(opts []TransformParam, err error) {
+	if h := applyOptions(opts...).errorHandler; h != nil {
+		h(err)
+	}
+}
+
+// WithMaxDepth limits nesting of transformed messages, e.g. for recursive
+// messages received from untrusted clients. Top-level message has depth 1,
+// messages nested deeper than n are replaced with empty messages and
+// ErrMaxDepth is passed to error handler. Depth is not limited if n is not
+// positive.
+func WithMaxDepth(n int) TransformParam {
+	return func(o *transformOptions) {
+		o.maxDepth = n
+	}
+}
+
+// withDepth sets depth of transformed message.
+func withDepth(d int) TransformParam {
+	return func(o *transformOptions) {
+		o.depth = d
+	}
+}
+
+// nestOptions returns options for sub messages of transformed message with
+// incremented depth. It returns false and passes ErrMaxDepth to error handler
+// if transformed message is nested deeper than maximal depth. Depth is set by
+// the last option of nested messages, it's replaced, so number of options
+// doesn't grow with depth.
+func nestOptions(opts []TransformParam) ([]TransformParam, bool) {
+	o := applyOptions(opts...)
+	if o.maxDepth <= 0 {
+		return opts, true
+	}
+
+	if o.depth >= o.maxDepth {
+		reportError(opts, ErrMaxDepth)
+		return opts, false
+	}
+
+	n := len(opts)
+	if o.depth > 0 {
+		n--
+	}
+
+	return append(opts[:n:n], withDepth(o.depth+1)), true
 }
 
 
@@ -80,6 +80,30 @@ func getBoolOption(m proto.Message, opt *proto.ExtensionDesc) bool {
 	return *option
 }
 
+// getInt32Option return any option of int32 type for proto.Message. If
+// option exists but has different type, function returns an error.
+func getInt32Option(m proto.Message, opt *proto.ExtensionDesc) (int32, error) {
+	if m == nil {
+		return 0, ErrNilOptions
+	}
+
+	if !proto.HasExtension(m, opt) {
+		return 0, newErrOptionNotExists(opt.Name)
+	}
+
+	ext, err := proto.GetExtension(m, opt)
+	if err != nil {
+		return 0, err
+	}
+
+	option, ok := ext.(*int32)
+	if !ok {
+		return 0, fmt.Errorf("extension is %T; want an *int32", ext)
+	}
+
+	return *option, nil
+}
+
 // extractEmbedOption returns true if proto.Message has an option
 // transformer.embed which equals to true.
 func extractEmbedOption(m proto.Message) bool {
@@ -92,6 +116,26 @@ func extractSkipOption(m proto.Message) bool {
 	return getBoolOption(m, options.E_Skip)
 }
 
+// extractDirectionOption returns value of transformer.direction option or
+// options.Direction_BOTH if option does not exist.
+func extractDirectionOption(m proto.Message) options.Direction {
+	if m == nil || !proto.HasExtension(m, options.E_Direction) {
+		return options.Direction_BOTH
+	}
+
+	ext, err := proto.GetExtension(m, options.E_Direction)
+	if err != nil {
+		return options.Direction_BOTH
+	}
+
+	option, ok := ext.(*options.Direction)
+	if !ok {
+		return options.Direction_BOTH
+	}
+
+	return *option
+}
+
 // extractNullOption returns true if Field has a gogoproto.nullable option which
 // equals to true.
 func extractNullOption(f *descriptor.FieldDescriptorProto) bool {
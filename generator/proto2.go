@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+// errRequiredValue is returned for required scalar fields which are not
+// pointers, missing values of such fields can not be detected.
+var errRequiredValue = errors.New("required field is not a pointer, missing value can not be detected: use gogofaster=false parameter or gogoproto.nullable = true option")
+
+// Go expressions for special float values of proto2 defaults.
+var floatDefaults = map[string]string{
+	"inf":  "math.Inf(1)",
+	"-inf": "math.Inf(-1)",
+	"nan":  "math.NaN()",
+}
+
+// isPointerScalar returns true if Go type of proto scalar field is a pointer.
+// Optional and required scalar fields of proto2 files are pointers, except
+// bytes fields and fields with gogoproto.nullable = false. If structures are
+// generated by protoc-gen-gogofaster, only fields with default values or with
+// explicit gogoproto.nullable = true are pointers. Fields of editions files
+// are pointers if they have explicit presence. Structures generated by
+// protoc-gen-go ignore gogoproto options and have no pointer oneof members.
+func isPointerScalar(fdp *descriptor.FieldDescriptorProto, fi fileInfo) bool {
+	if !fi.hasPresence(fdp) || fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+
+	if fi.golang && isOneofMember(fdp) {
+		return false
+	}
+
+	if fi.gogofaster && !fi.editions && fdp.DefaultValue == nil && (fdp.Options == nil || !proto.HasExtension(fdp.Options, gogoproto.E_Nullable)) {
+		return false
+	}
+
+	switch fdp.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE,
+		descriptor.FieldDescriptorProto_TYPE_GROUP,
+		descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return false
+	}
+
+	return fi.nullable(fdp)
+}
+
+// defaultValue returns Go expression of type goType for default value of proto
+// field declared as [default = ...]. If default value is not declared, zero
+// value of goType is returned.
+func defaultValue(fdp *descriptor.FieldDescriptorProto, pbType, goType string) (string, error) {
+	if fdp.DefaultValue == nil {
+		return zeroValue(goType), nil
+	}
+
+	d := fdp.GetDefaultValue()
+
+	switch pbType {
+	case "string":
+		return strconv.Quote(d), nil
+	case "bool":
+		if _, err := strconv.ParseBool(d); err != nil {
+			return "", fmt.Errorf("invalid default value %q", d)
+		}
+		return d, nil
+	case "float32", "float64":
+		if e, ok := floatDefaults[d]; ok {
+			return convertExpr(e, "float64", goType), nil
+		}
+		if _, err := strconv.ParseFloat(d, 64); err != nil {
+			return "", fmt.Errorf("invalid default value %q", d)
+		}
+		return d, nil
+	}
+
+	if _, err := strconv.ParseInt(d, 0, 64); err != nil {
+		if _, err := strconv.ParseUint(d, 0, 64); err != nil {
+			return "", fmt.Errorf("invalid default value %q", d)
+		}
+	}
+
+	return d, nil
+}
+
+// proto2Field returns *Field for pointer scalar field of proto2 file and value
+// or pointer model field. Nil proto values are converted into default values
+// of value model fields and into nil pointers. The second return value is
+// false if model type can not be converted into proto type, such fields are
+// converted by helper functions. Numeric fields of types other than default
+// pairs of table are converted with range checks, see numericConv.
+func proto2Field(pname, gname string, gf source.FieldInfo, fdp *descriptor.FieldDescriptorProto, table map[descriptor.FieldDescriptorProto_Type]typeRel) (*Field, bool, error) {
+	pbType, ok := pbGoTypes[fdp.GetType()]
+	if !ok || gf.IsSlice {
+		return nil, false, nil
+	}
+
+	toGo := convertExpr("v", pbType, gf.Type)
+	toPb := convertExpr("v", gf.Type, pbType)
+	if toGo == "" || toPb == "" {
+		return nil, false, nil
+	}
+
+	zero, err := defaultValue(fdp, pbType, gf.Type)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var f *Field
+	if isNumber(pbType) && !isDefaultPair(fdp.GetType(), gf.Type, table) {
+		f = numericField(pname, gname, pbType, zero, gf, true)
+	} else {
+		gt := googleType{pbType: pbType, pbZero: "nil", scalar: true}
+		conv := googleTypeConv{goZero: zero, toGo: toGo, toPb: toPb}
+
+		f = inlineField(pname, gname, gt, conv, gf, true, false)
+	}
+	f.ProtoIsPointer = true
+	f.GoIsPointer = gf.IsPointer
+
+	return f, true, nil
+}
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"errors"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Proto2", func() {
+	var (
+		str = descriptor.FieldDescriptorProto_TYPE_STRING
+		i32 = descriptor.FieldDescriptorProto_TYPE_INT32
+		dbl = descriptor.FieldDescriptorProto_TYPE_DOUBLE
+		byt = descriptor.FieldDescriptorProto_TYPE_BYTES
+		opt = descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+		req = descriptor.FieldDescriptorProto_LABEL_REQUIRED
+		rep = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	DescribeTable("isPointerScalar",
+		func(fdp *descriptor.FieldDescriptorProto, fi fileInfo, nullable *bool, expected bool) {
+			if nullable != nil {
+				fdp.Options = &descriptor.FieldOptions{}
+				err := proto.SetExtension(fdp.Options, gogoproto.E_Nullable, nullable)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(isPointerScalar(fdp, fi)).To(Equal(expected))
+		},
+
+		Entry("Optional field", &descriptor.FieldDescriptorProto{Type: &i32, Label: &opt}, fileInfo{proto2: true}, nil, true),
+		Entry("Required field", &descriptor.FieldDescriptorProto{Type: &str, Label: &req}, fileInfo{proto2: true}, nil, true),
+		Entry("proto3 file", &descriptor.FieldDescriptorProto{Type: &i32, Label: &opt}, fileInfo{}, nil, false),
+		Entry("Repeated field", &descriptor.FieldDescriptorProto{Type: &i32, Label: &rep}, fileInfo{proto2: true}, nil, false),
+		Entry("Bytes field", &descriptor.FieldDescriptorProto{Type: &byt, Label: &opt}, fileInfo{proto2: true}, nil, false),
+		Entry("Not nullable field", &descriptor.FieldDescriptorProto{Type: &i32, Label: &opt}, fileInfo{proto2: true}, bp(false), false),
+		Entry("gogofaster, no default value", &descriptor.FieldDescriptorProto{Type: &i32, Label: &opt}, fileInfo{proto2: true, gogofaster: true}, nil, false),
+		Entry("gogofaster, default value", &descriptor.FieldDescriptorProto{Type: &i32, Label: &opt, DefaultValue: sp("1")}, fileInfo{proto2: true, gogofaster: true}, nil, true),
+		Entry("gogofaster, nullable field", &descriptor.FieldDescriptorProto{Type: &str, Label: &req}, fileInfo{proto2: true, gogofaster: true}, bp(true), true),
+	)
+
+	DescribeTable("defaultValue",
+		func(def *string, pbType, goType, expected string, expectedErr error) {
+			v, err := defaultValue(&descriptor.FieldDescriptorProto{DefaultValue: def}, pbType, goType)
+			if expectedErr != nil {
+				Expect(err).To(Equal(expectedErr))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(expected))
+		},
+
+		Entry("No default", nil, "int32", "int", "0", nil),
+		Entry("No default string", nil, "string", "string", `""`, nil),
+		Entry("String", proto.String(`say "hi"`), "string", "string", `"say \"hi\""`, nil),
+		Entry("Integer", proto.String("-42"), "int64", "int", "-42", nil),
+		Entry("Unsigned integer", proto.String("18446744073709551615"), "uint64", "uint64", "18446744073709551615", nil),
+		Entry("Float", proto.String("1.5e3"), "float64", "float64", "1.5e3", nil),
+		Entry("Infinity", proto.String("-inf"), "float32", "float32", "float32(math.Inf(-1))", nil),
+		Entry("NaN", proto.String("nan"), "float64", "float64", "math.NaN()", nil),
+		Entry("Bool", proto.String("true"), "bool", "bool", "true", nil),
+		Entry("Invalid integer", proto.String("x"), "int32", "int32", "", errors.New(`invalid default value "x"`)),
+	)
+
+	Describe("proto2Field", func() {
+
+		It("skips model types which can not be converted", func() {
+			f, ok, err := proto2Field("Pb", "Go", source.FieldInfo{Type: "time.Duration"}, &descriptor.FieldDescriptorProto{Type: &i32}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(f).To(BeNil())
+		})
+
+		It("converts pointer into value with default", func() {
+			f, ok, err := proto2Field("Count", "Count", source.FieldInfo{Type: "int"}, &descriptor.FieldDescriptorProto{Type: &i32, DefaultValue: proto.String("10")}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(f.ProtoIsPointer).To(BeTrue())
+			Expect(f.GoIsPointer).To(BeFalse())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in *int32) int {
+	if in == nil {
+		return 10
+	}
+	v := *in
+	return int(v)
+}(src.Count)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in int) *int32 {
+	v := in
+	r := int32(v)
+	return &r
+}(src.Count)`))
+		})
+
+		It("checks range of numeric types other than default ones", func() {
+			f, ok, err := proto2Field("Count", "Count", source.FieldInfo{Type: "int16"}, &descriptor.FieldDescriptorProto{Type: &i32}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in *int32) int16 {
+	if in == nil {
+		return 0
+	}
+	v := *in
+	if int32(int16(v)) != v {
+		reportError(opts, fmt.Errorf("Count: value %v is out of range of int16", v))
+		return 0
+	}
+	return int16(v)
+}(src.Count)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in int16) *int32 {
+	v := in
+	r := int32(v)
+	return &r
+}(src.Count)`))
+		})
+
+		It("converts pointer into pointer", func() {
+			f, ok, err := proto2Field("Ratio", "Ratio", source.FieldInfo{Type: "float64", IsPointer: true}, &descriptor.FieldDescriptorProto{Type: &dbl, DefaultValue: proto.String("0.5")}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(f.GoIsPointer).To(BeTrue())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in *float64) *float64 {
+	if in == nil {
+		return nil
+	}
+	v := *in
+	r := v
+	return &r
+}(src.Ratio)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in *float64) *float64 {
+	if in == nil {
+		return nil
+	}
+	v := *in
+	r := v
+	return &r
+}(src.Ratio)`))
+		})
+	})
+
+	Describe("processField", func() {
+		fi := fileInfo{proto2: true}
+
+		It("marks required fields", func() {
+			f, err := processField(nil, &descriptor.FieldDescriptorProto{
+				Name:  sp("string_field"),
+				Type:  &str,
+				Label: &req,
+			}, nil, goStruct, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Required).To(BeTrue())
+			Expect(f.ProtoToGoExpr).To(ContainSubstring("func(in *string) string {"))
+		})
+
+		It("rejects required scalars which are not pointers", func() {
+			_, err := processField(nil, &descriptor.FieldDescriptorProto{
+				Name:  sp("string_field"),
+				Type:  &str,
+				Label: &req,
+			}, nil, goStruct, fileInfo{proto2: true, gogofaster: true})
+			Expect(pkgerrors.Cause(err)).To(MatchError(errRequiredValue))
+		})
+
+		It("checks required bytes fields", func() {
+			f, err := processField(nil, &descriptor.FieldDescriptorProto{
+				Name:  sp("string_field"),
+				Type:  &byt,
+				Label: &req,
+			}, nil, goStruct, fileInfo{proto2: true, gogofaster: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Required).To(BeTrue())
+		})
+
+		It("uses helper functions with pointer suffix for other types", func() {
+			f, err := processField(nil, &descriptor.FieldDescriptorProto{
+				Name:  sp("pkg_type_field"),
+				Type:  &i32,
+				Label: &opt,
+			}, nil, goStruct, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Required).To(BeFalse())
+			Expect(f.convertFunc(false)).To(Equal("Int32ToPkgTypePtrVal"))
+			Expect(f.convertFunc(true)).To(Equal("PkgTypeToInt32ValPtr"))
+		})
+	})
+})
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gogen "github.com/gogo/protobuf/protoc-gen-gogo/generator"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+var errProtoMessageList = errors.New("slices of proto messages with different pointers can not be assigned")
+
+// protoMessageType returns Go type of proto message with full name typeName,
+// e.g. "pb.Product_Attributes" for ".shop.Product.Attributes". The second
+// return value is false for messages of other packages, Go packages of such
+// messages are unknown.
+func protoMessageType(typeName string, fi fileInfo) (string, bool) {
+	prefix := "."
+	if fi.pkg != "" {
+		prefix += fi.pkg + "."
+	}
+
+	if !strings.HasPrefix(typeName, prefix) {
+		return "", false
+	}
+
+	name := gogen.CamelCaseSlice(strings.Split(strings.TrimPrefix(typeName, prefix), "."))
+
+	return fi.protoPackage + "." + name, true
+}
+
+// isProtoMessageField returns true if model field gf has Go type of proto
+// message with full name typeName. Slices of values of types from other
+// packages are parsed without package name.
+func isProtoMessageField(typeName string, gf source.FieldInfo, fi fileInfo) bool {
+	t, ok := protoMessageType(typeName, fi)
+	if !ok {
+		return false
+	}
+
+	if gf.IsSlice && !gf.IsPointer && gf.PkgPath != "" {
+		return pkgName(gf.PkgPath) == fi.protoPackage && fi.protoPackage+"."+gf.Type == t
+	}
+
+	return gf.Type == t
+}
+
+// messageConv returns conversion of proto message of Go type t into the same
+// type. If clone is true, message is copied with proto.Clone, otherwise
+// values are dereferenced or addressed if needed.
+func messageConv(name, arg, t string, inPtr, outPtr, clone bool) inlineConv {
+	c := inlineConv{name: name, arg: arg, in: t, out: t, zero: t + "{}", result: "v"}
+	if inPtr {
+		c.in, c.nilable = "*"+t, true
+	}
+	if outPtr {
+		c.out, c.zero = "*"+t, "nil"
+	}
+
+	ptr := "&v"
+	if inPtr {
+		ptr = "v"
+	}
+
+	switch {
+	case clone && outPtr:
+		c.result = fmt.Sprintf("proto.Clone(%s).(*%s)", ptr, t)
+	case clone:
+		c.result = fmt.Sprintf("*proto.Clone(%s).(*%s)", ptr, t)
+	case inPtr && !outPtr:
+		c.deref = true
+	case !inPtr && outPtr:
+		c.outPtr = true
+	}
+
+	return c
+}
+
+// protoMessageField returns *Field for sub message field which model field
+// gf has the same proto message type t. Fields are assigned directly without
+// transformation functions, or copied with proto.Clone if clone is true.
+func protoMessageField(pname, gname, t string, gf source.FieldInfo, pnullable, clone bool, fi fileInfo) (*Field, error) {
+	f := &Field{
+		Name:           gname,
+		ProtoName:      pname,
+		GoIsPointer:    gf.IsPointer,
+		ProtoIsPointer: pnullable,
+	}
+
+	if gf.IsSlice && gf.IsPointer != pnullable {
+		return nil, errProtoMessageList
+	}
+
+	if !clone && gf.IsPointer == pnullable {
+		return f, nil
+	}
+
+	toGo := messageConv(pname, "src."+pname, t, pnullable, gf.IsPointer, clone)
+	toPb := messageConv(pname, "src."+gname, t, gf.IsPointer, pnullable, clone)
+
+	f.ProtoToGoExpr, f.GoToProtoExpr = toGo.String(), toPb.String()
+	if gf.IsSlice {
+		f.ProtoToGoExpr, f.GoToProtoExpr = toGo.list(), toPb.list()
+	}
+
+	if clone {
+		f.Imports = []string{gogoProtoImport}
+		if fi.golang {
+			f.Imports = []string{golangProtoImport}
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Proto message", func() {
+	fi := fileInfo{protoPackage: "pb", pkg: "shop"}
+
+	DescribeTable("protoMessageType",
+		func(typeName string, fi fileInfo, expected string, ok bool) {
+			t, found := protoMessageType(typeName, fi)
+			Expect(found).To(Equal(ok))
+			Expect(t).To(Equal(expected))
+		},
+
+		Entry("Message", ".shop.Attributes", fi, "pb.Attributes", true),
+		Entry("Nested message", ".shop.Product.AttributeSet", fi, "pb.Product_AttributeSet", true),
+		Entry("File without package", ".Attributes", fileInfo{protoPackage: "pb"}, "pb.Attributes", true),
+		Entry("Other package", ".google.type.Date", fi, "", false),
+	)
+
+	DescribeTable("isProtoMessageField",
+		func(gf source.FieldInfo, expected bool) {
+			Expect(isProtoMessageField(".shop.Attributes", gf, fi)).To(Equal(expected))
+		},
+
+		Entry("Pointer", source.FieldInfo{Type: "pb.Attributes", IsPointer: true}, true),
+		Entry("Value", source.FieldInfo{Type: "pb.Attributes"}, true),
+		Entry("Slice of pointers", source.FieldInfo{Type: "pb.Attributes", IsPointer: true, IsSlice: true}, true),
+		Entry("Slice of values", source.FieldInfo{Type: "Attributes", IsSlice: true, PkgPath: "example.com/shop/pb"}, true),
+		Entry("Slice of values of other package", source.FieldInfo{Type: "Attributes", IsSlice: true, PkgPath: "example.com/shop/model"}, false),
+		Entry("Model type", source.FieldInfo{Type: "Attributes", IsPointer: true}, false),
+		Entry("Other message", source.FieldInfo{Type: "pb.Product", IsPointer: true}, false),
+	)
+
+	DescribeTable("protoMessageField",
+		func(gf source.FieldInfo, pnullable, clone bool, expected *Field) {
+			f, err := protoMessageField("Attrs", "Attrs", "pb.Attributes", gf, pnullable, clone, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f).To(Equal(expected))
+		},
+
+		Entry("Pointers", source.FieldInfo{Type: "pb.Attributes", IsPointer: true}, true, false,
+			&Field{Name: "Attrs", ProtoName: "Attrs", GoIsPointer: true, ProtoIsPointer: true}),
+		Entry("Slices", source.FieldInfo{Type: "pb.Attributes", IsPointer: true, IsSlice: true}, true, false,
+			&Field{Name: "Attrs", ProtoName: "Attrs", GoIsPointer: true, ProtoIsPointer: true}),
+		Entry("Pointer into value", source.FieldInfo{Type: "pb.Attributes"}, true, false,
+			&Field{
+				Name:      "Attrs",
+				ProtoName: "Attrs",
+				ProtoToGoExpr: `func(in *pb.Attributes) pb.Attributes {
+	if in == nil {
+		return pb.Attributes{}
+	}
+	v := *in
+	return v
+}(src.Attrs)`,
+				GoToProtoExpr: `func(in pb.Attributes) *pb.Attributes {
+	v := in
+	r := v
+	return &r
+}(src.Attrs)`,
+				ProtoIsPointer: true,
+			}),
+		Entry("Cloned pointers", source.FieldInfo{Type: "pb.Attributes", IsPointer: true}, true, true,
+			&Field{
+				Name:      "Attrs",
+				ProtoName: "Attrs",
+				ProtoToGoExpr: `func(in *pb.Attributes) *pb.Attributes {
+	if in == nil {
+		return nil
+	}
+	v := in
+	return proto.Clone(v).(*pb.Attributes)
+}(src.Attrs)`,
+				GoToProtoExpr: `func(in *pb.Attributes) *pb.Attributes {
+	if in == nil {
+		return nil
+	}
+	v := in
+	return proto.Clone(v).(*pb.Attributes)
+}(src.Attrs)`,
+				GoIsPointer:    true,
+				ProtoIsPointer: true,
+				Imports:        []string{gogoProtoImport},
+			}),
+	)
+
+	It("protoMessageField clones slices", func() {
+		gf := source.FieldInfo{Type: "pb.Attributes", IsPointer: true, IsSlice: true}
+		f, err := protoMessageField("All", "All", "pb.Attributes", gf, true, true, fileInfo{golang: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.ProtoToGoExpr).To(HavePrefix("func(in []*pb.Attributes) []*pb.Attributes {"))
+		Expect(f.ProtoToGoExpr).To(ContainSubstring("proto.Clone(v).(*pb.Attributes)"))
+		Expect(f.Imports).To(Equal([]string{golangProtoImport}))
+	})
+
+	It("protoMessageField returns an error for slices with different pointers", func() {
+		gf := source.FieldInfo{Type: "Attributes", IsSlice: true, PkgPath: "example.com/shop/pb"}
+		_, err := protoMessageField("All", "All", "pb.Attributes", gf, true, false, fi)
+		Expect(err).To(Equal(errProtoMessageList))
+	})
+})
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"text/template"
 	"text/template/parse"
+
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
 )
 
 func at(t *template.Template) (string, *parse.Tree) {
@@ -25,8 +27,20 @@ func mt(name, tpl string, inc ...*template.Template) *template.Template {
 
 var (
 	funcMap = template.FuncMap{
-		"formatField":          formatField,
-		"formatOneofInitField": formatOneofInitField,
+		"formatField":           formatField,
+		"formatOneofInitField":  formatOneofInitField,
+		"formatRequiredField":   formatRequiredField,
+		"formatExtensionField":  formatExtensionField,
+		"formatClosedEnumField": formatClosedEnumField,
+		"formatAliasFields":     formatAliasFields,
+		"formatFlattenField":    formatFlattenField,
+		"formatNestedField":     formatNestedField,
+		"formatKeyedField":      formatKeyedField,
+		"formatDefaultField":    formatDefaultField,
+
+		"formatGolangOneofField":     formatGolangOneofField,
+		"formatGolangExtensionField": formatGolangExtensionField,
+		"formatGolangUnknownField":   formatGolangUnknownField,
 	}
 
 	funcNameT = mt("FuncName", `{{- .SrcFn }}To{{ .DstFn }}`)
@@ -60,6 +74,11 @@ var (
 }`, funcNameT, srcParamT, dstParamT)
 
 	val2valT = mt("val2val", `func {{ template "FuncName" . }}(src {{ template "SrcParam" . }}) {{ template "DstParam" . }} {
+	opts, ok := nestOptions(opts)
+	if !ok {
+		return {{ template "DstParam" . }}{}
+	}
+
 	s := {{ template "DstParam" . }}{
 		{{- with $R := . }}
 			{{- range $f := .Fields}}
@@ -72,7 +91,7 @@ var (
 
 {{- with $R := . }}
 {{ range $f := .Fields }}
-{{ formatOneofInitField $f $R.Swapped }}
+{{ formatOneofInitField $f $R.Swapped }}{{ formatRequiredField $f $R.Swapped }}{{ formatExtensionField $f $R.Swapped }}{{ formatClosedEnumField $f $R.Swapped }}{{ formatAliasFields $f $R.Swapped }}{{ formatFlattenField $f $R.Swapped }}{{ formatNestedField $f $R.Swapped }}{{ formatKeyedField $f $R.Swapped }}{{ formatDefaultField $f $R.Swapped }}
 {{- end -}}
 {{- end }}
 	return s
@@ -100,7 +119,7 @@ var (
 		g := {{ template "FuncName" . }}(s, opts...)
 		resp[i] = &g
 		{{ else }}
-		resp[i] = {{ template "FuncName" . }}(*s)
+		resp[i] = {{ template "FuncName" . }}(*s, opts...)
 		{{ end -}}
 	}
 
@@ -109,13 +128,64 @@ var (
 
 	ptr2vallstT = mt("ptr2vallst", `// {{ template "FuncName" . }}List is DEPRECATED. Use {{ template "FuncName" . }}{{ template "PtrValName" . }}List instead.
 func {{ template "FuncName" . }}List(src []{{ .SrcPointer }}{{ template "SrcParam" . }}) []{{ .DstPointer }}{{ template "DstParam" . }} {
-	return {{ template "FuncName" . }}{{ template "PtrValName" . }}List(src)
+	return {{ template "FuncName" . }}{{ template "PtrValName" . }}List(src, opts...)
+}`, funcNameT, ptrValT, srcParamT, dstParamT)
+
+	golangPtrT = mt("golangPtr", `func {{ template "FuncName" . }}Ptr(src *{{ template "SrcParam" . }}) *{{ template "DstParam" . }} {
+	if src == nil {
+		return nil
+	}
+{{ if .Swapped }}
+	return {{ template "FuncName" . }}ValPtr(*src, opts...)
+{{- else }}
+	d := {{ template "FuncName" . }}PtrVal(src, opts...)
+	return &d
+{{- end }}
+}`, funcNameT, srcParamT, dstParamT)
+
+	golangValT = mt("golangVal", `func {{ template "FuncName" . }}{{ template "PtrValName" . }}(src {{ .SrcPointer }}{{ template "SrcParam" . }}) {{ .DstPointer }}{{ template "DstParam" . }} {
+{{- if not .Swapped }}
+	if src == nil {
+		return {{ template "DstParam" . }}{}
+	}
+{{ end }}
+	opts, ok := nestOptions(opts)
+	if !ok {
+		return {{ if .Swapped }}&{{ end }}{{ template "DstParam" . }}{}
+	}
+
+	s := {{ if .Swapped }}&{{ end }}{{ template "DstParam" . }}{
+		{{- with $R := . }}
+			{{- range $f := .Fields}}
+			{{ formatField $f $R.Swapped $R.DstPref }}
+			{{- end -}}
+		{{- end }}
+	}
+
+	applyOptions(opts...)
+
+{{- with $R := . }}
+{{ range $f := .Fields }}
+{{ formatGolangOneofField $f $R.Swapped }}{{ formatRequiredField $f $R.Swapped }}{{ formatGolangExtensionField $f $R.Swapped }}{{ formatClosedEnumField $f $R.Swapped }}{{ formatGolangUnknownField $f $R.Swapped }}{{ formatAliasFields $f $R.Swapped }}{{ formatFlattenField $f $R.Swapped }}{{ formatNestedField $f $R.Swapped }}{{ formatKeyedField $f $R.Swapped }}{{ formatDefaultField $f $R.Swapped }}
+{{- end -}}
+{{- end }}
+	return s
+}`, funcNameT, ptrValT, srcParamT, dstParamT)
+
+	golangValListT = mt("golangValList", `func {{ template "FuncName" . }}{{ template "PtrValName" . }}List(src []{{ .SrcPointer }}{{ template "SrcParam" . }}) []{{ .DstPointer }}{{ template "DstParam" . }} {
+	resp := make([]{{ .DstPointer }}{{ template "DstParam" . }}, len(src))
+
+	for i, s := range src {
+		resp[i] = {{ template "FuncName" . }}{{ template "PtrValName" . }}(s, opts...)
+	}
+
+	return resp
 }`, funcNameT, ptrValT, srcParamT, dstParamT)
 
 	tpls = []*template.Template{
 		funcNameT, srcParamT, dstParamT, ptrValT, ptrT, ptrOnlyT, starT, ptr2ptrT,
 		ptr2valT, val2ptrT, val2valT, lst2lstT, ptrlst2ptrlstT, vallst2vallstT,
-		ptrlst2vallstT, ptr2vallstT,
+		ptrlst2vallstT, ptr2vallstT, golangPtrT, golangValT, golangValListT,
 	}
 
 	// Executed with Data struct.
@@ -135,6 +205,21 @@ func {{ template "FuncName" . }}List(src []{{ .SrcPointer }}{{ template "SrcPara
 
 {{ template "vallst2vallst" . }}
 
+`
+
+	// Executed with Data struct for structures generated by protoc-gen-go.
+	// Proto messages are never passed by value, so functions which take or
+	// return values of proto messages are not generated.
+	golangFunctionSetT = `{{- template "golangPtr" . }}
+
+{{ template "ptrlst2ptrlst" . }}
+
+{{ template "golangVal" . }}
+
+{{ template "golangValList" . }}
+
+{{ template "ptr2vallst" . }}
+
 `
 
 	oneofT = `
@@ -168,30 +253,105 @@ func {{ .GoType }}To{{ .ProtoType }}(s string, dst *{{ .ProtoPackage }}.{{ .Prot
 
 `
 
-	optionsT = `var version string
+	optionsT = `import "errors"
+
+var version string
+
+// ErrMaxDepth is passed to error handler for messages which are nested deeper
+// than maximal depth set by WithMaxDepth.
+var ErrMaxDepth = errors.New("maximal depth of nested messages exceeded")
 
 // TransformParam is a function option type.
-type TransformParam func()
+type TransformParam func(*transformOptions)
+
+// transformOptions contains values set by TransformParam functions.
+type transformOptions struct {
+	errorHandler func(error)
+	maxDepth     int
+	depth        int
+}
 
 // WithVersion sets global version variable.
 func WithVersion(v string) TransformParam {
-	return func() {
+	return func(*transformOptions) {
 		version = v
 	}
 }
 
-func applyOptions(opts ...TransformParam) {
-	for _, o := range opts {
-		o()
+// WithErrorHandler sets a function which is called for each value which can
+// not be transformed, e.g. invalid date. Such values are replaced with zero
+// values.
+func WithErrorHandler(h func(error)) TransformParam {
+	return func(o *transformOptions) {
+		o.errorHandler = h
+	}
+}
+
+func applyOptions(opts ...TransformParam) transformOptions {
+	o := transformOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// reportError passes err to error handler if it's set.
+func reportError(opts []TransformParam, err error) {
+	if h := applyOptions(opts...).errorHandler; h != nil {
+		h(err)
+	}
+}
+
+// WithMaxDepth limits nesting of transformed messages, e.g. for recursive
+// messages received from untrusted clients. Top-level message has depth 1,
+// messages nested deeper than n are replaced with empty messages and
+// ErrMaxDepth is passed to error handler. Depth is not limited if n is not
+// positive.
+func WithMaxDepth(n int) TransformParam {
+	return func(o *transformOptions) {
+		o.maxDepth = n
+	}
+}
+
+// withDepth sets depth of transformed message.
+func withDepth(d int) TransformParam {
+	return func(o *transformOptions) {
+		o.depth = d
 	}
 }
 
+// nestOptions returns options for sub messages of transformed message with
+// incremented depth. It returns false and passes ErrMaxDepth to error handler
+// if transformed message is nested deeper than maximal depth. Depth is set by
+// the last option of nested messages, it's replaced, so number of options
+// doesn't grow with depth.
+func nestOptions(opts []TransformParam) ([]TransformParam, bool) {
+	o := applyOptions(opts...)
+	if o.maxDepth <= 0 {
+		return opts, true
+	}
+
+	if o.depth >= o.maxDepth {
+		reportError(opts, ErrMaxDepth)
+		return opts, false
+	}
+
+	n := len(opts)
+	if o.depth > 0 {
+		n--
+	}
+
+	return append(opts[:n:n], withDepth(o.depth+1)), true
+}
+
 `
 )
 
 // templateWithHelpers initializes main oneFuncitonSetT template with given
-// name, adds there sub-templates and maps functions into template.
-func templateWithHelpers(name string) (*template.Template, error) {
+// name, adds there sub-templates and maps functions into template. If golang
+// is true, golangFunctionSetT is used as main template.
+func templateWithHelpers(name string, golang bool) (*template.Template, error) {
 	t := template.
 		New(name).
 		Funcs(funcMap)
@@ -202,6 +362,10 @@ func templateWithHelpers(name string) (*template.Template, error) {
 		}
 	}
 
+	if golang {
+		return t.Parse(golangFunctionSetT)
+	}
+
 	return t.Parse(oneFuncitonSetT)
 }
 
@@ -217,6 +381,15 @@ type Field struct {
 	ProtoToGoType string
 	// Name of function which is used for converting Go field into proto one.
 	GoToProtoType string
+	// Go expression which is used for converting proto field into Go one
+	// instead of function. Expression uses "src" as a source structure.
+	ProtoToGoExpr string
+	// Go expression which is used for converting Go field into proto one
+	// instead of function.
+	GoToProtoExpr string
+	// Import specs which are required by expressions, e.g. for packages with
+	// the same names.
+	Imports []string
 	// True if field in model is a pointer.
 	GoIsPointer bool
 	// True if field in .proto file has an option gogoproto.nullable = false
@@ -229,6 +402,75 @@ type Field struct {
 	//        This field will be deprecated together with oneof.go once BoldCommerce update their code
 	OneofDecl string
 	Opts      string
+	// Field name as declared in .proto file. Used as google.protobuf.FieldMask
+	// path element.
+	ProtoPath string
+	// Column name from db tag of model field.
+	Column string
+	// Model name of sub message which has field mask functions. Is used for
+	// translating nested field mask paths.
+	MaskTarget string
+	// True if field is a required field of proto2 file, missing values are
+	// passed to error handler.
+	Required bool
+	// Go expression for descriptor of extension field, e.g.
+	// "pb.E_LegacyCode", and Go type of extension value. Extension fields are
+	// not added to composite literal, see formatExtensionField.
+	Extension     string
+	ExtensionType string
+	// Name of Go map with names of closed enum values, e.g.
+	// "pb.Color_name". Values converted from model are checked, see
+	// formatClosedEnumField.
+	ClosedEnum string
+	// True if proto field is read by getter, e.g. src.GetName(), see
+	// golangField.
+	Getter bool
+	// Name of oneof field of protoc-gen-go message, wrapper type of oneof
+	// member and condition which is checked before setting oneof field, see
+	// golangOneofField.
+	OneofField   string
+	OneofWrapper string
+	OneofCond    string
+	// True if field keeps unknown fields of protoc-gen-go message, they are
+	// set after message is created, see formatGolangUnknownField.
+	Unknown bool
+	// Fields which are aliases of this field, see formatAliasFields. Alias
+	// fields are mapped into the same model field.
+	Aliases []Field
+	// Format of condition which is true if proto field of field with aliases,
+	// alias field or field of nested model structure has a value, see
+	// presenceCond.
+	Presence string
+	// True if model value is written into alias field too.
+	WriteAlias bool
+	// Direction of field transformation, field is omitted in functions of the
+	// other direction, see Data.directed.
+	Direction options.Direction
+	// Fields of flattened sub message which are mapped into fields of parent
+	// model, see formatFlattenField. Not nil if field has transformer.flatten
+	// option.
+	Flatten []Field
+	// True if model fields with zero values are transformed into empty sub
+	// message instead of nil.
+	FlattenEmpty bool
+	// Format of condition which is true if model field of flattened sub
+	// message field has non-zero value, see modelPresence.
+	ModelPresence string
+	// Fields of nested model structure which are set from proto fields with
+	// map_to option like "Address.City", see formatNestedField. Not nil if
+	// field is a nested structure.
+	Nested []Field
+	// Model type of nested structure, e.g. "model.Address".
+	NestedType string
+	// Go expression which is used as model value if proto field has no
+	// value, see formatDefaultField.
+	Default string
+	// True if model value is read from method, Name contains method call,
+	// e.g. "Total()".
+	Method bool
+	// Model map or set which is transformed from repeated proto field, see
+	// formatKeyedField.
+	Keyed *KeyedMap
 }
 
 // IsOneof returns true if Field has non-empty OneOf declaration.
@@ -236,6 +478,19 @@ func (f Field) IsOneof() bool {
 	return f.OneofDecl != ""
 }
 
+// transformed returns true if field is transformed in direction based on
+// swapped flag.
+func (f Field) transformed(swapped bool) bool {
+	switch f.Direction {
+	case options.Direction_PB_TO_MODEL:
+		return !swapped
+	case options.Direction_MODEL_TO_PB:
+		return swapped
+	default:
+		return true
+	}
+}
+
 // name based on swapped flag return Name or ProtoName for current Field.
 func (f Field) name(swapped bool) string {
 	if swapped {
@@ -324,17 +579,54 @@ func formatOneofInitField(f Field, swapped bool) string {
 
 }
 
+// formatRequiredField returns check for missing value of required field. Go
+// field is checked only if it's a pointer.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatRequiredField(f Field, swapped bool) string {
+	if !f.Required || swapped && !f.GoIsPointer {
+		return ""
+	}
+
+	return fmt.Sprintf("\tif src.%s == nil {\n\t\treportError(opts, errors.New(%q))\n\t}\n",
+		f.name(swapped), f.ProtoName+": required field is not set")
+}
+
+// expr based on swapped flag returns ProtoToGoExpr or GoToProtoExpr.
+func (f Field) expr(swapped bool) string {
+	if swapped {
+		return f.GoToProtoExpr
+	}
+	return f.ProtoToGoExpr
+}
+
+// srcField returns expression which reads field of source structure.
+func (f Field) srcField(swapped bool) string {
+	if f.Getter && !swapped {
+		return fmt.Sprintf("src.Get%s()", f.ProtoName)
+	}
+	return "src." + f.name(swapped)
+}
+
 func formatComplexField(f Field, swapped bool) string {
+	if e := f.expr(swapped); e != "" {
+		return e
+	}
+
 	if f.ProtoToGoType != "" {
-		return fmt.Sprintf(" %s(src.%s %s)", f.convertFunc(swapped), f.name(swapped), f.Opts)
+		return fmt.Sprintf(" %s(%s %s)", f.convertFunc(swapped), f.srcField(swapped), f.Opts)
 	}
 
-	return fmt.Sprintf("src.%s", f.name(swapped))
+	return f.srcField(swapped)
 }
 
 // formatField returns a string with appropriate field convert functions for
 // using in template.
 func formatField(f Field, swapped bool, pref string) string {
+	if f.Extension != "" || f.Flatten != nil || f.Nested != nil || f.Keyed != nil || swapped && (f.OneofWrapper != "" || f.Unknown) {
+		return ""
+	}
+
 	left := f.name(!swapped)
 
 	right := ""
@@ -393,6 +685,9 @@ type Data struct {
 	HelperPackage string
 	// Ptr is used in template for indication of pointer usage.
 	Ptr bool
+	// If true, functions for structures generated by protoc-gen-go are used,
+	// see golangFunctionSetT.
+	Golang bool
 }
 
 // swap swaps source and destination parameters for using in reverse functions.
@@ -404,6 +699,20 @@ func (d *Data) swap() {
 	d.Swapped = !d.Swapped
 }
 
+// directed returns copy of Data structure with fields which are transformed in
+// direction of d.
+func (d Data) directed() *Data {
+	fields := make([]Field, 0, len(d.Fields))
+	for _, f := range d.Fields {
+		if f.transformed(d.Swapped) {
+			fields = append(fields, f)
+		}
+	}
+	d.Fields = fields
+
+	return &d
+}
+
 // P sets Ptr flag of Data structure. Used inside template. Should be exported
 // in template case.
 func (d Data) P(t bool) Data {
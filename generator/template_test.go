@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -73,6 +74,54 @@ var _ = Describe("Template", func() {
 				xEntry("go2protoList", "proto2goList", false, true, true, "go2protoValPtrList"),
 			)
 		})
+
+		Context("when call transformed(swapped) method", func() {
+
+			DescribeTable("check result",
+				func(d options.Direction, swapped, expected bool) {
+					f := Field{Direction: d}
+					Expect(f.transformed(swapped)).To(Equal(expected))
+				},
+
+				Entry("Both, proto to Go", options.Direction_BOTH, false, true),
+				Entry("Both, Go to proto", options.Direction_BOTH, true, true),
+				Entry("Proto to Go only, proto to Go", options.Direction_PB_TO_MODEL, false, true),
+				Entry("Proto to Go only, Go to proto", options.Direction_PB_TO_MODEL, true, false),
+				Entry("Go to proto only, proto to Go", options.Direction_MODEL_TO_PB, false, false),
+				Entry("Go to proto only, Go to proto", options.Direction_MODEL_TO_PB, true, true),
+			)
+		})
+	})
+
+	Describe("Data.directed", func() {
+		d := Data{Fields: []Field{
+			{Name: "ID"},
+			{Name: "Password", Direction: options.Direction_PB_TO_MODEL},
+			{Name: "CreatedAt", Direction: options.Direction_MODEL_TO_PB},
+		}}
+
+		names := func(d *Data) []string {
+			var n []string
+			for _, f := range d.Fields {
+				n = append(n, f.Name)
+			}
+			return n
+		}
+
+		It("keeps fields which are transformed from proto to Go", func() {
+			Expect(names(d.directed())).To(Equal([]string{"ID", "Password"}))
+		})
+
+		It("keeps fields which are transformed from Go to proto", func() {
+			s := d
+			s.swap()
+			Expect(names(s.directed())).To(Equal([]string{"ID", "CreatedAt"}))
+		})
+
+		It("does not change fields of original structure", func() {
+			d.directed()
+			Expect(d.Fields).To(HaveLen(3))
+		})
 	})
 
 	Describe("formatOneofField", func() {
@@ -125,6 +174,22 @@ var _ = Describe("Template", func() {
 		)
 	})
 
+	Describe("formatRequiredField", func() {
+
+		DescribeTable("check returns",
+			func(f Field, swapped bool, expected string) {
+				r := formatRequiredField(f, swapped)
+				Expect(r).To(Equal(expected))
+			},
+			Entry("Not required", Field{ProtoName: "ProtoName"}, false, ""),
+			Entry("Required", Field{Name: "Name", ProtoName: "ProtoName", Required: true}, false,
+				"\tif src.ProtoName == nil {\n\t\treportError(opts, errors.New(\"ProtoName: required field is not set\"))\n\t}\n"),
+			Entry("Required, swapped, Go field is not a pointer", Field{Name: "Name", ProtoName: "ProtoName", Required: true}, true, ""),
+			Entry("Required, swapped, Go field is a pointer", Field{Name: "Name", ProtoName: "ProtoName", Required: true, GoIsPointer: true}, true,
+				"\tif src.Name == nil {\n\t\treportError(opts, errors.New(\"ProtoName: required field is not set\"))\n\t}\n"),
+		)
+	})
+
 	Describe("formatComplexField", func() {
 
 		DescribeTable("check returns",
@@ -151,6 +216,28 @@ var _ = Describe("Template", func() {
 				ProtoIsPointer: false,
 				Opts:           ", opts...",
 			}, false, "src.proto_name"),
+
+			Entry("Expression is set", Field{
+				Name:          "name",
+				ProtoName:     "proto_name",
+				ProtoToGoType: "p2g",
+				ProtoToGoExpr: "p2g(src.proto_name)",
+				GoToProtoExpr: "g2p(src.name)",
+			}, true, "g2p(src.name)"),
+
+			Entry("Getter", Field{
+				Name:          "Name",
+				ProtoName:     "ProtoName",
+				ProtoToGoType: "p2g",
+				Getter:        true,
+				Opts:          ", opts...",
+			}, false, " p2g(src.GetProtoName() , opts...)"),
+
+			Entry("Getter, swapped", Field{
+				Name:      "Name",
+				ProtoName: "ProtoName",
+				Getter:    true,
+			}, true, "src.Name"),
 		)
 	})
 
@@ -184,6 +271,19 @@ var _ = Describe("Template", func() {
 				ProtoType: "proto_type",
 				OneofDecl: "oneof_decl_name",
 			}, true, "prefix", "proto_name: &prefix.proto_type{},"),
+
+			Entry("Oneof member of protoc-gen-go message", Field{
+				Name:         "Name",
+				ProtoName:    "ProtoName",
+				Getter:       true,
+				OneofWrapper: "pb.Message_ProtoName",
+			}, false, "", "Name: src.GetProtoName(),"),
+
+			Entry("Oneof member of protoc-gen-go message, swapped", Field{
+				Name:         "Name",
+				ProtoName:    "ProtoName",
+				OneofWrapper: "pb.Message_ProtoName",
+			}, true, "", ""),
 		)
 	})
 
@@ -234,7 +334,7 @@ var _ = Describe("Template", func() {
 		Context("when execute whole template", func() {
 
 			It("returns full function set as string", func() {
-				t, err := templateWithHelpers("test_template")
+				t, err := templateWithHelpers("test_template", false)
 				Expect(err).NotTo(HaveOccurred())
 
 				err = t.Execute(w, Data{
@@ -496,6 +596,11 @@ var _ = Describe("Template", func() {
 						},
 					},
 				}, `func SrcFnToDstFn(src SrcPref.Src, opts ...TransformParam) DstPref.Dst {
+	opts, ok := nestOptions(opts)
+	if !ok {
+		return DstPref.Dst{}
+	}
+
 	s := DstPref.Dst{
 			FirstField:  FirstGo2proto(src.proto_name ),
 			SecondField: SecondProto2go(src.proto_name2),
@@ -610,7 +715,7 @@ var _ = Describe("Template", func() {
 	resp := make([]DstPref.Dst, len(src))
 
 	for i, s := range src {
-		resp[i] = SrcFnToDstFn(*s)
+		resp[i] = SrcFnToDstFn(*s, opts...)
 		}
 
 	return resp
@@ -635,7 +740,7 @@ var _ = Describe("Template", func() {
 					DstPref: "DstPref",
 				}, `// SrcFnToDstFnList is DEPRECATED. Use SrcFnToDstFnPtrValList instead.
 func SrcFnToDstFnList(src []SrcPref.Src, opts ...TransformParam) []DstPref.Dst {
-	return SrcFnToDstFnPtrValList(src)
+	return SrcFnToDstFnPtrValList(src, opts...)
 }`),
 			)
 		})
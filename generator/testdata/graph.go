@@ -0,0 +1,26 @@
+package model
+
+type Order struct {
+	Total *Money
+}
+
+type Money struct {
+	Units    int64
+	Currency Currency
+}
+
+type Currency struct {
+	Code string
+}
+
+type Category struct {
+	Children []Category
+}
+
+type Node struct {
+	Edges []Edge
+}
+
+type Edge struct {
+	To *Node
+}
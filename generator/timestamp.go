@@ -0,0 +1,258 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+const (
+	// Import spec for package with google.protobuf.Timestamp type, package
+	// name "types" is too common to be found by goimports.
+	gogoTypesImport = `"github.com/gogo/protobuf/types"`
+
+	// Range of google.protobuf.Timestamp seconds: from
+	// 0001-01-01T00:00:00Z to 9999-12-31T23:59:59Z.
+	minTimestampSeconds = -62135596800
+	maxTimestampSeconds = 253402300799
+)
+
+var (
+	errTimeZeroNil = errors.New("transformer.time_zero_nil option requires nullable timestamp, non-nullable timestamps can not be unset")
+
+	timestampType = googleType{
+		pbType:  "types.Timestamp",
+		pbZero:  "types.Timestamp{}",
+		imports: []string{gogoTypesImport},
+	}
+
+	// Conversions from time.Time into Unix epoch and back for values of
+	// transformer.time_unix option.
+	timeUnits = map[string][2]string{
+		"s":  {"t.Unix()", "time.Unix(v, 0)"},
+		"ms": {"t.UnixMilli()", "time.UnixMilli(v)"},
+		"us": {"t.UnixMicro()", "time.UnixMicro(v)"},
+		"ns": {"t.UnixNano()", "time.Unix(0, v)"},
+	}
+
+	// Duration constants of time package.
+	durationNames = map[time.Duration]string{
+		time.Nanosecond:  "time.Nanosecond",
+		time.Microsecond: "time.Microsecond",
+		time.Millisecond: "time.Millisecond",
+		time.Second:      "time.Second",
+		time.Minute:      "time.Minute",
+		time.Hour:        "time.Hour",
+	}
+
+	// Layout constants of time package which can be used in
+	// transformer.time_format option.
+	timeLayouts = map[string]bool{
+		"ANSIC": true, "UnixDate": true, "RubyDate": true, "RFC822": true,
+		"RFC822Z": true, "RFC850": true, "RFC1123": true, "RFC1123Z": true,
+		"RFC3339": true, "RFC3339Nano": true, "Kitchen": true, "DateTime": true,
+		"DateOnly": true, "TimeOnly": true,
+	}
+)
+
+// timeOptions contains field options which are used for conversion of
+// google.protobuf.Timestamp fields.
+type timeOptions struct {
+	location string
+	truncate string
+	zeroNil  bool
+	unix     string
+	format   string
+	validate bool
+}
+
+// inline returns true if at least one option is set, i.e. timestamp should be
+// converted inline instead of helper functions.
+func (o timeOptions) inline() bool {
+	return o != timeOptions{}
+}
+
+// extractTimeOptions returns values of transformer.time_* options and
+// transformer.validate option.
+func extractTimeOptions(m proto.Message) (timeOptions, error) {
+	o := timeOptions{
+		zeroNil:  getBoolOption(m, options.E_TimeZeroNil),
+		validate: getBoolOption(m, options.E_Validate),
+	}
+
+	for _, v := range []struct {
+		dst *string
+		opt *proto.ExtensionDesc
+	}{
+		{&o.location, options.E_TimeLocation},
+		{&o.truncate, options.E_TimeTruncate},
+		{&o.unix, options.E_TimeUnix},
+		{&o.format, options.E_TimeFormat},
+	} {
+		s, err := getStringOption(m, v.opt)
+		if _, ok := err.(errOptionNotExists); err != nil && err != ErrNilOptions && !ok {
+			return o, err
+		}
+		*v.dst = s
+	}
+
+	return o, nil
+}
+
+// durationExpr returns Go expression for duration which is set as a string.
+func durationExpr(s string) (string, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+
+	if d <= 0 {
+		return "", fmt.Errorf("transformer.time_truncate should be positive, got %q", s)
+	}
+
+	if n, ok := durationNames[d]; ok {
+		return n, nil
+	}
+
+	return fmt.Sprintf("time.Duration(%d)", d), nil
+}
+
+// layoutExpr returns Go expression for time layout.
+func layoutExpr(s string) string {
+	switch {
+	case s == "":
+		return "time.RFC3339Nano"
+	case timeLayouts[s]:
+		return "time." + s
+	}
+
+	return fmt.Sprintf("%q", s)
+}
+
+// timestampConv returns conversion between google.protobuf.Timestamp and
+// model type. The second return value is false if model type is not
+// supported.
+func timestampConv(gf source.FieldInfo, o timeOptions) (googleTypeConv, bool, error) {
+	var (
+		conv    googleTypeConv
+		toModel string
+	)
+
+	toTime := "t := v"
+
+	switch gf.Type {
+	case "time.Time":
+		conv.goZero = "time.Time{}"
+		toModel = "t"
+
+	case "int64":
+		u, ok := timeUnits[o.unix]
+		if !ok {
+			if o.unix != "" {
+				return conv, false, fmt.Errorf("transformer.time_unix should be one of s, ms, us, ns, got %q", o.unix)
+			}
+			return conv, false, nil
+		}
+
+		conv.goZero = "0"
+		toModel = u[0]
+		toTime = "t := " + u[1]
+
+	case "string":
+		layout := layoutExpr(o.format)
+		conv.goZero = `""`
+		toModel = fmt.Sprintf("t.Format(%s)", layout)
+		toTime = fmt.Sprintf("t, err := time.Parse(%s, v)", layout)
+
+	default:
+		return conv, false, nil
+	}
+
+	location := ".UTC()"
+	switch o.location {
+	case "", "UTC":
+	case "Local":
+		location = ""
+	default:
+		return conv, false, fmt.Errorf("transformer.time_location should be UTC or Local, got %q", o.location)
+	}
+
+	var truncate []step
+	if o.truncate != "" {
+		d, err := durationExpr(o.truncate)
+		if err != nil {
+			return conv, false, err
+		}
+		truncate = append(truncate, step{stmt: fmt.Sprintf("t = t.Truncate(%s)", d)})
+	}
+
+	// proto to model. Only nil timestamp is converted into zero value, epoch
+	// is a valid instant even with time_zero_nil option.
+	conv.toGoSteps = append(conv.toGoSteps,
+		step{
+			cond:       fmt.Sprintf("v.Seconds < %d || v.Seconds > %d", minTimestampSeconds, maxTimestampSeconds),
+			format:     "seconds %d are out of range",
+			args:       []string{"v.Seconds"},
+			validation: true,
+		},
+		step{cond: "v.Nanos < 0 || v.Nanos > 999999999", format: "nanos %d are out of range", args: []string{"v.Nanos"}, validation: true},
+		step{stmt: fmt.Sprintf("t := time.Unix(v.Seconds, int64(v.Nanos))%s", location)},
+	)
+	conv.toGoSteps = append(conv.toGoSteps, truncate...)
+	conv.toGo = toModel
+
+	// model to proto.
+	switch {
+	case gf.Type == "string":
+		conv.toPbSteps = append(conv.toPbSteps,
+			step{cond: `v == ""`},
+			step{stmt: toTime},
+			step{cond: "err != nil", format: "%v", args: []string{"err"}},
+		)
+	case o.zeroNil:
+		zero := "v == 0"
+		if gf.Type == "time.Time" {
+			zero = "v.IsZero()"
+		}
+		conv.toPbSteps = append(conv.toPbSteps, step{cond: zero}, step{stmt: toTime})
+	default:
+		conv.toPbSteps = append(conv.toPbSteps, step{stmt: toTime})
+	}
+
+	conv.toPbSteps = append(conv.toPbSteps, truncate...)
+	conv.toPbSteps = append(conv.toPbSteps, step{
+		cond:       fmt.Sprintf("t.Unix() < %d || t.Unix() > %d", minTimestampSeconds, maxTimestampSeconds),
+		format:     "time %v is out of range",
+		args:       []string{"t"},
+		validation: true,
+	})
+	conv.toPb = "types.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}"
+
+	return conv, true, nil
+}
+
+// wktgoogleProtobufTimestampInline returns *Field with inline conversions
+// between google.protobuf.Timestamp and model field. The second return value
+// is false if no transformer.time_* options are set or model type is not
+// supported, such fields are converted by helper functions, see
+// wktgoogleProtobufTimestamp.
+func wktgoogleProtobufTimestampInline(pname, gname string, gf source.FieldInfo, pnullable bool, o timeOptions) (*Field, bool, error) {
+	if !o.inline() {
+		return nil, false, nil
+	}
+
+	if o.zeroNil && !pnullable {
+		return nil, false, errTimeZeroNil
+	}
+
+	conv, ok, err := timestampConv(gf, o)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return inlineField(pname, gname, timestampType, conv, gf, pnullable, o.validate), true, nil
+}
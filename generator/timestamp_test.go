@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"errors"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timestamp", func() {
+
+	Describe("extractTimeOptions", func() {
+
+		It("returns all options", func() {
+			o := &descriptor.FieldOptions{}
+			Expect(proto.SetExtension(o, options.E_TimeLocation, sp("Local"))).To(Succeed())
+			Expect(proto.SetExtension(o, options.E_TimeTruncate, sp("1s"))).To(Succeed())
+			Expect(proto.SetExtension(o, options.E_TimeZeroNil, bp(true))).To(Succeed())
+			Expect(proto.SetExtension(o, options.E_TimeUnix, sp("ms"))).To(Succeed())
+			Expect(proto.SetExtension(o, options.E_TimeFormat, sp("RFC3339"))).To(Succeed())
+			Expect(proto.SetExtension(o, options.E_Validate, bp(true))).To(Succeed())
+
+			to, err := extractTimeOptions(o)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(to).To(Equal(timeOptions{
+				location: "Local",
+				truncate: "1s",
+				zeroNil:  true,
+				unix:     "ms",
+				format:   "RFC3339",
+				validate: true,
+			}))
+			Expect(to.inline()).To(BeTrue())
+		})
+
+		It("returns empty options", func() {
+			to, err := extractTimeOptions(&descriptor.FieldOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(to.inline()).To(BeFalse())
+		})
+	})
+
+	Describe("durationExpr", func() {
+
+		DescribeTable("check result",
+			func(s, expected string, expErr bool) {
+				d, err := durationExpr(s)
+				Expect(err != nil).To(Equal(expErr))
+				Expect(d).To(Equal(expected))
+			},
+
+			Entry("Second", "1s", "time.Second", false),
+			Entry("Millisecond", "1ms", "time.Millisecond", false),
+			Entry("Arbitrary", "90s", "time.Duration(90000000000)", false),
+			Entry("Negative", "-1s", "", true),
+			Entry("Invalid", "second", "", true),
+		)
+	})
+
+	Describe("layoutExpr", func() {
+
+		DescribeTable("check result",
+			func(s, expected string) {
+				Expect(layoutExpr(s)).To(Equal(expected))
+			},
+
+			Entry("Default", "", "time.RFC3339Nano"),
+			Entry("Constant", "RFC3339", "time.RFC3339"),
+			Entry("Layout", "2006-01-02 15:04", `"2006-01-02 15:04"`),
+		)
+	})
+
+	Describe("wktgoogleProtobufTimestampInline", func() {
+
+		DescribeTable("falls back to helper functions",
+			func(gf source.FieldInfo, o timeOptions, expErr error) {
+				f, ok, err := wktgoogleProtobufTimestampInline("Pb", "Go", gf, true, o)
+				Expect(f).To(BeNil())
+				Expect(ok).To(BeFalse())
+				if expErr == nil {
+					Expect(err).NotTo(HaveOccurred())
+				} else {
+					Expect(err).To(MatchError(expErr.Error()))
+				}
+			},
+
+			Entry("No options", source.FieldInfo{Type: "time.Time"}, timeOptions{}, nil),
+			Entry("Unsupported type", source.FieldInfo{Type: "civil.DateTime"}, timeOptions{zeroNil: true}, nil),
+			Entry("int64 without unit", source.FieldInfo{Type: "int64"}, timeOptions{zeroNil: true}, nil),
+			Entry("Invalid unit", source.FieldInfo{Type: "int64"}, timeOptions{unix: "min"},
+				errors.New(`transformer.time_unix should be one of s, ms, us, ns, got "min"`)),
+			Entry("Invalid location", source.FieldInfo{Type: "time.Time"}, timeOptions{location: "Europe/Berlin"},
+				errors.New(`transformer.time_location should be UTC or Local, got "Europe/Berlin"`)),
+			Entry("Invalid truncate", source.FieldInfo{Type: "time.Time"}, timeOptions{truncate: "0s"},
+				errors.New(`transformer.time_truncate should be positive, got "0s"`)),
+		)
+
+		It("converts timestamp into Unix epoch", func() {
+			f, ok, err := wktgoogleProtobufTimestampInline("CreatedAt", "CreatedAt", source.FieldInfo{Type: "int64"}, true, timeOptions{unix: "ms", zeroNil: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(f.Imports).To(Equal([]string{gogoTypesImport}))
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in *types.Timestamp) int64 {
+	if in == nil {
+		return 0
+	}
+	v := in
+	t := time.Unix(v.Seconds, int64(v.Nanos)).UTC()
+	return t.UnixMilli()
+}(src.CreatedAt)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in int64) *types.Timestamp {
+	v := in
+	if v == 0 {
+		return nil
+	}
+	t := time.UnixMilli(v)
+	return &types.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}(src.CreatedAt)`))
+		})
+
+		It("rejects time_zero_nil for non-nullable timestamps", func() {
+			_, _, err := wktgoogleProtobufTimestampInline("CreatedAt", "CreatedAt", source.FieldInfo{Type: "time.Time"}, false, timeOptions{zeroNil: true})
+			Expect(err).To(Equal(errTimeZeroNil))
+		})
+
+		It("converts timestamp into string", func() {
+			f, ok, err := wktgoogleProtobufTimestampInline("At", "At", source.FieldInfo{Type: "string"}, false, timeOptions{format: "RFC3339", truncate: "1s", validate: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(f.GoToProtoExpr).To(Equal(`func(in string) types.Timestamp {
+	v := in
+	if v == "" {
+		return types.Timestamp{}
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		reportError(opts, fmt.Errorf("At: %v", err))
+		return types.Timestamp{}
+	}
+	t = t.Truncate(time.Second)
+	if t.Unix() < -62135596800 || t.Unix() > 253402300799 {
+		reportError(opts, fmt.Errorf("At: time %v is out of range", t))
+		return types.Timestamp{}
+	}
+	return types.Timestamp{Seconds: t.Unix(), Nanos: int32(t.Nanosecond())}
+}(src.At)`))
+		})
+	})
+})
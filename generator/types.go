@@ -1,6 +1,20 @@
 package generator
 
-import "github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	errScalarEntry = errors.New("scalar map entries should be in form proto_type:model_type")
+	errScalarProto = errors.New("proto type should be one of numeric scalar types")
+	errScalarModel = errors.New("model type should be one of Go numeric types")
+)
 
 type typeRel struct {
 	pbType     string
@@ -11,12 +25,208 @@ type typeRel struct {
 // types contains protobuf types.
 // default mapping for similar but non-equal types.
 var types = map[descriptor.FieldDescriptorProto_Type]typeRel{
-	descriptor.FieldDescriptorProto_TYPE_INT32:  typeRel{pbType: "int32", goType: "int"},
-	descriptor.FieldDescriptorProto_TYPE_INT64:  typeRel{pbType: "int64", goType: "int"},
-	descriptor.FieldDescriptorProto_TYPE_UINT32: typeRel{pbType: "uint32", goType: "uint"},
-	descriptor.FieldDescriptorProto_TYPE_UINT64: typeRel{pbType: "uint64", goType: "uint"},
-	descriptor.FieldDescriptorProto_TYPE_FLOAT:  typeRel{pbType: "", goType: "float32"},
-	descriptor.FieldDescriptorProto_TYPE_DOUBLE: typeRel{pbType: "", goType: "float64"},
-	descriptor.FieldDescriptorProto_TYPE_BOOL:   typeRel{pbType: "", goType: "bool"},
-	descriptor.FieldDescriptorProto_TYPE_STRING: typeRel{pbType: "", goType: "string"},
+	descriptor.FieldDescriptorProto_TYPE_INT32:    typeRel{pbType: "int32", goType: "int"},
+	descriptor.FieldDescriptorProto_TYPE_INT64:    typeRel{pbType: "int64", goType: "int"},
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   typeRel{pbType: "uint32", goType: "uint"},
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   typeRel{pbType: "uint64", goType: "uint"},
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   typeRel{pbType: "int32", goType: "int"},
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   typeRel{pbType: "int64", goType: "int"},
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  typeRel{pbType: "uint32", goType: "uint"},
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  typeRel{pbType: "uint64", goType: "uint"},
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: typeRel{pbType: "int32", goType: "int"},
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: typeRel{pbType: "int64", goType: "int"},
+	descriptor.FieldDescriptorProto_TYPE_FLOAT:    typeRel{pbType: "", goType: "float32"},
+	descriptor.FieldDescriptorProto_TYPE_DOUBLE:   typeRel{pbType: "", goType: "float64"},
+	descriptor.FieldDescriptorProto_TYPE_BOOL:     typeRel{pbType: "", goType: "bool"},
+	descriptor.FieldDescriptorProto_TYPE_STRING:   typeRel{pbType: "", goType: "string"},
+}
+
+// numericTypes contains proto numeric scalar types which are accepted by
+// scalar map entries.
+var numericTypes = map[string]descriptor.FieldDescriptorProto_Type{
+	"int32":    descriptor.FieldDescriptorProto_TYPE_INT32,
+	"int64":    descriptor.FieldDescriptorProto_TYPE_INT64,
+	"uint32":   descriptor.FieldDescriptorProto_TYPE_UINT32,
+	"uint64":   descriptor.FieldDescriptorProto_TYPE_UINT64,
+	"sint32":   descriptor.FieldDescriptorProto_TYPE_SINT32,
+	"sint64":   descriptor.FieldDescriptorProto_TYPE_SINT64,
+	"fixed32":  descriptor.FieldDescriptorProto_TYPE_FIXED32,
+	"fixed64":  descriptor.FieldDescriptorProto_TYPE_FIXED64,
+	"sfixed32": descriptor.FieldDescriptorProto_TYPE_SFIXED32,
+	"sfixed64": descriptor.FieldDescriptorProto_TYPE_SFIXED64,
+	"float":    descriptor.FieldDescriptorProto_TYPE_FLOAT,
+	"double":   descriptor.FieldDescriptorProto_TYPE_DOUBLE,
+}
+
+// scalarMap returns copy of table updated by comma separated entries of
+// value like "int64:int64,float:float64". Each entry replaces default model
+// type of proto numeric type, fields of replaced default types are converted
+// with range checks like other numeric model types, see isDefaultPair.
+func scalarMap(table map[descriptor.FieldDescriptorProto_Type]typeRel, value string) (map[descriptor.FieldDescriptorProto_Type]typeRel, error) {
+	out := make(map[descriptor.FieldDescriptorProto_Type]typeRel, len(table))
+	for k, v := range table {
+		out[k] = v
+	}
+
+	for _, e := range strings.Split(value, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+
+		parts := strings.SplitN(e, ":", 2)
+		if len(parts) != 2 {
+			return nil, pkgerrors.Wrap(errScalarEntry, e)
+		}
+
+		pt, gt := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		t, ok := numericTypes[pt]
+		if !ok {
+			return nil, pkgerrors.Wrap(errScalarProto, e)
+		}
+
+		if !isNumber(gt) {
+			return nil, pkgerrors.Wrap(errScalarModel, e)
+		}
+
+		out[t] = typeRel{pbType: pbGoTypes[t], goType: gt}
+	}
+
+	return out, nil
+}
+
+// fileScalarMap returns table of default model types for file: entries of
+// scalar_map CLI parameter are applied to default table, then entries of
+// transformer.scalar_map file option.
+func fileScalarMap(param, option string) (map[descriptor.FieldDescriptorProto_Type]typeRel, error) {
+	table, err := scalarMap(types, param)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "scalar_map parameter")
+	}
+
+	if table, err = scalarMap(table, option); err != nil {
+		return nil, pkgerrors.Wrap(err, "scalar_map option")
+	}
+
+	return table, nil
+}
+
+// numericBits contains sizes of Go numeric types in bits. Sizes of int and
+// uint depend on platform, see typeBits.
+var numericBits = map[string]int{
+	"int8": 8, "int16": 16, "int32": 32, "int64": 64, "int": 64,
+	"uint8": 8, "byte": 8, "uint16": 16, "uint32": 32, "uint64": 64, "uint": 64,
+	"float32": 32, "float64": 64,
+}
+
+// typeBits returns size of numeric type t in bits. Destination int and uint
+// types are considered 32-bit types, so conversions of integers into them are
+// checked on every platform; checks compare converted values with original
+// ones, so values which fit int of 64-bit platforms are not rejected.
+func typeBits(t string, dst bool) int {
+	if dst && (t == "int" || t == "uint") {
+		return 32
+	}
+
+	return numericBits[t]
+}
+
+// isUnsigned returns true if t is unsigned integer type.
+func isUnsigned(t string) bool {
+	return t == "byte" || strings.HasPrefix(t, "uint")
+}
+
+// isFloat returns true if t is floating point type.
+func isFloat(t string) bool {
+	return strings.HasPrefix(t, "float")
+}
+
+// numericConv returns steps, result expression and imports of inline
+// conversion of value v of numeric type from into numeric type to. Values
+// which are out of range of type to are passed to error handler. Values which
+// only lose precision, e.g. floats converted into integers, are converted as
+// is.
+func numericConv(from, to string) ([]step, string, []string) {
+	if from == to {
+		return nil, "v", nil
+	}
+
+	fb, tb := typeBits(from, false), typeBits(to, true)
+	r := fmt.Sprintf("%s(v)", to)
+
+	var (
+		conds   []string
+		imports []string
+	)
+
+	switch {
+	case isFloat(to) && !isFloat(from): // integers are in range of floats
+	case isFloat(to):
+		if tb < fb {
+			conds = append(conds, fmt.Sprintf("math.IsInf(float64(%s), 0) && !math.IsInf(v, 0)", r))
+			imports = append(imports, `"math"`)
+		}
+	case isFloat(from):
+		// Sizes of int and uint are not capped, checks of floats don't
+		// reject values which fit int of 64-bit platforms.
+		half, full := fmt.Sprint(tb-1), fmt.Sprint(tb)
+		if to == "int" || to == "uint" {
+			half, full = "(strconv.IntSize-1)", "strconv.IntSize"
+			imports = append(imports, `"strconv"`)
+		}
+		if isUnsigned(to) {
+			conds = append(conds, fmt.Sprintf("!(v > -1 && v < 1<<%s)", full))
+		} else {
+			conds = append(conds, fmt.Sprintf("!(v >= -(1<<%s) && v < 1<<%s)", half, half))
+		}
+	default:
+		if isUnsigned(to) && !isUnsigned(from) {
+			conds = append(conds, "v < 0")
+		}
+		if !isUnsigned(to) && isUnsigned(from) && tb <= fb {
+			conds = append(conds, r+" < 0")
+		}
+		if tb < fb {
+			conds = append(conds, fmt.Sprintf("%s(%s) != v", from, r))
+		}
+	}
+
+	if len(conds) == 0 {
+		return nil, r, nil
+	}
+
+	s := step{
+		cond:   strings.Join(conds, " || "),
+		format: "value %v is out of range of " + to,
+		args:   []string{"v"},
+	}
+
+	return []step{s}, r, imports
+}
+
+// isDefaultPair returns true if model type goType is default model type of
+// proto type t and the default is not replaced by table. Such fields are
+// converted without range checks.
+func isDefaultPair(t descriptor.FieldDescriptorProto_Type, goType string, table map[descriptor.FieldDescriptorProto_Type]typeRel) bool {
+	if table == nil {
+		table = types
+	}
+
+	return table[t].goType == goType && types[t].goType == goType
+}
+
+// numericField returns *Field which converts numeric proto field of Go type
+// pbType into numeric model field of other type inline, see numericConv.
+func numericField(pname, gname, pbType, zero string, gf source.FieldInfo, pnullable bool) *Field {
+	conv := googleTypeConv{goZero: zero}
+
+	var imports []string
+	conv.toGoSteps, conv.toGo, conv.imports = numericConv(pbType, gf.Type)
+	conv.toPbSteps, conv.toPb, imports = numericConv(gf.Type, pbType)
+	conv.imports = append(conv.imports, imports...)
+
+	gt := googleType{pbType: pbType, pbZero: zeroValue(pbType), scalar: true}
+
+	return inlineField(pname, gname, gt, conv, gf, pnullable, false)
 }
@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Scalar map", func() {
+	var (
+		i32 = descriptor.FieldDescriptorProto_TYPE_INT32
+		i64 = descriptor.FieldDescriptorProto_TYPE_INT64
+		s64 = descriptor.FieldDescriptorProto_TYPE_SINT64
+		flt = descriptor.FieldDescriptorProto_TYPE_FLOAT
+	)
+
+	DescribeTable("scalarMap",
+		func(value string, t descriptor.FieldDescriptorProto_Type, expected typeRel) {
+			table, err := scalarMap(types, value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(table[t]).To(Equal(expected))
+		},
+
+		Entry("Empty value", "", i64, typeRel{pbType: "int64", goType: "int"}),
+		Entry("Same types", "int64:int64", i64, typeRel{pbType: "int64", goType: "int64"}),
+		Entry("Several entries", "int64:int64, int32:int16", i32, typeRel{pbType: "int32", goType: "int16"}),
+		Entry("Other types are not changed", "int64:int64", i32, typeRel{pbType: "int32", goType: "int"}),
+		Entry("Float", "float:float64", flt, typeRel{pbType: "float32", goType: "float64"}),
+		Entry("Sint64", "sint64:int64", s64, typeRel{pbType: "int64", goType: "int64"}),
+	)
+
+	It("scalarMap does not change table", func() {
+		_, err := scalarMap(types, "int64:int64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(types[i64]).To(Equal(typeRel{pbType: "int64", goType: "int"}))
+	})
+
+	DescribeTable("scalarMap returns an error",
+		func(value string, expected error) {
+			_, err := scalarMap(types, value)
+			Expect(pkgerrors.Cause(err)).To(MatchError(expected))
+		},
+
+		Entry("Entry without model type", "int64", errScalarEntry),
+		Entry("Not numeric proto type", "string:int64", errScalarProto),
+		Entry("Go type instead of proto type", "float32:float64", errScalarProto),
+		Entry("Not numeric model type", "int64:string", errScalarModel),
+	)
+
+	It("fileScalarMap applies option after parameter", func() {
+		table, err := fileScalarMap("int64:int64,int32:int32", "int64:uint64")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(table[i32].goType).To(Equal("int32"))
+		Expect(table[i64].goType).To(Equal("uint64"))
+	})
+
+	It("fileScalarMap returns an error for invalid option", func() {
+		_, err := fileScalarMap("int64:int64", "int64")
+		Expect(err).To(MatchError(ContainSubstring("scalar_map option")))
+	})
+
+	DescribeTable("processSimpleField",
+		func(value string, t descriptor.FieldDescriptorProto_Type, sf source.FieldInfo, toGo, toPb string, usePackage bool) {
+			table, err := scalarMap(types, value)
+			Expect(err).NotTo(HaveOccurred())
+
+			f, err := processSimpleField(nil, "Abc", "Abc", &t, sf, &descriptor.FieldDescriptorProto{}, table)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(f.ProtoToGoType).To(Equal(toGo))
+			Expect(f.GoToProtoType).To(Equal(toPb))
+			Expect(f.UsePackage).To(Equal(usePackage))
+		},
+
+		Entry("Default int64 into int", "", i64, goStruct["IntField"], "int", "int64", false),
+		Entry("Same types", "int64:int64", i64, goStruct["Int64Field"], "", "", false),
+		Entry("Default sint64 into int64", "", s64, goStruct["Int64Field"], "", "", false),
+	)
+
+	DescribeTable("processSimpleField converts other numeric types inline",
+		func(value string, t descriptor.FieldDescriptorProto_Type, sf source.FieldInfo, toGo, toPb string) {
+			table, err := scalarMap(types, value)
+			Expect(err).NotTo(HaveOccurred())
+
+			f, err := processSimpleField(nil, "Abc", "Abc", &t, sf, &descriptor.FieldDescriptorProto{}, table)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(f.ProtoToGoType).To(BeEmpty())
+			Expect(f.UsePackage).To(BeFalse())
+			for _, c := range []struct{ expr, cond string }{{f.ProtoToGoExpr, toGo}, {f.GoToProtoExpr, toPb}} {
+				if c.cond == "" {
+					Expect(c.expr).NotTo(ContainSubstring("reportError"))
+				} else {
+					Expect(c.expr).To(ContainSubstring("if " + c.cond + " {\n\t\treportError(opts, fmt.Errorf(\"Abc: value %v is out of range of "))
+				}
+			}
+		},
+
+		Entry("Default int64 into int32", "", i64, goStruct["Int32Field"], "int64(int32(v)) != v", ""),
+		Entry("Int is not expected", "int64:int64", i64, goStruct["IntField"], "int64(int(v)) != v", ""),
+		Entry("Int64 into int32", "int64:int32", i64, goStruct["Int32Field"], "int64(int32(v)) != v", ""),
+		Entry("Int32 into int64", "int32:int64", i32, goStruct["Int64Field"], "", "int64(int32(v)) != v"),
+		Entry("Float into float64", "float:float64", flt, goStruct["Float64Field"], "", "math.IsInf(float64(float32(v)), 0) && !math.IsInf(v, 0)"),
+		Entry("Sint64 into uint", "sint64:uint", s64, goStruct["UintField"], "v < 0 || int64(uint(v)) != v", "int64(v) < 0"),
+	)
+
+	DescribeTable("numericConv",
+		func(from, to, cond, result string) {
+			steps, r, _ := numericConv(from, to)
+			Expect(r).To(Equal(result))
+			if cond == "" {
+				Expect(steps).To(BeEmpty())
+			} else {
+				Expect(steps).To(HaveLen(1))
+				Expect(steps[0].cond).To(Equal(cond))
+				Expect(steps[0].format).To(Equal("value %v is out of range of " + to))
+			}
+		},
+
+		Entry("Same types", "int64", "int64", "", "v"),
+		Entry("Widening", "int32", "int64", "", "int64(v)"),
+		Entry("Unsigned into wider signed", "uint32", "int64", "", "int64(v)"),
+		Entry("Narrowing", "int64", "int16", "int64(int16(v)) != v", "int16(v)"),
+		Entry("Signed into unsigned", "int32", "uint64", "v < 0", "uint64(v)"),
+		Entry("Unsigned into signed", "uint64", "int64", "int64(v) < 0", "int64(v)"),
+		Entry("Int is 32-bit destination", "int64", "int", "int64(int(v)) != v", "int(v)"),
+		Entry("Int is 64-bit source", "int", "int64", "", "int64(v)"),
+		Entry("Integer into float", "int64", "float32", "", "float32(v)"),
+		Entry("Float into signed", "float64", "int32", "!(v >= -(1<<31) && v < 1<<31)", "int32(v)"),
+		Entry("Float into unsigned", "float32", "uint8", "!(v > -1 && v < 1<<8)", "uint8(v)"),
+		Entry("Float into int", "float64", "int", "!(v >= -(1<<(strconv.IntSize-1)) && v < 1<<(strconv.IntSize-1))", "int(v)"),
+		Entry("Float into uint", "float32", "uint", "!(v > -1 && v < 1<<strconv.IntSize)", "uint(v)"),
+		Entry("Float narrowing", "float64", "float32", "math.IsInf(float64(float32(v)), 0) && !math.IsInf(v, 0)", "float32(v)"),
+		Entry("Float widening", "float32", "float64", "", "float64(v)"),
+	)
+})
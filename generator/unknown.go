@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var (
+	errUnknownFieldsType = errors.New("model field for unknown fields should be []byte")
+	errUnknownGogofaster = errors.New("unknown fields are not generated by protoc-gen-gogofaster: use gogofaster=false parameter and protoc-gen-gogo")
+)
+
+// unknownField returns *Field for model field which keeps unknown fields of
+// proto message msg, if the message has transformer.unknown_fields option.
+// Unknown fields are copied from XXX_unrecognized field of gogo/protobuf
+// structures or read by ProtoReflect().GetUnknown() of protoc-gen-go
+// structures. Bytes are copied in both directions, so model and message don't
+// share them. Second return value is false if message has no such option.
+func unknownField(msg *descriptor.DescriptorProto, goStructFields source.Structure, fi fileInfo) (*Field, bool, error) {
+	if msg.Options == nil {
+		return nil, false, nil
+	}
+
+	name, err := getStringOption(msg.Options, options.E_UnknownFields)
+	if err != nil || name == "" {
+		return nil, false, nil
+	}
+
+	gf, ok := goStructFields[name]
+	if !ok {
+		return nil, false, pkgerrors.Wrap(errors.New("field not found in destination structure"), name)
+	}
+
+	if gf.Type != "byte" || !gf.IsSlice || gf.IsPointer {
+		return nil, false, pkgerrors.Wrap(errUnknownFieldsType, name)
+	}
+
+	if fi.golang {
+		return &Field{
+			Name:          name,
+			ProtoName:     "unknownFields",
+			ProtoToGoExpr: "append([]byte(nil), src.ProtoReflect().GetUnknown()...)",
+			Unknown:       true,
+		}, true, nil
+	}
+
+	if fi.gogofaster {
+		return nil, false, pkgerrors.Wrap(errUnknownGogofaster, name)
+	}
+
+	return &Field{
+		Name:          name,
+		ProtoName:     "XXX_unrecognized",
+		ProtoToGoExpr: "append([]byte(nil), src.XXX_unrecognized...)",
+		GoToProtoExpr: fmt.Sprintf("append([]byte(nil), src.%s...)", name),
+	}, true, nil
+}
+
+// formatGolangUnknownField returns statement which restores unknown fields of
+// protoc-gen-go message from model field.
+//
+// This function is mapped into template. See funcMap variable for details.
+func formatGolangUnknownField(f Field, swapped bool) string {
+	if !f.Unknown || !swapped {
+		return ""
+	}
+
+	return fmt.Sprintf("\ts.ProtoReflect().SetUnknown(append([]byte(nil), src.%s...))\n", f.Name)
+}
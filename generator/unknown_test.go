@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/options"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	pkgerrors "github.com/pkg/errors"
+)
+
+var _ = Describe("Unknown fields", func() {
+	goStructFields := source.Structure{
+		"Unknown": {Type: "byte", IsSlice: true},
+		"Name":    {Type: "string"},
+	}
+
+	// message returns proto message with unknown_fields option.
+	message := func(name string) *descriptor.DescriptorProto {
+		m := &descriptor.DescriptorProto{Name: sp("Product"), Options: &descriptor.MessageOptions{}}
+		if err := proto.SetExtension(m.Options, options.E_UnknownFields, sp(name)); err != nil {
+			panic(err)
+		}
+
+		return m
+	}
+
+	DescribeTable("unknownField",
+		func(msg *descriptor.DescriptorProto, fi fileInfo, expected *Field, found bool) {
+			f, ok, err := unknownField(msg, goStructFields, fi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(Equal(found))
+			Expect(f).To(Equal(expected))
+		},
+
+		Entry("Message without options", &descriptor.DescriptorProto{Name: sp("Product")}, fileInfo{}, nil, false),
+		Entry("gogo/protobuf", message("Unknown"), fileInfo{}, &Field{
+			Name:          "Unknown",
+			ProtoName:     "XXX_unrecognized",
+			ProtoToGoExpr: "append([]byte(nil), src.XXX_unrecognized...)",
+			GoToProtoExpr: "append([]byte(nil), src.Unknown...)",
+		}, true),
+		Entry("google.golang.org/protobuf", message("Unknown"), fileInfo{golang: true, gogofaster: true}, &Field{
+			Name:          "Unknown",
+			ProtoName:     "unknownFields",
+			ProtoToGoExpr: "append([]byte(nil), src.ProtoReflect().GetUnknown()...)",
+			Unknown:       true,
+		}, true),
+	)
+
+	DescribeTable("unknownField returns an error",
+		func(msg *descriptor.DescriptorProto, fi fileInfo, expected interface{}) {
+			_, _, err := unknownField(msg, goStructFields, fi)
+			Expect(pkgerrors.Cause(err)).To(MatchError(expected))
+		},
+
+		Entry("Field not found", message("Raw"), fileInfo{}, "field not found in destination structure"),
+		Entry("Not a []byte field", message("Name"), fileInfo{}, errUnknownFieldsType),
+		Entry("protoc-gen-gogofaster", message("Unknown"), fileInfo{gogofaster: true}, errUnknownGogofaster),
+	)
+
+	DescribeTable("formatGolangUnknownField",
+		func(f Field, swapped bool, expected string) {
+			Expect(formatGolangUnknownField(f, swapped)).To(Equal(expected))
+		},
+
+		Entry("Other field", Field{Name: "Name"}, true, ""),
+		Entry("Proto to Go", Field{Name: "Unknown", Unknown: true}, false, ""),
+		Entry("Go to proto", Field{Name: "Unknown", Unknown: true}, true, "\ts.ProtoReflect().SetUnknown(append([]byte(nil), src.Unknown...))\n"),
+	)
+
+	It("formatField skips unknown fields of protoc-gen-go messages in Go to proto functions", func() {
+		f := Field{Name: "Unknown", ProtoName: "unknownFields", ProtoToGoExpr: "append([]byte(nil), src.ProtoReflect().GetUnknown()...)", Unknown: true}
+		Expect(formatField(f, true, "pb")).To(BeEmpty())
+		Expect(formatField(f, false, "model")).To(Equal("Unknown: append([]byte(nil), src.ProtoReflect().GetUnknown()...),"))
+	})
+})
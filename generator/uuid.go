@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+)
+
+var (
+	// uuidParsers contains import paths of supported UUID packages and names
+	// of functions which parse UUID from string.
+	uuidParsers = map[string]string{
+		"github.com/google/uuid":    "Parse",
+		"github.com/gofrs/uuid":     "FromString",
+		"github.com/satori/go.uuid": "FromString",
+	}
+
+	// Major version suffix of import path, e.g. /v5.
+	majorVersion = regexp.MustCompile(`/v[0-9]+$`)
+)
+
+// uuidPackage returns package name which is used in model file for UUID type,
+// import spec for this package and name of function which parses UUID from
+// string. The last return value is false if model field is not a UUID.
+func uuidPackage(gf source.FieldInfo) (pkg, spec, parse string, ok bool) {
+	if n := lastName(gf.Type); n != "UUID" && n != "NullUUID" {
+		return "", "", "", false
+	}
+
+	// Types of slice elements have no package name, see source package.
+	pkg = "uuid"
+	if i := strings.Index(gf.Type, "."); i > 0 {
+		pkg = gf.Type[:i]
+	}
+
+	if gf.PkgPath == "" {
+		// Package is unknown, google/uuid is used by default.
+		return pkg, "", uuidParsers["github.com/google/uuid"], pkg == "uuid"
+	}
+
+	parse, ok = uuidParsers[majorVersion.ReplaceAllString(gf.PkgPath, "")]
+	if !ok {
+		return "", "", "", false
+	}
+
+	return pkg, importSpec(pkg, gf.PkgPath), parse, true
+}
+
+// uuidConv returns conversion between proto string or bytes field and model
+// UUID field. The second return value is false if model field is not a UUID
+// or proto field has other type.
+func uuidConv(gf source.FieldInfo, ftype descriptor.FieldDescriptorProto_Type, zeroOnError bool) (googleType, googleTypeConv, bool) {
+	pkg, spec, parse, ok := uuidPackage(gf)
+	if !ok {
+		return googleType{}, googleTypeConv{}, false
+	}
+
+	var (
+		gt    googleType
+		empty string
+		value string
+	)
+
+	switch ftype {
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		gt = googleType{pbType: "string", pbZero: `""`}
+		empty, parse, value = `v == ""`, pkg+"."+parse, "%s.String()"
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		gt = googleType{pbType: "[]byte", pbZero: "nil"}
+		empty, parse, value = "len(v) == 0", pkg+".FromBytes", "%s[:]"
+	default:
+		return googleType{}, googleTypeConv{}, false
+	}
+
+	conv := googleTypeConv{
+		toGoSteps: []step{
+			{cond: empty},
+			{stmt: fmt.Sprintf("u, err := %s(v)", parse)},
+			{cond: "err != nil", format: "%v", args: []string{"err"}},
+		},
+		silent: zeroOnError,
+	}
+
+	if spec != "" {
+		conv.imports = []string{spec}
+	}
+
+	if lastName(gf.Type) == "NullUUID" {
+		conv.goType = pkg + ".NullUUID"
+		conv.goZero = pkg + ".NullUUID{}"
+		conv.toGo = pkg + ".NullUUID{UUID: u, Valid: true}"
+		conv.toPbSteps = []step{{cond: "!v.Valid"}}
+		conv.toPb = fmt.Sprintf(value, "v.UUID")
+
+		return gt, conv, true
+	}
+
+	conv.goType = pkg + ".UUID"
+	conv.goZero = pkg + ".Nil"
+	conv.toGo = "u"
+	conv.toPbSteps = []step{{cond: "v == " + pkg + ".Nil"}}
+	conv.toPb = fmt.Sprintf(value, "v")
+
+	return gt, conv, true
+}
+
+// uuidField returns *Field for proto string or bytes field and model UUID
+// field. Repeated proto fields are converted into slices. The second return
+// value is false if fields are not supported.
+func uuidField(pname, gname string, gf source.FieldInfo, fdp *descriptor.FieldDescriptorProto, zeroOnError bool) (*Field, bool) {
+	isRepeated := fdp.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+	if isRepeated != gf.IsSlice {
+		return nil, false
+	}
+
+	gt, conv, ok := uuidConv(gf, fdp.GetType(), zeroOnError)
+	if !ok {
+		return nil, false
+	}
+
+	return inlineField(pname, gname, gt, conv, gf, false, false), true
+}
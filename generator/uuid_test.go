@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/innovation-upstream/protoc-gen-struct-transformer/source"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UUID", func() {
+
+	Describe("uuidPackage", func() {
+
+		DescribeTable("check result",
+			func(gf source.FieldInfo, pkg, spec, parse string, ok bool) {
+				p, s, f, found := uuidPackage(gf)
+				Expect(found).To(Equal(ok))
+				Expect(p).To(Equal(pkg))
+				Expect(s).To(Equal(spec))
+				Expect(f).To(Equal(parse))
+			},
+
+			Entry("google/uuid", source.FieldInfo{Type: "uuid.UUID", PkgPath: "github.com/google/uuid"},
+				"uuid", `"github.com/google/uuid"`, "Parse", true),
+			Entry("gofrs with alias", source.FieldInfo{Type: "gofrs.NullUUID", PkgPath: "github.com/gofrs/uuid/v5"},
+				"gofrs", `gofrs "github.com/gofrs/uuid/v5"`, "FromString", true),
+			Entry("Slice element", source.FieldInfo{Type: "UUID", IsSlice: true, PkgPath: "github.com/satori/go.uuid"},
+				"uuid", `"github.com/satori/go.uuid"`, "FromString", true),
+			Entry("Unknown package", source.FieldInfo{Type: "uuid.UUID"}, "uuid", "", "Parse", true),
+			Entry("Unknown package with other name", source.FieldInfo{Type: "id.UUID"}, "id", "", "Parse", false),
+			Entry("Unsupported package", source.FieldInfo{Type: "my.UUID", PkgPath: "example.com/my"}, "", "", "", false),
+			Entry("Not a UUID", source.FieldInfo{Type: "string"}, "", "", "", false),
+		)
+	})
+
+	Describe("uuidField", func() {
+		var (
+			str = descriptor.FieldDescriptorProto_TYPE_STRING
+			byt = descriptor.FieldDescriptorProto_TYPE_BYTES
+			i64 = descriptor.FieldDescriptorProto_TYPE_INT64
+			rep = descriptor.FieldDescriptorProto_LABEL_REPEATED
+		)
+
+		DescribeTable("skips unsupported fields",
+			func(gf source.FieldInfo, fdp *descriptor.FieldDescriptorProto) {
+				f, ok := uuidField("Pb", "Go", gf, fdp, false)
+				Expect(ok).To(BeFalse())
+				Expect(f).To(BeNil())
+			},
+
+			Entry("Integer proto field", source.FieldInfo{Type: "uuid.UUID"}, &descriptor.FieldDescriptorProto{Type: &i64}),
+			Entry("Repeated proto field, non-slice model field", source.FieldInfo{Type: "uuid.UUID"}, &descriptor.FieldDescriptorProto{Type: &str, Label: &rep}),
+			Entry("Slice model field, non-repeated proto field", source.FieldInfo{Type: "UUID", IsSlice: true}, &descriptor.FieldDescriptorProto{Type: &str}),
+		)
+
+		It("converts string into UUID pointer", func() {
+			f, ok := uuidField("ID", "ID", source.FieldInfo{Type: "uuid.UUID", IsPointer: true, PkgPath: "github.com/google/uuid"}, &descriptor.FieldDescriptorProto{Type: &str}, false)
+			Expect(ok).To(BeTrue())
+			Expect(f.Imports).To(Equal([]string{`"github.com/google/uuid"`}))
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in string) *uuid.UUID {
+	v := in
+	if v == "" {
+		return nil
+	}
+	u, err := uuid.Parse(v)
+	if err != nil {
+		reportError(opts, fmt.Errorf("ID: %v", err))
+		return nil
+	}
+	r := u
+	return &r
+}(src.ID)`))
+			Expect(f.GoToProtoExpr).To(Equal(`func(in *uuid.UUID) string {
+	if in == nil {
+		return ""
+	}
+	v := *in
+	if v == uuid.Nil {
+		return ""
+	}
+	return v.String()
+}(src.ID)`))
+		})
+
+		It("converts repeated bytes into NullUUID slice without errors", func() {
+			f, ok := uuidField("IDs", "IDs", source.FieldInfo{Type: "NullUUID", IsSlice: true}, &descriptor.FieldDescriptorProto{Type: &byt, Label: &rep}, true)
+			Expect(ok).To(BeTrue())
+			Expect(f.Imports).To(BeNil())
+			Expect(f.ProtoToGoExpr).To(Equal(`func(in [][]byte) []uuid.NullUUID {
+	if in == nil {
+		return nil
+	}
+	r := make([]uuid.NullUUID, len(in))
+	for i, e := range in {
+		r[i] = func(in []byte) uuid.NullUUID {
+			v := in
+			if len(v) == 0 {
+				return uuid.NullUUID{}
+			}
+			u, err := uuid.FromBytes(v)
+			if err != nil {
+				return uuid.NullUUID{}
+			}
+			return uuid.NullUUID{UUID: u, Valid: true}
+		}(e)
+	}
+	return r
+}(src.IDs)`))
+			Expect(f.GoToProtoExpr).To(ContainSubstring(`		r[i] = func(in uuid.NullUUID) []byte {
+			v := in
+			if !v.Valid {
+				return nil
+			}
+			return v.UUID[:]
+		}(e)`))
+		})
+	})
+})
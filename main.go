@@ -38,8 +38,28 @@ var (
 	debug             = flag.Bool("debug", false, "Add debug information to generated file.")
 	usePackageInPath  = flag.Bool("use-package-in-path", true, "If true, package parameter will be used in path for output file.")
 	paths             = flag.String("paths", "", "How to generate output filenames.")
+	gogofaster        = flag.Bool("gogofaster", true, "If true, proto2 scalar fields without default values are not pointers and messages have no XXX_unrecognized field, like in protoc-gen-gogofaster output. Set to false for transformer.unknown_fields option with protoc-gen-gogo.")
+	runtime           = flag.String("runtime", "gogo", `Runtime of proto structures: "gogo" for gogo/protobuf or "golang" for google.golang.org/protobuf.`)
+	scalarMap         listFlag
 )
 
+func init() {
+	flag.Var(&scalarMap, "scalar_map", `Default model types of proto numeric types, e.g. "int64:int64". Can be repeated.`)
+}
+
+// listFlag is a flag which collects all its values, protoc parameters are
+// separated by commas, so such flags are repeated for multiple entries.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 type PathType int
 
 const (
@@ -63,9 +83,14 @@ func main() {
 	// Convert incoming parameters into CLI flags.
 	must(generator.SetParameters(flag.CommandLine, gogoreq.Parameter))
 
-	resp := &plugin.CodeGeneratorResponse{}
+	// Response of gogo/protobuf has no fields for editions support.
+	resp := &plugin.CodeGeneratorResponse{XXX_unrecognized: generator.EditionsSupport()}
 	optPath := ""
 
+	if *runtime != "gogo" && *runtime != "golang" {
+		log.Fatalf(`Unknown runtime %q: want "gogo" or "golang".`, *runtime)
+	}
+
 	messages, err := generator.CollectAllMessages(gogoreq)
 	must(err)
 
@@ -80,7 +105,7 @@ func main() {
 			log.Fatalf(`Unknown path type %q: want "import" or "source_relative".`, pathType)
 		}
 
-		content, err := generator.ProcessFile(f, packageName, helperPackageName, messages, *debug, *paths)
+		content, err := generator.ProcessFile(f, packageName, helperPackageName, messages, *debug, *paths, *gogofaster, *runtime, scalarMap.String())
 		if err != nil {
 			if err != generator.ErrFileSkipped {
 				must(err)
@@ -186,7 +211,7 @@ func ProcessDependency(allProtos []*descriptor.FileDescriptorProto, currentProto
 	ap:
 		for _, p := range allProtos {
 			if p.GetName() == d {
-				content, err := generator.ProcessFile(p, packageName, helperPackageName, messages, *debug, *paths)
+				content, err := generator.ProcessFile(p, packageName, helperPackageName, messages, *debug, *paths, *gogofaster, *runtime, scalarMap.String())
 				if err != nil {
 					if err != generator.ErrFileSkipped {
 						return allFiles, errors.WithStack(err)
@@ -26,6 +26,73 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// Direction of field transformation, see option direction.
+type Direction int32
+
+const (
+	// Field is transformed in both directions.
+	Direction_BOTH Direction = 0
+	// Field is transformed from proto message into model only, e.g. password.
+	Direction_PB_TO_MODEL Direction = 1
+	// Field is transformed from model into proto message only, e.g. read-only
+	// created_at or etag.
+	Direction_MODEL_TO_PB Direction = 2
+)
+
+var Direction_name = map[int32]string{
+	0: "BOTH",
+	1: "PB_TO_MODEL",
+	2: "MODEL_TO_PB",
+}
+
+var Direction_value = map[string]int32{
+	"BOTH":        0,
+	"PB_TO_MODEL": 1,
+	"MODEL_TO_PB": 2,
+}
+
+func (x Direction) String() string {
+	return proto.EnumName(Direction_name, int32(x))
+}
+
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_5df765dc541320cc, []int{0}
+}
+
+// Policy for elements of repeated field with the same key, see option
+// map_duplicates.
+type DuplicateKeys int32
+
+const (
+	// Value of the last element is kept.
+	DuplicateKeys_KEEP_LAST DuplicateKeys = 0
+	// Value of the first element is kept.
+	DuplicateKeys_KEEP_FIRST DuplicateKeys = 1
+	// Value of the first element is kept, other elements are passed to error
+	// handler.
+	DuplicateKeys_REPORT_DUPLICATES DuplicateKeys = 2
+)
+
+var DuplicateKeys_name = map[int32]string{
+	0: "KEEP_LAST",
+	1: "KEEP_FIRST",
+	2: "REPORT_DUPLICATES",
+}
+
+var DuplicateKeys_value = map[string]int32{
+	"KEEP_LAST":         0,
+	"KEEP_FIRST":        1,
+	"REPORT_DUPLICATES": 2,
+}
+
+func (x DuplicateKeys) String() string {
+	return proto.EnumName(DuplicateKeys_name, int32(x))
+}
+
+func (DuplicateKeys) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_5df765dc541320cc, []int{1}
+}
+
 var E_GoModelsFilePath = &proto.ExtensionDesc{
 	ExtendedType:  (*descriptor.FileOptions)(nil),
 	ExtensionType: (*string)(nil),
@@ -53,6 +120,24 @@ var E_GoProtobufPackage = &proto.ExtensionDesc{
 	Filename:      "options/annotations.proto",
 }
 
+var E_FieldMask = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FileOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5204,
+	Name:          "transformer.field_mask",
+	Tag:           "varint,5204,opt,name=field_mask",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ScalarMap = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FileOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5205,
+	Name:          "transformer.scalar_map",
+	Tag:           "bytes,5205,opt,name=scalar_map",
+	Filename:      "options/annotations.proto",
+}
+
 var E_GoStruct = &proto.ExtensionDesc{
 	ExtendedType:  (*descriptor.MessageOptions)(nil),
 	ExtensionType: (*string)(nil),
@@ -62,6 +147,51 @@ var E_GoStruct = &proto.ExtensionDesc{
 	Filename:      "options/annotations.proto",
 }
 
+var E_GoConverter = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.MessageOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5101,
+	Name:          "transformer.go_converter",
+	Tag:           "bytes,5101,opt,name=go_converter",
+	Filename:      "options/annotations.proto",
+}
+
+var E_UnknownFields = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.MessageOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5102,
+	Name:          "transformer.unknown_fields",
+	Tag:           "bytes,5102,opt,name=unknown_fields",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ModelConst = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.MessageOptions)(nil),
+	ExtensionType: ([]string)(nil),
+	Field:         5103,
+	Name:          "transformer.model_const",
+	Tag:           "bytes,5103,rep,name=model_const",
+	Filename:      "options/annotations.proto",
+}
+
+var E_PbConst = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.MessageOptions)(nil),
+	ExtensionType: ([]string)(nil),
+	Field:         5104,
+	Name:          "transformer.pb_const",
+	Tag:           "bytes,5104,rep,name=pb_const",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ConstImports = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.MessageOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5105,
+	Name:          "transformer.const_imports",
+	Tag:           "bytes,5105,opt,name=const_imports",
+	Filename:      "options/annotations.proto",
+}
+
 var E_Embed = &proto.ExtensionDesc{
 	ExtendedType:  (*descriptor.FieldOptions)(nil),
 	ExtensionType: (*bool)(nil),
@@ -121,15 +251,240 @@ var E_ForceAssignable = &proto.ExtensionDesc{
 	ExtensionType: (*bool)(nil),
 	Field:         5307,
 	Name:          "transformer.force_assignable",
-	Tag:           "varint,5306,opt,name=force_assignable",
+	Tag:           "varint,5307,opt,name=force_assignable",
+	Filename:      "options/annotations.proto",
+}
+
+var E_Validate = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5308,
+	Name:          "transformer.validate",
+	Tag:           "varint,5308,opt,name=validate",
+	Filename:      "options/annotations.proto",
+}
+
+var E_Currency = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5309,
+	Name:          "transformer.currency",
+	Tag:           "bytes,5309,opt,name=currency",
+	Filename:      "options/annotations.proto",
+}
+
+var E_Scale = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*int32)(nil),
+	Field:         5310,
+	Name:          "transformer.scale",
+	Tag:           "varint,5310,opt,name=scale",
+	Filename:      "options/annotations.proto",
+}
+
+var E_TimeLocation = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5311,
+	Name:          "transformer.time_location",
+	Tag:           "bytes,5311,opt,name=time_location",
+	Filename:      "options/annotations.proto",
+}
+
+var E_TimeTruncate = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5312,
+	Name:          "transformer.time_truncate",
+	Tag:           "bytes,5312,opt,name=time_truncate",
+	Filename:      "options/annotations.proto",
+}
+
+var E_TimeZeroNil = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5313,
+	Name:          "transformer.time_zero_nil",
+	Tag:           "varint,5313,opt,name=time_zero_nil",
+	Filename:      "options/annotations.proto",
+}
+
+var E_TimeUnix = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5314,
+	Name:          "transformer.time_unix",
+	Tag:           "bytes,5314,opt,name=time_unix",
+	Filename:      "options/annotations.proto",
+}
+
+var E_TimeFormat = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5315,
+	Name:          "transformer.time_format",
+	Tag:           "bytes,5315,opt,name=time_format",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ZeroOnError = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5316,
+	Name:          "transformer.zero_on_error",
+	Tag:           "varint,5316,opt,name=zero_on_error",
+	Filename:      "options/annotations.proto",
+}
+
+var E_Clone = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5317,
+	Name:          "transformer.clone",
+	Tag:           "varint,5317,opt,name=clone",
+	Filename:      "options/annotations.proto",
+}
+
+var E_AliasOf = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5318,
+	Name:          "transformer.alias_of",
+	Tag:           "bytes,5318,opt,name=alias_of",
+	Filename:      "options/annotations.proto",
+}
+
+var E_WriteAlias = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5319,
+	Name:          "transformer.write_alias",
+	Tag:           "varint,5319,opt,name=write_alias",
+	Filename:      "options/annotations.proto",
+}
+
+var E_Direction = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*Direction)(nil),
+	Field:         5320,
+	Name:          "transformer.direction",
+	Tag:           "varint,5320,opt,name=direction,enum=transformer.Direction",
+	Filename:      "options/annotations.proto",
+}
+
+var E_Flatten = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5321,
+	Name:          "transformer.flatten",
+	Tag:           "varint,5321,opt,name=flatten",
+	Filename:      "options/annotations.proto",
+}
+
+var E_FlattenPrefix = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5322,
+	Name:          "transformer.flatten_prefix",
+	Tag:           "bytes,5322,opt,name=flatten_prefix",
+	Filename:      "options/annotations.proto",
+}
+
+var E_FlattenMap = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5323,
+	Name:          "transformer.flatten_map",
+	Tag:           "bytes,5323,opt,name=flatten_map",
+	Filename:      "options/annotations.proto",
+}
+
+var E_FlattenEmpty = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*bool)(nil),
+	Field:         5324,
+	Name:          "transformer.flatten_empty",
+	Tag:           "varint,5324,opt,name=flatten_empty",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ToModelExpr = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5325,
+	Name:          "transformer.to_model_expr",
+	Tag:           "bytes,5325,opt,name=to_model_expr",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ToPbExpr = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5326,
+	Name:          "transformer.to_pb_expr",
+	Tag:           "bytes,5326,opt,name=to_pb_expr",
+	Filename:      "options/annotations.proto",
+}
+
+var E_ExprImports = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5327,
+	Name:          "transformer.expr_imports",
+	Tag:           "bytes,5327,opt,name=expr_imports",
+	Filename:      "options/annotations.proto",
+}
+
+var E_DefaultValue = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5328,
+	Name:          "transformer.default_value",
+	Tag:           "bytes,5328,opt,name=default_value",
+	Filename:      "options/annotations.proto",
+}
+
+var E_FromMethod = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5329,
+	Name:          "transformer.from_method",
+	Tag:           "bytes,5329,opt,name=from_method",
+	Filename:      "options/annotations.proto",
+}
+
+var E_MapKey = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*string)(nil),
+	Field:         5330,
+	Name:          "transformer.map_key",
+	Tag:           "bytes,5330,opt,name=map_key",
+	Filename:      "options/annotations.proto",
+}
+
+var E_MapDuplicates = &proto.ExtensionDesc{
+	ExtendedType:  (*descriptor.FieldOptions)(nil),
+	ExtensionType: (*DuplicateKeys)(nil),
+	Field:         5331,
+	Name:          "transformer.map_duplicates",
+	Tag:           "varint,5331,opt,name=map_duplicates,enum=transformer.DuplicateKeys",
 	Filename:      "options/annotations.proto",
 }
 
 func init() {
+	proto.RegisterEnum("transformer.Direction", Direction_name, Direction_value)
+	proto.RegisterEnum("transformer.DuplicateKeys", DuplicateKeys_name, DuplicateKeys_value)
 	proto.RegisterExtension(E_GoModelsFilePath)
 	proto.RegisterExtension(E_GoRepoPackage)
 	proto.RegisterExtension(E_GoProtobufPackage)
+	proto.RegisterExtension(E_FieldMask)
+	proto.RegisterExtension(E_ScalarMap)
 	proto.RegisterExtension(E_GoStruct)
+	proto.RegisterExtension(E_GoConverter)
+	proto.RegisterExtension(E_UnknownFields)
+	proto.RegisterExtension(E_ModelConst)
+	proto.RegisterExtension(E_PbConst)
+	proto.RegisterExtension(E_ConstImports)
 	proto.RegisterExtension(E_Embed)
 	proto.RegisterExtension(E_Skip)
 	proto.RegisterExtension(E_MapTo)
@@ -137,37 +492,105 @@ func init() {
 	proto.RegisterExtension(E_Custom)
 	proto.RegisterExtension(E_ForceUseHelperPackage)
 	proto.RegisterExtension(E_ForceAssignable)
+	proto.RegisterExtension(E_Validate)
+	proto.RegisterExtension(E_Currency)
+	proto.RegisterExtension(E_Scale)
+	proto.RegisterExtension(E_TimeLocation)
+	proto.RegisterExtension(E_TimeTruncate)
+	proto.RegisterExtension(E_TimeZeroNil)
+	proto.RegisterExtension(E_TimeUnix)
+	proto.RegisterExtension(E_TimeFormat)
+	proto.RegisterExtension(E_ZeroOnError)
+	proto.RegisterExtension(E_Clone)
+	proto.RegisterExtension(E_AliasOf)
+	proto.RegisterExtension(E_WriteAlias)
+	proto.RegisterExtension(E_Direction)
+	proto.RegisterExtension(E_Flatten)
+	proto.RegisterExtension(E_FlattenPrefix)
+	proto.RegisterExtension(E_FlattenMap)
+	proto.RegisterExtension(E_FlattenEmpty)
+	proto.RegisterExtension(E_ToModelExpr)
+	proto.RegisterExtension(E_ToPbExpr)
+	proto.RegisterExtension(E_ExprImports)
+	proto.RegisterExtension(E_DefaultValue)
+	proto.RegisterExtension(E_FromMethod)
+	proto.RegisterExtension(E_MapKey)
+	proto.RegisterExtension(E_MapDuplicates)
 }
 
 func init() { proto.RegisterFile("options/annotations.proto", fileDescriptor_5df765dc541320cc) }
 
 var fileDescriptor_5df765dc541320cc = []byte{
-	// 422 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x93, 0xcd, 0xaa, 0xd4, 0x30,
-	0x14, 0xc7, 0xa7, 0xe0, 0x1d, 0xee, 0x8d, 0x88, 0x5a, 0x11, 0xae, 0xa2, 0x75, 0x76, 0xce, 0x5d,
-	0xb4, 0x05, 0xbf, 0x16, 0x01, 0x17, 0x8a, 0x8a, 0x0b, 0x07, 0x87, 0x51, 0x11, 0x5c, 0x18, 0xd2,
-	0xf6, 0x34, 0x2d, 0xb7, 0xe9, 0x09, 0x49, 0xea, 0x73, 0xf8, 0x30, 0x8a, 0x1f, 0x4f, 0xe0, 0x72,
-	0xd4, 0x8d, 0x4b, 0x99, 0xd9, 0xfa, 0x10, 0x62, 0xd2, 0x8e, 0x82, 0x42, 0xdd, 0x05, 0xce, 0xff,
-	0xf7, 0xcb, 0x81, 0x73, 0x0e, 0xb9, 0x80, 0xca, 0xd6, 0xd8, 0x9a, 0x94, 0xb7, 0x2d, 0x5a, 0xee,
-	0xde, 0x89, 0xd2, 0x68, 0x31, 0x3c, 0x69, 0x35, 0x6f, 0x4d, 0x89, 0x5a, 0x82, 0xbe, 0x38, 0x13,
-	0x88, 0xa2, 0x81, 0xd4, 0x95, 0xb2, 0xae, 0x4c, 0x0b, 0x30, 0xb9, 0xae, 0x95, 0x45, 0xed, 0xe3,
-	0xf4, 0x11, 0x39, 0x27, 0x90, 0x49, 0x2c, 0xa0, 0x31, 0xac, 0xac, 0x1b, 0x60, 0x8a, 0xdb, 0x2a,
-	0xbc, 0x94, 0x78, 0x32, 0x19, 0xc8, 0xe4, 0x41, 0xdd, 0xc0, 0x63, 0xff, 0xeb, 0xe1, 0xe7, 0xf9,
-	0x2c, 0x98, 0x1f, 0xac, 0xce, 0x08, 0x5c, 0x38, 0xf0, 0x57, 0x6d, 0xc9, 0x6d, 0x45, 0xef, 0x93,
-	0xd3, 0x02, 0x99, 0x06, 0x85, 0x4c, 0xf1, 0xfc, 0x98, 0x0b, 0x18, 0x31, 0x7d, 0xf1, 0xa6, 0x53,
-	0x02, 0x57, 0xa0, 0x70, 0xe9, 0x19, 0xba, 0x70, 0x4d, 0x0d, 0xc0, 0x7f, 0xaa, 0xbe, 0x7a, 0xd5,
-	0x59, 0x81, 0xcb, 0xbe, 0x3c, 0xe8, 0x6e, 0x93, 0x03, 0x81, 0xcc, 0x58, 0xdd, 0xe5, 0x36, 0xbc,
-	0xf2, 0x97, 0x64, 0x01, 0xc6, 0x70, 0xb1, 0xf3, 0xfc, 0xb8, 0xea, 0x3c, 0xfb, 0x02, 0x9f, 0x38,
-	0x82, 0xde, 0x20, 0x7b, 0x20, 0x33, 0x28, 0xc2, 0xcb, 0xff, 0xf8, 0x1f, 0x9a, 0x62, 0x00, 0xdf,
-	0x1c, 0xcd, 0x82, 0xf9, 0xfe, 0xca, 0x87, 0xe9, 0x35, 0x72, 0xc2, 0x1c, 0xd7, 0x6a, 0x0c, 0x7a,
-	0xeb, 0x21, 0x97, 0xa5, 0x37, 0xc9, 0x54, 0x72, 0xc5, 0x2c, 0x8e, 0x51, 0xef, 0x8e, 0x5c, 0x8f,
-	0x7b, 0x92, 0xab, 0xa7, 0x38, 0x60, 0xdc, 0x8c, 0x61, 0xef, 0x7f, 0x63, 0x77, 0x0c, 0xbd, 0x45,
-	0xa6, 0x79, 0x67, 0x2c, 0xca, 0x31, 0xec, 0x83, 0xef, 0xb1, 0x4f, 0xd3, 0xe7, 0xe4, 0xb0, 0x44,
-	0x9d, 0x03, 0xeb, 0x0c, 0xb0, 0x0a, 0x1a, 0x05, 0x7a, 0x37, 0xa2, 0x11, 0xd3, 0x47, 0x6f, 0x3a,
-	0xef, 0xf8, 0x67, 0x06, 0x1e, 0x3a, 0xba, 0x9f, 0xd3, 0xdd, 0x97, 0x9f, 0x36, 0x51, 0xb0, 0xde,
-	0x44, 0xc1, 0xf7, 0x4d, 0x14, 0xbc, 0xde, 0x46, 0x93, 0xf5, 0x36, 0x9a, 0x7c, 0xdb, 0x46, 0x93,
-	0x17, 0xf7, 0x44, 0x6d, 0xab, 0x2e, 0x4b, 0x72, 0x94, 0x69, 0xdd, 0xb6, 0xf8, 0xca, 0xad, 0x7c,
-	0xdc, 0x29, 0x63, 0x35, 0x70, 0xe9, 0xf7, 0x3b, 0x8f, 0x05, 0xb4, 0xb1, 0x1f, 0x73, 0xfc, 0xc7,
-	0x15, 0xa4, 0xfd, 0xb1, 0x64, 0x53, 0x17, 0xbb, 0xfe, 0x33, 0x00, 0x00, 0xff, 0xff, 0x70, 0x22,
-	0x82, 0xf4, 0x3e, 0x03, 0x00, 0x00,
+	// 1125 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x96, 0x5b, 0x6f, 0x1b, 0xc5,
+	0x17, 0xc0, 0xe3, 0xfe, 0xdb, 0xc4, 0x1e, 0xc7, 0x89, 0xeb, 0xbf, 0x8a, 0x4a, 0x05, 0x26, 0x6f,
+	0x4d, 0x2b, 0xc5, 0x91, 0xca, 0xa5, 0x30, 0x05, 0x21, 0xc7, 0xd9, 0xd0, 0x28, 0x31, 0xb6, 0x1c,
+	0x07, 0xa4, 0x3e, 0x30, 0x1a, 0xaf, 0xc7, 0x9b, 0x95, 0x77, 0xf7, 0x8c, 0x66, 0x66, 0xd3, 0x84,
+	0x4f, 0xc1, 0x87, 0x01, 0x71, 0xbf, 0xdf, 0xca, 0x3d, 0x6d, 0x41, 0xe2, 0x11, 0x25, 0xaf, 0x5c,
+	0xbf, 0x01, 0x9a, 0x99, 0x5d, 0x37, 0x08, 0xa4, 0xf1, 0xdb, 0x7a, 0xf7, 0xfc, 0x7e, 0x7b, 0xce,
+	0xec, 0x8c, 0xcf, 0x41, 0x0f, 0x03, 0x57, 0x21, 0x24, 0x72, 0x95, 0x26, 0x09, 0x28, 0x6a, 0xae,
+	0x1b, 0x5c, 0x80, 0x82, 0x5a, 0x59, 0x09, 0x9a, 0xc8, 0x11, 0x88, 0x98, 0x89, 0x4b, 0x4b, 0x01,
+	0x40, 0x10, 0xb1, 0x55, 0xf3, 0x68, 0x90, 0x8e, 0x56, 0x87, 0x4c, 0xfa, 0x22, 0xe4, 0x0a, 0x84,
+	0x0d, 0xbf, 0x7a, 0x1d, 0x95, 0xd6, 0x43, 0xc1, 0x7c, 0xad, 0xa8, 0x15, 0xd1, 0xd9, 0xb5, 0x4e,
+	0xff, 0x66, 0x75, 0xa6, 0xb6, 0x88, 0xca, 0xdd, 0x35, 0xd2, 0xef, 0x90, 0x76, 0x67, 0xdd, 0xdb,
+	0xae, 0x16, 0xf4, 0x0d, 0x73, 0xa9, 0xef, 0x75, 0xd7, 0xaa, 0x67, 0xae, 0x7a, 0xa8, 0xb2, 0x9e,
+	0xf2, 0x28, 0xf4, 0xa9, 0x62, 0x5b, 0xec, 0x50, 0xd6, 0x2a, 0xa8, 0xb4, 0xe5, 0x79, 0x5d, 0xb2,
+	0xdd, 0xdc, 0xe9, 0x57, 0x67, 0x6a, 0x0b, 0x08, 0x99, 0x9f, 0x1b, 0x9b, 0xbd, 0x9d, 0x7e, 0xb5,
+	0x50, 0xbb, 0x80, 0xce, 0xf7, 0xbc, 0x6e, 0xa7, 0xd7, 0x27, 0xeb, 0xbb, 0xdd, 0xed, 0xcd, 0x56,
+	0xb3, 0xef, 0xed, 0x54, 0xcf, 0xe0, 0x6d, 0xf4, 0xff, 0x00, 0x48, 0x0c, 0x43, 0x16, 0x49, 0x32,
+	0x0a, 0x23, 0x46, 0x38, 0x55, 0x7b, 0xb5, 0x47, 0x1a, 0x36, 0xf3, 0x46, 0x9e, 0x79, 0x63, 0x23,
+	0x8c, 0x58, 0xc7, 0x56, 0x7d, 0xf1, 0xee, 0xf2, 0x52, 0x61, 0xb9, 0xd4, 0xab, 0x06, 0xd0, 0x36,
+	0xa0, 0x7e, 0xd6, 0xa5, 0x6a, 0x0f, 0x7b, 0x68, 0x31, 0x00, 0x22, 0x18, 0x07, 0xc2, 0xa9, 0x3f,
+	0xa6, 0x01, 0x73, 0x98, 0xee, 0x59, 0x53, 0x25, 0x80, 0x1e, 0xe3, 0xd0, 0xb5, 0x0c, 0x6e, 0x9b,
+	0xa4, 0x72, 0x60, 0x4a, 0xd5, 0x7d, 0xab, 0x3a, 0x1f, 0x40, 0x37, 0x7b, 0x9c, 0xeb, 0x9e, 0x45,
+	0x68, 0x14, 0xb2, 0x68, 0x48, 0x62, 0x2a, 0xc7, 0x0e, 0xcb, 0x8f, 0xda, 0x52, 0xec, 0x95, 0x0c,
+	0xd0, 0xa6, 0x72, 0xac, 0x69, 0xe9, 0xd3, 0x88, 0x0a, 0x12, 0x53, 0xee, 0xa0, 0x7f, 0xb2, 0x39,
+	0x94, 0x2c, 0xd0, 0xa6, 0x1c, 0x3f, 0x87, 0x4a, 0x01, 0x10, 0xa9, 0x44, 0xea, 0xab, 0xda, 0x63,
+	0xff, 0x82, 0xdb, 0x4c, 0x4a, 0x1a, 0x4c, 0xf8, 0x5f, 0x2f, 0x1b, 0xbe, 0x18, 0xc0, 0x8e, 0x21,
+	0x70, 0x0b, 0xcd, 0x07, 0x40, 0x7c, 0x48, 0xf6, 0x99, 0x50, 0x4c, 0xb8, 0x0d, 0xbf, 0x59, 0x43,
+	0x39, 0x80, 0x56, 0x0e, 0xe1, 0x17, 0xd0, 0x42, 0x9a, 0x8c, 0x13, 0xb8, 0x9d, 0x10, 0x53, 0x96,
+	0x74, 0x6b, 0x7e, 0xb7, 0x9a, 0x4a, 0xc6, 0x6d, 0x18, 0x0c, 0x37, 0x51, 0xd9, 0xec, 0x14, 0x9d,
+	0x90, 0x9c, 0xa2, 0x9c, 0x3f, 0x2e, 0x2f, 0xfd, 0x6f, 0xb9, 0xd4, 0x43, 0x06, 0x6a, 0x69, 0x06,
+	0xdf, 0x40, 0x45, 0x3e, 0x98, 0x96, 0xff, 0xd3, 0xf2, 0x73, 0x7c, 0x60, 0x61, 0x0f, 0x55, 0x0c,
+	0x49, 0xc2, 0x98, 0x83, 0x50, 0x53, 0xd4, 0xf1, 0x97, 0xad, 0x63, 0xde, 0x60, 0x9b, 0x96, 0xc2,
+	0x4f, 0xa0, 0x73, 0x2c, 0x1e, 0xb0, 0x61, 0xed, 0xd1, 0xff, 0xf8, 0x98, 0x2c, 0x1a, 0xe6, 0xf0,
+	0xeb, 0x57, 0xcc, 0x5e, 0xb0, 0xc1, 0xf8, 0x1a, 0x3a, 0x2b, 0xc7, 0x21, 0x77, 0x41, 0x6f, 0x58,
+	0xc8, 0xc4, 0xe2, 0x27, 0xd1, 0x6c, 0x4c, 0x39, 0x51, 0xe0, 0xa2, 0xde, 0xbc, 0x62, 0xf2, 0x3c,
+	0x17, 0x53, 0xde, 0x87, 0x1c, 0xa3, 0xd2, 0x85, 0xbd, 0xf5, 0x00, 0x6b, 0x4a, 0xfc, 0x14, 0x9a,
+	0xf5, 0x53, 0xa9, 0x20, 0x76, 0x61, 0x6f, 0xdb, 0x1c, 0xb3, 0x68, 0xfc, 0x32, 0xba, 0x38, 0x02,
+	0xe1, 0x33, 0x92, 0x4a, 0x46, 0xf6, 0x58, 0xc4, 0x99, 0x98, 0x9c, 0x39, 0x87, 0xe9, 0x1d, 0x6b,
+	0xba, 0x60, 0xf8, 0x5d, 0xc9, 0x6e, 0x1a, 0x3a, 0x3f, 0x78, 0x9b, 0xa8, 0x6a, 0xc5, 0x54, 0xca,
+	0x30, 0x48, 0xe8, 0x20, 0x72, 0x0a, 0xdf, 0xb5, 0xc2, 0x45, 0xc3, 0x35, 0x27, 0x18, 0xc6, 0xa8,
+	0xb8, 0x4f, 0xa3, 0x70, 0x48, 0x95, 0x53, 0xf1, 0x9e, 0x55, 0x4c, 0xe2, 0x35, 0xeb, 0xa7, 0x42,
+	0xb0, 0xc4, 0x3f, 0x74, 0xb1, 0xef, 0xdb, 0x05, 0x9d, 0xc4, 0xeb, 0xbd, 0xa2, 0x0f, 0xb3, 0xf3,
+	0xa5, 0x1f, 0x68, 0xf0, 0x5c, 0xcf, 0x06, 0xe3, 0x16, 0xaa, 0xa8, 0x30, 0x66, 0x24, 0x02, 0xdf,
+	0x34, 0x07, 0x17, 0xfd, 0xa1, 0x7d, 0xed, 0xbc, 0x86, 0xb6, 0x33, 0x66, 0x22, 0x51, 0x22, 0x4d,
+	0xfc, 0x29, 0xea, 0xfe, 0xe8, 0x94, 0xa4, 0x9f, 0x31, 0x78, 0x2d, 0x93, 0xbc, 0xca, 0x04, 0x90,
+	0x24, 0x8c, 0x5c, 0x92, 0x8f, 0xed, 0xe2, 0x95, 0x35, 0x74, 0x8b, 0x09, 0x78, 0x31, 0x8c, 0xf0,
+	0x0d, 0x54, 0x32, 0x8e, 0x34, 0x09, 0x0f, 0x5c, 0xfc, 0x27, 0xd9, 0x02, 0x6a, 0x60, 0x37, 0x09,
+	0x0f, 0xf0, 0xf3, 0xc8, 0xb8, 0x88, 0xee, 0x88, 0x54, 0xb9, 0xf0, 0x4f, 0x2d, 0x8e, 0x34, 0xb2,
+	0x61, 0x08, 0x5d, 0x81, 0x49, 0x1e, 0x12, 0xc2, 0x84, 0x00, 0xe1, 0x52, 0x7c, 0x96, 0x55, 0xa0,
+	0xa1, 0x4e, 0xe2, 0x69, 0x44, 0x7f, 0x45, 0x3f, 0x82, 0xc4, 0xb9, 0x84, 0x9f, 0x67, 0x27, 0xde,
+	0x04, 0xe3, 0x67, 0x50, 0x91, 0x46, 0x21, 0x95, 0x04, 0x46, 0x2e, 0xf0, 0x0b, 0x9b, 0xf7, 0x9c,
+	0x89, 0xef, 0x8c, 0x74, 0xd5, 0xb7, 0x45, 0xa8, 0x18, 0x31, 0x37, 0x5c, 0xf4, 0x97, 0xf6, 0xb5,
+	0xc8, 0x20, 0x4d, 0x4d, 0xe0, 0x3e, 0x2a, 0x0d, 0x27, 0x73, 0x81, 0x03, 0xbf, 0xa3, 0xf1, 0x85,
+	0x6b, 0x0f, 0x35, 0x4e, 0x8d, 0x1e, 0x8d, 0xc9, 0x54, 0xd1, 0x7b, 0x20, 0xc2, 0x4f, 0xa3, 0xb9,
+	0x51, 0x44, 0x95, 0x62, 0x4e, 0xe7, 0x57, 0x36, 0xa5, 0x3c, 0x1c, 0x7b, 0x68, 0x21, 0xbb, 0x24,
+	0x5c, 0xb0, 0x91, 0x7b, 0x23, 0x7c, 0x6d, 0x57, 0xa4, 0x92, 0x51, 0x5d, 0x03, 0xe9, 0x75, 0xc9,
+	0x35, 0xba, 0x9b, 0x3a, 0x1c, 0xdf, 0x64, 0xbb, 0x21, 0x43, 0x74, 0x3f, 0x6d, 0xa1, 0xdc, 0x48,
+	0x58, 0xcc, 0x95, 0xf3, 0x40, 0x7f, 0x6b, 0xeb, 0x98, 0xcf, 0x20, 0x4f, 0x33, 0xe6, 0x50, 0x64,
+	0x43, 0x0f, 0x61, 0x07, 0xdc, 0xb9, 0xa5, 0xbe, 0xb3, 0x79, 0x94, 0x95, 0x9d, 0x77, 0xbc, 0x03,
+	0x2e, 0xf4, 0x58, 0xa0, 0x80, 0xf0, 0xc1, 0x54, 0x82, 0xef, 0xf3, 0x53, 0x01, 0xdd, 0x81, 0xa1,
+	0x9b, 0x68, 0x5e, 0x73, 0x93, 0x46, 0xe6, 0xe0, 0x7f, 0xc8, 0x12, 0xd0, 0x4c, 0xde, 0xc5, 0x5a,
+	0xa8, 0x32, 0x64, 0x23, 0x9a, 0x46, 0x8a, 0xec, 0xd3, 0x28, 0x75, 0xee, 0xed, 0xa3, 0xec, 0xef,
+	0x21, 0x83, 0x5e, 0xd2, 0x8c, 0xf9, 0x1e, 0x02, 0x62, 0x12, 0x33, 0xb5, 0x07, 0xce, 0x86, 0x78,
+	0x37, 0xff, 0x1e, 0x02, 0xe2, 0xb6, 0x21, 0xf0, 0x75, 0x34, 0xa7, 0x5b, 0xd5, 0x98, 0x39, 0xbf,
+	0xc4, 0x3d, 0x0b, 0xeb, 0xce, 0xb6, 0xc5, 0x0e, 0x31, 0x45, 0x0b, 0x1a, 0x1c, 0xe6, 0x33, 0xac,
+	0x73, 0x0d, 0xee, 0xdb, 0x5d, 0x7e, 0xe9, 0x9f, 0xbb, 0xfc, 0xf4, 0x08, 0xdc, 0xab, 0xc4, 0x94,
+	0x4f, 0xee, 0xc8, 0xb5, 0x57, 0xee, 0x1c, 0xd7, 0x0b, 0x47, 0xc7, 0xf5, 0xc2, 0x2f, 0xc7, 0xf5,
+	0xc2, 0x6b, 0x27, 0xf5, 0x99, 0xa3, 0x93, 0xfa, 0xcc, 0xcf, 0x27, 0xf5, 0x99, 0x5b, 0xeb, 0x41,
+	0xa8, 0xf6, 0xd2, 0x41, 0xc3, 0x87, 0x78, 0x35, 0x4c, 0x12, 0xd8, 0x37, 0xff, 0xb8, 0x2b, 0x29,
+	0x97, 0x4a, 0x30, 0x1a, 0xdb, 0x79, 0xdd, 0x5f, 0x09, 0x58, 0xb2, 0x62, 0x47, 0xb7, 0x95, 0x53,
+	0x2f, 0x5d, 0xcd, 0x86, 0xff, 0xc1, 0xac, 0x09, 0x7b, 0xfc, 0xef, 0x01, 0x00, 0xf5, 0x33, 0x5f,
+	0x4e, 0x0e, 0x0c, 0x00, 0x00,
 }
@@ -1,6 +1,10 @@
 package source
 
-import "fmt"
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 type (
 	// FieldInfo contains information about one structure field without field name.
@@ -9,12 +13,25 @@ type (
 		Type string
 		// Equals true if field is a pointer.
 		IsPointer bool
+		// Raw field tag without backquotes, e.g. `db:"id" json:"id"`.
+		Tag string
+		// Equals true if field is a slice, Type contains type of slice element.
+		IsSlice bool
+		// Import path of package for types like uuid.UUID if package is
+		// imported by source file, e.g. "github.com/google/uuid".
+		PkgPath string
 	}
 
 	// Structure is a set of fields of one structure.
 	Structure map[string]FieldInfo
 	// StructureList is a list of parsed structures.
 	StructureList map[string]Structure
+
+	// Methods is a set of methods of one type, key is a method name and value
+	// contains information about type of method result.
+	Methods map[string]FieldInfo
+	// MethodList is a list of method sets of parsed types.
+	MethodList map[string]Methods
 )
 
 // String return structure information as a string.
@@ -33,3 +50,15 @@ func (fi FieldInfo) String() string {
 	}
 	return fi.Type
 }
+
+// TagName returns name part of struct tag value with given key, e.g. for tag
+// `db:"product_id,omitempty"` and key "db" it returns "product_id". Empty
+// string is returned if tag not found or name is "-".
+func (fi FieldInfo) TagName(key string) string {
+	name := strings.Split(reflect.StructTag(fi.Tag).Get(key), ",")[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
@@ -5,14 +5,79 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
+// importName returns default package name for import path, e.g. "uuid" for
+// "github.com/gofrs/uuid/v5".
+func importName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+
+	// Major version suffix, e.g. github.com/gofrs/uuid/v5.
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "go-"), "go.")
+	name = strings.TrimSuffix(strings.TrimSuffix(name, "-go"), ".go")
+
+	return name
+}
+
+// fileImports returns map where key is a package name which is used in source
+// file and value is an import path.
+func fileImports(node *ast.File) map[string]string {
+	imports := map[string]string{}
+
+	for _, i := range node.Imports {
+		path, err := strconv.Unquote(i.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		name := importName(path)
+		if i.Name != nil {
+			name = i.Name.Name
+		}
+
+		imports[name] = path
+	}
+
+	return imports
+}
+
+// selector returns FieldInfo for types like time.Time with import path of
+// package if it's imported by source file.
+func selector(se *ast.SelectorExpr, imports map[string]string) FieldInfo {
+	pkg := se.X.(*ast.Ident).Name
+
+	return FieldInfo{
+		Type:    fmt.Sprintf("%s.%s", pkg, se.Sel.Name),
+		PkgPath: imports[pkg],
+	}
+}
+
+// generic returns FieldInfo for instantiated generic types with one type
+// argument like sql.Null[time.Time].
+func generic(ie *ast.IndexExpr, imports map[string]string) FieldInfo {
+	fi := FieldInfo{Type: types.ExprString(ie)}
+
+	if se, ok := ie.X.(*ast.SelectorExpr); ok {
+		fi.PkgPath = imports[se.X.(*ast.Ident).Name]
+	}
+
+	return fi
+}
+
 // inspect is a function which is run for each node in source file. See go/ast
 // package for details.
-func inspect(output StructureList) func(n ast.Node) bool {
+func inspect(output StructureList, imports map[string]string) func(n ast.Node) bool {
 	return func(n ast.Node) bool {
 		spec, ok := n.(*ast.TypeSpec)
 		if !ok {
@@ -52,8 +117,13 @@ func inspect(output StructureList) func(n ast.Node) bool {
 				output[structName][fname] = FieldInfo{Type: t.Name}
 
 			case *ast.SelectorExpr: // types like time.Time, time.Duration, nulls.String
-				typ := fmt.Sprintf("%s.%s", t.X.(*ast.Ident).Name, t.Sel.Name)
-				output[structName][fname] = FieldInfo{Type: typ}
+				output[structName][fname] = selector(t, imports)
+
+			case *ast.IndexExpr: // generic types like sql.Null[time.Time]
+				output[structName][fname] = generic(t, imports)
+
+			case *ast.MapType: // maps like map[string]int64
+				output[structName][fname] = FieldInfo{Type: types.ExprString(t)}
 
 			case *ast.StarExpr: // pointer to something
 				switch se := t.X.(type) {
@@ -61,8 +131,13 @@ func inspect(output StructureList) func(n ast.Node) bool {
 					typ := se.Name
 					output[structName][fname] = FieldInfo{Type: typ, IsPointer: true}
 				case *ast.SelectorExpr: // *time.Time
-					typ := fmt.Sprintf("%s.%s", se.X.(*ast.Ident).Name, se.Sel.Name)
-					output[structName][fname] = FieldInfo{Type: typ, IsPointer: true}
+					fi := selector(se, imports)
+					fi.IsPointer = true
+					output[structName][fname] = fi
+				case *ast.IndexExpr: // *sql.Null[string]
+					fi := generic(se, imports)
+					fi.IsPointer = true
+					output[structName][fname] = fi
 				default:
 					typ := fmt.Sprintf("%s", reflect.TypeOf(t))
 					output[structName]["unsupported_star_expr_"+typ] = FieldInfo{Type: fmt.Sprintf("%T", se)}
@@ -73,19 +148,22 @@ func inspect(output StructureList) func(n ast.Node) bool {
 				typ := "empty_type"
 				switch at := t.Elt.(type) {
 				case *ast.SelectorExpr:
+					// Slices of selector types have type name without package
+					// name, e.g. "String" for []nulls.String.
 					typ = at.Sel.Name
-					output[structName][fname] = FieldInfo{Type: typ}
+					output[structName][fname] = FieldInfo{Type: typ, IsSlice: true, PkgPath: imports[at.X.(*ast.Ident).Name]}
 				case *ast.Ident:
 					typ = at.Name
-					output[structName][fname] = FieldInfo{Type: typ}
+					output[structName][fname] = FieldInfo{Type: typ, IsSlice: true}
 				case *ast.StarExpr: // pointer to something
 					switch se := at.X.(type) {
 					case *ast.Ident: // *SomeStruct, *string, *int etc.
 						typ := se.Name
-						output[structName][fname] = FieldInfo{Type: typ, IsPointer: true}
+						output[structName][fname] = FieldInfo{Type: typ, IsPointer: true, IsSlice: true}
 					case *ast.SelectorExpr: // *time.Time
-						typ := fmt.Sprintf("%s.%s", se.X.(*ast.Ident).Name, se.Sel.Name)
-						output[structName][fname] = FieldInfo{Type: typ, IsPointer: true}
+						fi := selector(se, imports)
+						fi.IsPointer, fi.IsSlice = true, true
+						output[structName][fname] = fi
 					default:
 						typ := fmt.Sprintf("%s", reflect.TypeOf(t))
 						output[structName]["unsupported_star_expr_"+typ] = FieldInfo{Type: fmt.Sprintf("%T", se)}
@@ -101,6 +179,12 @@ func inspect(output StructureList) func(n ast.Node) bool {
 				typ := fmt.Sprintf("%s", reflect.TypeOf(t))
 				output[structName]["unsupported_"+typ] = FieldInfo{Type: typ}
 			}
+
+			// Struct tags are used for db column names, json names, etc.
+			if fi, ok := output[structName][fname]; ok && field.Tag != nil {
+				fi.Tag, _ = strconv.Unquote(field.Tag.Value)
+				output[structName][fname] = fi
+			}
 		}
 		return false
 	}
@@ -117,11 +201,146 @@ func Parse(path string, src io.Reader) (StructureList, error) {
 
 	info := StructureList{}
 
-	ast.Inspect(node, inspect(info))
+	ast.Inspect(node, inspect(info, fileImports(node)))
 
 	return info, nil
 }
 
+// methodResult returns FieldInfo for type of method result. False is returned
+// for types which are not supported, e.g. functions and arrays.
+func methodResult(expr ast.Expr, imports map[string]string) (FieldInfo, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return FieldInfo{Type: t.Name}, true
+	case *ast.SelectorExpr:
+		return selector(t, imports), true
+	case *ast.IndexExpr:
+		return generic(t, imports), true
+	case *ast.MapType:
+		return FieldInfo{Type: types.ExprString(t)}, true
+	case *ast.StarExpr:
+		fi, ok := methodResult(t.X, imports)
+		if !ok || fi.IsPointer || fi.IsSlice {
+			return FieldInfo{}, false
+		}
+		fi.IsPointer = true
+		return fi, true
+	case *ast.ArrayType:
+		fi, ok := methodResult(t.Elt, imports)
+		if !ok || t.Len != nil || fi.IsSlice {
+			return FieldInfo{}, false
+		}
+		// Slices of selector types have type name without package name, the
+		// same as fields.
+		if se, ok := t.Elt.(*ast.SelectorExpr); ok {
+			fi.Type = se.Sel.Name
+		}
+		fi.IsSlice = true
+		return fi, true
+	}
+
+	return FieldInfo{}, false
+}
+
+// ParseMethods gets path to source file or content of source file as a
+// io.Reader and returns exported methods of types from this file, which have
+// no arguments and return one value. Methods with value and pointer receivers
+// are collected.
+func ParseMethods(path string, src io.Reader) (MethodList, error) {
+	node, err := parser.ParseFile(token.NewFileSet(), path, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	imports := fileImports(node)
+	ml := MethodList{}
+
+	for _, d := range node.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Recv == nil || len(fd.Recv.List) != 1 || !fd.Name.IsExported() {
+			continue
+		}
+
+		if fd.Type.Params.NumFields() != 0 || fd.Type.Results.NumFields() != 1 {
+			continue
+		}
+
+		recv := fd.Recv.List[0].Type
+		if se, ok := recv.(*ast.StarExpr); ok {
+			recv = se.X
+		}
+
+		// Methods of generic types are skipped.
+		typ, ok := recv.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		fi, ok := methodResult(fd.Type.Results.List[0].Type, imports)
+		if !ok {
+			continue
+		}
+
+		if _, ok := ml[typ.Name]; !ok {
+			ml[typ.Name] = Methods{}
+		}
+		ml[typ.Name][fd.Name.Name] = fi
+	}
+
+	return ml, nil
+}
+
+// ParsePackageMethods returns methods of types from all Go files of package
+// in directory of source file path, see ParseMethods. Methods of model types
+// are often declared in other files than types. Test files and files of other
+// packages are skipped, build constraints are not checked.
+func ParsePackageMethods(path string) (MethodList, error) {
+	fset := token.NewFileSet()
+
+	node, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	ml := MethodList{}
+
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+
+		pkg, err := parser.ParseFile(fset, f, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg.Name.Name != node.Name.Name {
+			continue
+		}
+
+		fml, err := ParseMethods(f, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for typ, methods := range fml {
+			if _, ok := ml[typ]; !ok {
+				ml[typ] = Methods{}
+			}
+			for name, fi := range methods {
+				ml[typ][name] = fi
+			}
+		}
+	}
+
+	return ml, nil
+}
+
 // Lookup return structure by name from parsed source file or an error if
 // structure with such name not found.
 func Lookup(sl StructureList, structName string) (Structure, error) {
@@ -2,6 +2,8 @@ package source
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -118,7 +120,7 @@ type (
 			"MyStruct": {
 				"ID":           {Type: "int", IsPointer: false},
 				"Name":         {Type: "string", IsPointer: false},
-				"SubMyStructs": {Type: "int", IsPointer: false},
+				"SubMyStructs": {Type: "int", IsPointer: false, IsSlice: true},
 			},
 		}),
 
@@ -134,7 +136,7 @@ type (
 			"MyStruct": {
 				"ID":   {Type: "int", IsPointer: false},
 				"Name": {Type: "string", IsPointer: false},
-				"Tags": {Type: "String", IsPointer: false},
+				"Tags": {Type: "String", IsPointer: false, IsSlice: true},
 			},
 		}),
 
@@ -186,6 +188,53 @@ type (
 			},
 		}),
 
+		Entry("File with one struct, fields have tags.", `package model
+
+type (
+	MyStruct struct {
+		ID	 int    `+"`"+`db:"id" json:"id"`+"`"+`
+		Name string
+	}
+)`, StructureList{
+			"MyStruct": {
+				"ID":   {Type: "int", IsPointer: false, Tag: `db:"id" json:"id"`},
+				"Name": {Type: "string", IsPointer: false},
+			},
+		}),
+
+		Entry("File with one struct, fields are of imported types.", `package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	gofrs "github.com/gofrs/uuid/v5"
+)
+
+type (
+	MyStruct struct {
+		ID     uuid.UUID
+		PID    *uuid.UUID
+		IDs    []uuid.UUID
+		PIDs   []*gofrs.UUID
+		T      time.Time
+		S      sql.NullString
+		NT     sql.Null[time.Time]
+		PNS    *sql.Null[string]
+	}
+)`, StructureList{
+			"MyStruct": {
+				"ID":   {Type: "uuid.UUID", PkgPath: "github.com/google/uuid"},
+				"PID":  {Type: "uuid.UUID", IsPointer: true, PkgPath: "github.com/google/uuid"},
+				"IDs":  {Type: "UUID", IsSlice: true, PkgPath: "github.com/google/uuid"},
+				"PIDs": {Type: "gofrs.UUID", IsPointer: true, IsSlice: true, PkgPath: "github.com/gofrs/uuid/v5"},
+				"T":    {Type: "time.Time", PkgPath: "time"},
+				"S":    {Type: "sql.NullString"},
+				"NT":   {Type: "sql.Null[time.Time]"},
+				"PNS":  {Type: "sql.Null[string]", IsPointer: true},
+			},
+		}),
+
 		Entry("File with one struct, field is of unsupported type.", `package model
 
 type (
@@ -198,13 +247,91 @@ type (
 )`, StructureList{
 			"MyStruct": {
 				"I":                                   {Type: "int", IsPointer: false},
+				"M":                                   {Type: "map[int]string", IsPointer: false},
 				"unsupported_*ast.FuncType":           {Type: "*ast.FuncType", IsPointer: false},
-				"unsupported_*ast.MapType":            {Type: "*ast.MapType", IsPointer: false},
 				"unsupported_star_expr_*ast.StarExpr": {Type: "*ast.MapType", IsPointer: false},
 			},
 		}),
 	)
 
+	DescribeTable("ParseMethods",
+		func(fileContent string, expected MethodList) {
+			ml, err := ParseMethods("file.go", bytes.NewReader([]byte(fileContent)))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ml).To(Equal(expected))
+		},
+
+		Entry("File without methods", `package model
+
+type Order struct{}
+
+func Total() int { return 0 }
+`, MethodList{}),
+
+		Entry("Methods of value and pointer receivers", `package model
+
+import "github.com/shopspring/decimal"
+
+type Order struct{}
+
+func (o Order) Total() decimal.Decimal { return decimal.Zero }
+func (o *Order) Note() *string { return nil }
+func (Order) Tags() []string { return nil }
+func (o Order) Counts() map[string]int { return nil }
+`, MethodList{
+			"Order": {
+				"Total":  {Type: "decimal.Decimal", PkgPath: "github.com/shopspring/decimal"},
+				"Note":   {Type: "string", IsPointer: true},
+				"Tags":   {Type: "string", IsSlice: true},
+				"Counts": {Type: "map[string]int"},
+			},
+		}),
+
+		Entry("Skipped methods", `package model
+
+type Order struct{}
+type List[T any] []T
+
+func (o Order) total() int { return 0 }
+func (o Order) Add(n int) int { return n }
+func (o Order) Split() (int, int) { return 0, 0 }
+func (o Order) Do() {}
+func (o Order) Handler() func() { return nil }
+func (l List[T]) Len() int { return len(l) }
+`, MethodList{}),
+	)
+
+	Describe("importName", func() {
+
+		DescribeTable("check result",
+			func(path, expected string) {
+				Expect(importName(path)).To(Equal(expected))
+			},
+
+			Entry("Standard library", "database/sql", "sql"),
+			Entry("Module", "github.com/google/uuid", "uuid"),
+			Entry("Major version", "github.com/gofrs/uuid/v5", "uuid"),
+			Entry("go. prefix", "github.com/satori/go.uuid", "uuid"),
+			Entry("-go suffix", "github.com/example/money-go", "money"),
+		)
+	})
+
+	Describe("FieldInfo.TagName", func() {
+
+		DescribeTable("check result",
+			func(tag, key, expected string) {
+				Expect(FieldInfo{Tag: tag}.TagName(key)).To(Equal(expected))
+			},
+
+			Entry("Empty tag", "", "db", ""),
+			Entry("Tag without key", `json:"id"`, "db", ""),
+			Entry("Tag with key", `db:"id" json:"product_id"`, "json", "product_id"),
+			Entry("Tag with options", `db:"id,omitempty"`, "db", "id"),
+			Entry("Ignored field", `db:"-"`, "db", ""),
+		)
+	})
+
 	Describe("Lookup", func() {
 
 		Context("when call Lookup with existing struct", func() {
@@ -250,4 +377,27 @@ type (
 		})
 	})
 
+	It("ParsePackageMethods collects methods from files of the same package", func() {
+		dir, err := os.MkdirTemp("", "models")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		for name, content := range map[string]string{
+			"model.go":      "package model\n\ntype Order struct{}\n\nfunc (o Order) Total() int { return 0 }\n",
+			"methods.go":    "package model\n\nfunc (o *Order) Note() string { return \"\" }\n",
+			"model_test.go": "package model\n\nfunc (o Order) Fake() int { return 0 }\n",
+			"other.go":      "package other\n\nfunc (o Order) Other() int { return 0 }\n",
+		} {
+			Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)).To(Succeed())
+		}
+
+		ml, err := ParsePackageMethods(filepath.Join(dir, "model.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ml).To(Equal(MethodList{
+			"Order": {
+				"Total": {Type: "int"},
+				"Note":  {Type: "string"},
+			},
+		}))
+	})
 })